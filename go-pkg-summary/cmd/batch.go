@@ -0,0 +1,140 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runBatch は複数のパッケージのサマリーを並行して取得し、1つの文書または --out-dir のファイルに出力します
+// 失敗したパッケージは最後にまとめて報告し、1つでも失敗した場合は終了コード 1 で終了します
+func runBatch(f *internal.Fetcher, args []string) {
+	if recursive {
+		fmt.Fprintf(os.Stderr, "エラー: --recursive は複数のパッケージと併用できません\n")
+		os.Exit(1)
+	}
+
+	packages, err := batchPackages(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 短いパッケージ名を解決（対話的に選択する場合があるため、取得の前に1つずつ解決する）
+	// 解決に失敗したパッケージも指定した順序で出力するため、パッケージごとの位置に結果を置く
+	slots := make([]internal.BatchResult, len(packages))
+	var requests []internal.BatchRequest
+	for i, arg := range packages {
+		packagePath, version := parsePackageArg(arg)
+		req := internal.BatchRequest{ImportPath: packagePath, Version: version}
+		resolved, err := tryResolvePackagePath(f, packagePath)
+		if err != nil {
+			slots[i] = internal.BatchResult{Request: req, Err: err}
+			continue
+		}
+		req.ImportPath = resolved
+		slots[i] = internal.BatchResult{Request: req}
+		requests = append(requests, req)
+	}
+
+	opts, closeVulnDB := packageOptions()
+	defer closeVulnDB()
+
+	results := mergeBatchResults(slots, f.GetPackages(requests, opts, batchConcurrency))
+
+	if outDir != "" {
+		if err := writeBatchFiles(results); err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		writeOutput(strings.TrimSuffix(internal.FormatBatchDocument(results), "\n"))
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d 件中 %d 件のパッケージの取得に失敗しました:\n%s", len(results), failed, internal.FormatBatchReport(results))
+		os.Exit(1)
+	}
+}
+
+// mergeBatchResults は取得した結果を、パッケージを指定した順序（slots の位置）に並べます
+// slots のうちエラーのあるものは解決に失敗したパッケージで、その位置にそのまま残します
+// 同じパッケージが複数回指定された場合は、GetPackages と同じく最初の位置にのみ置きます
+func mergeBatchResults(slots []internal.BatchResult, fetched []internal.BatchResult) []internal.BatchResult {
+	byRequest := make(map[string]internal.BatchResult, len(fetched))
+	for _, result := range fetched {
+		byRequest[result.Request.String()] = result
+	}
+
+	results := make([]internal.BatchResult, 0, len(slots))
+	for _, slot := range slots {
+		if slot.Err != nil {
+			results = append(results, slot)
+			continue
+		}
+		key := slot.Request.String()
+		result, ok := byRequest[key]
+		if !ok {
+			continue
+		}
+		results = append(results, result)
+		delete(byRequest, key)
+	}
+	return results
+}
+
+// batchPackages は引数と --from-file で指定したパッケージの一覧を返します
+func batchPackages(args []string) ([]string, error) {
+	packages := append([]string{}, args...)
+	if fromFile == "" {
+		return packages, nil
+	}
+
+	var r io.Reader = os.Stdin
+	if fromFile != "-" {
+		file, err := os.Open(fromFile)
+		if err != nil {
+			return nil, fmt.Errorf("パッケージの一覧を開けません: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	listed, err := internal.ParseBatchList(r)
+	if err != nil {
+		return nil, err
+	}
+	packages = append(packages, listed...)
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("パッケージの一覧が空です: %s", fromFile)
+	}
+	return packages, nil
+}
+
+// writeBatchFiles は取得したサマリーを --out-dir にパッケージごとのファイルとして保存します
+func writeBatchFiles(results []internal.BatchResult) error {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("出力ディレクトリの作成に失敗しました: %w", err)
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		path := filepath.Join(outDir, internal.BatchFileName(result.Request))
+		if err := os.WriteFile(path, []byte(result.Content), 0644); err != nil {
+			return fmt.Errorf("ファイルの書き込みに失敗しました: %w", err)
+		}
+		fmt.Printf("結果を %s に保存しました\n", path)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeBatchResults(t *testing.T) {
+	resolveErr := errors.New("パッケージ 'yaml' の候補が複数あります")
+	slots := []internal.BatchResult{
+		{Request: internal.BatchRequest{ImportPath: "go.uber.org/zap", Version: "latest"}},
+		{Request: internal.BatchRequest{ImportPath: "yaml", Version: "latest"}, Err: resolveErr},
+		{Request: internal.BatchRequest{ImportPath: "github.com/spf13/cobra", Version: "v1.8.0"}},
+		{Request: internal.BatchRequest{ImportPath: "go.uber.org/zap", Version: "latest"}},
+	}
+	fetched := []internal.BatchResult{
+		{Request: internal.BatchRequest{ImportPath: "go.uber.org/zap", Version: "latest"}, Content: "# zap"},
+		{Request: internal.BatchRequest{ImportPath: "github.com/spf13/cobra", Version: "v1.8.0"}, Content: "# cobra"},
+	}
+
+	results := mergeBatchResults(slots, fetched)
+
+	assert.Equal(t, []internal.BatchResult{
+		{Request: internal.BatchRequest{ImportPath: "go.uber.org/zap", Version: "latest"}, Content: "# zap"},
+		{Request: internal.BatchRequest{ImportPath: "yaml", Version: "latest"}, Err: resolveErr},
+		{Request: internal.BatchRequest{ImportPath: "github.com/spf13/cobra", Version: "v1.8.0"}, Content: "# cobra"},
+	}, results, "解決に失敗したパッケージも指定した位置に置き、重複は最初の位置にのみ置くこと")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// card コマンドのフラグ変数
+	cardJSON bool
+)
+
+// cardCmd はパッケージのクイックリファレンスを出力するコマンドです
+var cardCmd = &cobra.Command{
+	Use:   "card [package-path][@version]",
+	Short: "パッケージのクイックリファレンスを出力",
+	Long: `パッケージの公開APIをドキュメントコメントとともに分類し、1画面に収まるクイックリファレンスを出力します。
+
+次の要素を抽出します。
+  コンストラクタ            New で始まる関数（返す型ごとにまとめます）
+  オプション                Option、Options、Config で終わる型と、With で始まる関数オプション
+  エラー                    Err で始まる変数と、Error で終わる型
+  実装するインターフェース  公開メソッドのみを持ち、パッケージ外から実装できるインターフェース`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// パッケージパスとバージョンを解析
+		packagePath, version := parsePackageArg(args[0])
+
+		// Fetcherを作成
+		f, err := newFetcher(debug)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 短いパッケージ名を解決
+		packagePath = resolvePackagePath(f, packagePath)
+
+		// クイックリファレンスを生成
+		card, err := f.GetPackageCard(packagePath, version, internal.CardOptions{HideDeprecated: hideDeprecated, UseCache: !noCache})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		content := internal.FormatPackageCard(card)
+		if cardJSON {
+			content, err = internal.FormatPackageCardJSON(card)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// 結果を出力
+		writeOutput(strings.TrimSuffix(content, "\n"))
+	},
+}
+
+func init() {
+	cardCmd.Flags().BoolVar(&cardJSON, "json", false, "JSON 形式で出力する")
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// projectConfig は読み込んだ設定ファイルの内容です（設定ファイルがない場合は空）
+var projectConfig = &internal.Config{}
+
+// configCmd は設定ファイルを扱うコマンドです
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "設定ファイル（.gopkgsummary.yaml）を扱う",
+	Long: `設定ファイル（.gopkgsummary.yaml）を扱います。
+
+設定ファイルはカレントディレクトリとその親ディレクトリのうち最も近いものと、$XDG_CONFIG_HOME/.gopkgsummary.yaml を
+読み込み、プロジェクトの設定をユーザーの設定より優先します。コマンドラインで指定したフラグは設定ファイルより優先されます。
+
+  defaults:            # フラグ名ごとの既定値
+    no-cache: true
+    auto-search: false
+    format: dot        # graph コマンドの --format
+  include: ["*.go", "go.mod"]
+  exclude: ["*_test.go"]
+  aliases:
+    zap: go.uber.org/zap
+  hosts:
+    github.com:
+      api-url: https://github.example.com/api/v3
+      token-env: GITHUB_TOKEN`,
+}
+
+// configShowCmd は有効な設定を表示するコマンドです
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "設定ファイルとフラグを反映した有効な設定を表示",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// サマリーを生成するルートコマンドのフラグにも設定ファイルを反映する
+		if err := applyConfigDefaults(rootCmd.Flags()); err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		content, err := formatEffectiveConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+		writeOutput(strings.TrimSuffix(content, "\n"))
+	},
+}
+
+// loadProjectConfig はカレントディレクトリから設定ファイルを読み込み、実行するコマンドのフラグに既定値を反映します
+func loadProjectConfig(cmd *cobra.Command) {
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = "."
+	}
+	config, err := internal.LoadConfig(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		os.Exit(1)
+	}
+	projectConfig = config
+
+	if err := applyConfigDefaults(cmd.Flags()); err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		os.Exit(1)
+	}
+	if debug {
+		for _, source := range config.Sources {
+			fmt.Fprintf(os.Stderr, "設定ファイルを読み込みました: %s\n", source)
+		}
+	}
+}
+
+// configDefaults は設定ファイルの defaults に include と exclude を加えたフラグの既定値を返します
+func configDefaults() map[string]any {
+	defaults := map[string]any{}
+	if projectConfig.Include != nil {
+		defaults["include"] = projectConfig.Include
+	}
+	if projectConfig.Exclude != nil {
+		defaults["exclude"] = projectConfig.Exclude
+	}
+	for name, value := range projectConfig.Defaults {
+		defaults[name] = value
+	}
+	return defaults
+}
+
+// applyConfigDefaults はコマンドラインで指定されていないフラグに設定ファイルの既定値を設定します
+// コマンドにないフラグの既定値は無視します
+func applyConfigDefaults(flags *pflag.FlagSet) error {
+	for name, value := range configDefaults() {
+		flag := flags.Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := setFlagValue(flag, value); err != nil {
+			return fmt.Errorf("設定ファイルの %s の値が正しくありません: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setFlagValue は設定ファイルの値をフラグに設定します（リストはスライスのフラグの値を置き換えます）
+func setFlagValue(flag *pflag.Flag, value any) error {
+	items, ok := value.([]any)
+	if !ok {
+		if strs, isStrings := value.([]string); isStrings {
+			for _, s := range strs {
+				items = append(items, s)
+			}
+			ok = true
+		}
+	}
+	if !ok {
+		return flag.Value.Set(fmt.Sprint(value))
+	}
+
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = fmt.Sprint(item)
+	}
+	if slice, isSlice := flag.Value.(pflag.SliceValue); isSlice {
+		return slice.Replace(values)
+	}
+	return flag.Value.Set(strings.Join(values, ","))
+}
+
+// newFetcher は設定ファイルの hosts を反映した Fetcher を作成します
+func newFetcher(debug bool) (*internal.Fetcher, error) {
+	return internal.NewFetcher(debug, projectConfig.FetcherOptions()...)
+}
+
+// effectiveConfig は config show で表示する有効な設定です
+type effectiveConfig struct {
+	Sources  []string                 `yaml:"sources"`
+	Flags    map[string]any           `yaml:"flags"`
+	Defaults map[string]any           `yaml:"defaults,omitempty"`
+	Hosts    map[string]effectiveHost `yaml:"hosts"`
+	Aliases  map[string]string        `yaml:"aliases,omitempty"`
+	Unknown  []string                 `yaml:"unknown-defaults,omitempty"`
+}
+
+// effectiveHost はホストごとの有効な接続先と、トークンが設定されているか（トークン自体は表示しない）です
+type effectiveHost struct {
+	APIURL   string `yaml:"api-url"`
+	TokenEnv string `yaml:"token-env,omitempty"`
+	Token    string `yaml:"token,omitempty"`
+}
+
+// formatEffectiveConfig は読み込んだ設定ファイルと、サマリーの生成に使用するフラグの値を YAML で返します
+func formatEffectiveConfig() (string, error) {
+	effective := effectiveConfig{
+		Sources:  projectConfig.Sources,
+		Flags:    map[string]any{},
+		Defaults: projectConfig.Defaults,
+		Hosts:    map[string]effectiveHost{},
+		Aliases:  projectConfig.Aliases,
+	}
+	if effective.Sources == nil {
+		effective.Sources = []string{}
+	}
+
+	rootCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "help" {
+			return
+		}
+		effective.Flags[flag.Name] = flagValue(flag)
+	})
+
+	for host, hostConfig := range projectConfig.EffectiveHosts() {
+		h := effectiveHost{APIURL: hostConfig.APIURL, TokenEnv: hostConfig.TokenEnv}
+		if hostConfig.TokenEnv != "" {
+			h.Token = "未設定"
+			if os.Getenv(hostConfig.TokenEnv) != "" {
+				h.Token = "設定済み"
+			}
+		}
+		effective.Hosts[host] = h
+	}
+
+	// どのコマンドのフラグにも該当しない既定値を報告する
+	for name := range configDefaults() {
+		if !hasFlag(rootCmd, name) {
+			effective.Unknown = append(effective.Unknown, name)
+		}
+	}
+	sort.Strings(effective.Unknown)
+
+	var output strings.Builder
+	encoder := yaml.NewEncoder(&output)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(effective); err != nil {
+		return "", fmt.Errorf("設定のエンコードに失敗しました: %w", err)
+	}
+	return output.String(), nil
+}
+
+// flagValue はフラグの値を型に応じて返します
+func flagValue(flag *pflag.Flag) any {
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		return slice.GetSlice()
+	}
+	switch flag.Value.Type() {
+	case "bool":
+		if b, err := strconv.ParseBool(flag.Value.String()); err == nil {
+			return b
+		}
+	case "int":
+		if n, err := strconv.Atoi(flag.Value.String()); err == nil {
+			return n
+		}
+	}
+	return flag.Value.String()
+}
+
+// hasFlag はコマンドまたはそのサブコマンドが name のフラグを持つかを判定します
+func hasFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, sub := range cmd.Commands() {
+		if hasFlag(sub, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+}
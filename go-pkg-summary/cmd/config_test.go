@@ -0,0 +1,44 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyConfigDefaults(t *testing.T) {
+	var noCache bool
+	var mode string
+	var include []string
+	var concurrency int
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.BoolVar(&noCache, "no-cache", false, "")
+	flags.StringVar(&mode, "search-mode", "package", "")
+	flags.StringSliceVar(&include, "include", []string{"README.md"}, "")
+	flags.IntVar(&concurrency, "concurrency", 4, "")
+	require.NoError(t, flags.Parse([]string{"--search-mode", "symbol"}))
+
+	saved := projectConfig
+	defer func() { projectConfig = saved }()
+	projectConfig = &internal.Config{
+		Defaults: map[string]any{
+			"no-cache":    true,
+			"search-mode": "package",
+			"concurrency": 8,
+			"format":      "dot",
+		},
+		Include: []string{"*.go", "go.mod"},
+	}
+
+	require.NoError(t, applyConfigDefaults(flags))
+	assert.True(t, noCache)
+	assert.Equal(t, "symbol", mode, "コマンドラインで指定したフラグが優先されること")
+	assert.Equal(t, []string{"*.go", "go.mod"}, include, "リストは既定値を置き換えること")
+	assert.Equal(t, 8, concurrency)
+
+	projectConfig = &internal.Config{Defaults: map[string]any{"concurrency": "many"}}
+	assert.Error(t, applyConfigDefaults(flags), "フラグの型に合わない値はエラーになること")
+}
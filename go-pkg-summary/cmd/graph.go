@@ -0,0 +1,73 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// graph コマンドのフラグ変数
+	graphOutputFormat string
+	graphDepth        int
+	graphLicenses     bool
+)
+
+// graphCmd はパッケージの依存グラフを出力するコマンドです
+var graphCmd = &cobra.Command{
+	Use:   "graph [package-path][@version]",
+	Short: "パッケージの依存グラフを出力",
+	Long: `パッケージがインポートしているパッケージと、go.mod の require を推移的にたどったモジュールの依存グラフを出力します。
+
+テスト以外のファイルのインポートを対象とし、各ノードを次の種類に分類します。
+  target       対象のパッケージ
+  stdlib       標準ライブラリ
+  same-module  対象と同じモジュールのパッケージ
+  third-party  サードパーティのパッケージ
+  module       go.mod の require で依存しているモジュール
+モジュールのバージョンは、依存先で要求されたものの中で最大のものを表示します。`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// パッケージパスとバージョンを解析
+		packagePath, version := parsePackageArg(args[0])
+
+		// Fetcherを作成
+		f, err := newFetcher(debug)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 短いパッケージ名を解決
+		packagePath = resolvePackagePath(f, packagePath)
+
+		// 依存グラフを生成
+		graph, err := f.GetDependencyGraph(packagePath, version, internal.DependencyGraphOptions{
+			Depth:    graphDepth,
+			Licenses: graphLicenses,
+			UseCache: !noCache,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		content, err := graph.Render(graphOutputFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 結果を出力
+		writeOutput(strings.TrimSuffix(content, "\n"))
+	},
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphOutputFormat, "format", internal.GraphFormatMermaid, "出力形式（mermaid, dot, json）")
+	graphCmd.Flags().IntVar(&graphDepth, "depth", 0, "go.mod の require をたどる深さ（0 の場合は無制限）")
+	graphCmd.Flags().BoolVar(&graphLicenses, "licenses", true, "モジュールのライセンスを取得する")
+}
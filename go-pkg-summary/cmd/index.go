@@ -0,0 +1,173 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// index と semantic-search コマンドのフラグ変数
+	indexDB         string
+	indexEmbedder   string
+	indexDimensions int
+	searchLimit     int
+	searchJSON      bool
+)
+
+// indexCmd はパッケージの公開APIをベクトルストアに索引付けするコマンドです
+var indexCmd = &cobra.Command{
+	Use:   "index [package-path][@version]...",
+	Short: "パッケージの公開APIを意味検索用の索引に追加",
+	Long: `パッケージの公開APIを宣言ごとのチャンクに分け、埋め込みモデルでベクトルに変換して DuckDB の索引に追加します。
+索引は VSS 拡張機能の HNSW インデックスを使用し、semantic-search コマンドで検索できます。
+同じパッケージを再び索引付けすると、そのパッケージのチャンクを置き換えます。
+
+埋め込みモデルは索引を作成したときのものが記録され、以降は同じモデルと次元数のみ使用できます。
+  hash               単語と文字の3-gramの特徴ハッシングによる決定的な埋め込み（外部のサービスを使用しません）
+  command:<コマンド>  コマンドを実行して埋め込みを作成します。コマンドは標準入力からテキストの JSON 配列を読み、
+                     同じ順序のベクトルの JSON 配列を標準出力に書き込みます（次元数は環境変数
+                     GOPKGSUMMARY_EMBEDDING_DIMENSIONS で渡します）。semantic-search でも同じコマンドを実行します
+
+DuckDB コマンド（duckdb）がインストールされている必要があります。`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && fromFile == "" {
+			return fmt.Errorf("パッケージを指定するか、--from-file でパッケージの一覧を指定してください")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		packages, err := batchPackages(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		embedder, err := internal.NewEmbedder(indexEmbedder, indexDimensions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		ix, dbPath, err := openVectorIndex()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Fetcherを作成
+		f, err := newFetcher(debug)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// パッケージごとに索引付けし、失敗したものは最後にまとめて報告する
+		var failures []string
+		for _, arg := range packages {
+			packagePath, version := parsePackageArg(arg)
+			if err := indexPackage(f, ix, embedder, packagePath, version); err != nil {
+				failures = append(failures, fmt.Sprintf("- %s: %v", arg, err))
+			}
+		}
+
+		if len(failures) > 0 {
+			fmt.Fprintf(os.Stderr, "%d 件中 %d 件のパッケージの索引付けに失敗しました:\n%s\n", len(packages), len(failures), strings.Join(failures, "\n"))
+			os.Exit(1)
+		}
+		fmt.Printf("%d 件のパッケージを %s に索引付けしました\n", len(packages), dbPath)
+	},
+}
+
+// indexPackage はパッケージの公開APIを索引に追加します
+func indexPackage(f *internal.Fetcher, ix *internal.VectorIndex, embedder internal.Embedder, packagePath string, version string) error {
+	// 短いパッケージ名を解決
+	packagePath, err := tryResolvePackagePath(f, packagePath)
+	if err != nil {
+		return err
+	}
+
+	chunks, err := f.GetPackageChunks(packagePath, version, hideDeprecated, !noCache)
+	if err != nil {
+		return err
+	}
+	if err := ix.Index(embedder, chunks); err != nil {
+		return err
+	}
+
+	indexed := packagePath
+	if len(chunks) > 0 {
+		indexed += "@" + chunks[0].Version
+	}
+	fmt.Printf("%s: %d 件の宣言を索引に追加しました\n", indexed, len(chunks))
+	return nil
+}
+
+// semanticSearchCmd は索引付けした公開APIを自然言語のクエリで検索するコマンドです
+var semanticSearchCmd = &cobra.Command{
+	Use:   "semantic-search <query>",
+	Short: "索引付けした全てのパッケージの公開APIを意味検索",
+	Long: `index コマンドで索引付けした全てのパッケージの公開APIから、クエリに近い宣言をコサイン距離の近い順に出力します。
+クエリは索引を作成したときの埋め込みモデルでベクトルに変換します。
+
+例:
+  go-pkg-summary index gopkg.in/yaml.v3 encoding/json
+  go-pkg-summary semantic-search "parse a YAML file into a struct"`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		query := strings.Join(args, " ")
+
+		ix, _, err := openVectorIndex()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		results, err := ix.Search(query, searchLimit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		content := internal.FormatSemanticSearchResults(query, results)
+		if searchJSON {
+			data, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+				os.Exit(1)
+			}
+			content = string(data)
+		}
+
+		// 結果を出力
+		writeOutput(strings.TrimSuffix(content, "\n"))
+	},
+}
+
+// openVectorIndex は --db で指定した索引（指定がない場合は既定の索引）を開き、そのパスとともに返します
+func openVectorIndex() (*internal.VectorIndex, string, error) {
+	dbPath := indexDB
+	if dbPath == "" {
+		var err error
+		dbPath, err = internal.DefaultIndexPath()
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return internal.NewVectorIndex(dbPath), dbPath, nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{indexCmd, semanticSearchCmd} {
+		cmd.Flags().StringVar(&indexDB, "db", "", "索引の DuckDB データベースファイル（既定: ~/.gopkgsummary/index.duckdb）")
+	}
+	indexCmd.Flags().StringVar(&indexEmbedder, "embedder", internal.DefaultEmbedder, "埋め込みモデル（hash または command:<コマンド>）")
+	indexCmd.Flags().IntVar(&indexDimensions, "dimensions", internal.DefaultEmbeddingDimensions, "埋め込みの次元数")
+	indexCmd.Flags().StringVar(&fromFile, "from-file", "", "パッケージの一覧を1行に1つずつ記載したファイル（- の場合は標準入力）")
+	semanticSearchCmd.Flags().IntVar(&searchLimit, "limit", 10, "出力する宣言の数")
+	semanticSearchCmd.Flags().BoolVar(&searchJSON, "json", false, "JSON 形式で出力する")
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// licenses コマンドのフラグ変数
+	licensesModFile string
+	licensesPolicy  string
+	licensesAllow   []string
+	licensesDeny    []string
+	licensesJSON    bool
+)
+
+// licensesCmd は現在のモジュールの依存関係のライセンスを検査するコマンドです
+var licensesCmd = &cobra.Command{
+	Use:   "licenses",
+	Short: "現在のモジュールの依存関係のライセンスを検査",
+	Long: `現在のモジュールの go.mod にある全ての require について、モジュールプロキシから取得したモジュールの
+LICENSE や COPYING などのファイルからライセンス（SPDX 識別子）を検出し、許可されていないものを報告します。
+--no-cache を指定すると、キャッシュしたモジュールの zip を使用せずにモジュールプロキシから取得し直します。
+
+許可リストと拒否リストは --allow と --deny、または次の形式の JSON ファイルを --policy で指定します。
+"GPL-*" のようなパターンも使用できます。
+  {"allow": ["MIT", "Apache-2.0", "BSD-*"], "deny": ["AGPL-*"]}
+許可されていないライセンスのモジュールがある場合は終了コード 1 で終了します。`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// ライセンスポリシーを読み込み、フラグで指定したものを追加
+		policy := &internal.LicensePolicy{}
+		if licensesPolicy != "" {
+			loaded, err := internal.LoadLicensePolicy(licensesPolicy)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+				os.Exit(1)
+			}
+			policy = loaded
+		}
+		policy.Allow = append(policy.Allow, licensesAllow...)
+		policy.Deny = append(policy.Deny, licensesDeny...)
+
+		goMod, err := os.ReadFile(licensesModFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: go.mod の読み込みに失敗しました: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Fetcherを作成
+		f, err := newFetcher(debug)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		reports, err := f.CheckRequirementLicenses(goMod, policy, !noCache)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 結果を出力
+		var content string
+		if licensesJSON {
+			data, err := json.MarshalIndent(reports, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+				os.Exit(1)
+			}
+			content = string(data)
+		} else {
+			content = formatLicenseReports(reports)
+		}
+		writeOutput(content)
+
+		disallowed := 0
+		for _, report := range reports {
+			if !report.Allowed {
+				disallowed++
+			}
+		}
+		if disallowed > 0 {
+			fmt.Fprintf(os.Stderr, "許可されていないライセンスのモジュールが %d 件あります\n", disallowed)
+			os.Exit(1)
+		}
+	},
+}
+
+// formatLicenseReports はライセンスの判定結果を表形式の文字列に整形します
+func formatLicenseReports(reports []internal.LicenseReport) string {
+	var output strings.Builder
+
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "モジュール\tバージョン\tライセンス\t判定")
+	for _, report := range reports {
+		module := report.ModulePath
+		if report.Indirect {
+			module += " (indirect)"
+		}
+
+		licenses := strings.Join(report.Licenses, ", ")
+		if licenses == "" {
+			licenses = "-"
+		}
+
+		verdict := "OK"
+		if !report.Allowed {
+			verdict = "NG"
+		}
+		if report.Reason != "" {
+			verdict += fmt.Sprintf("（%s）", report.Reason)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", module, report.Version, licenses, verdict)
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(output.String(), "\n")
+}
+
+func init() {
+	licensesCmd.Flags().StringVar(&licensesModFile, "modfile", "go.mod", "検査する go.mod のパス")
+	licensesCmd.Flags().StringVar(&licensesPolicy, "policy", "", "許可リストと拒否リストを記述した JSON ファイル")
+	licensesCmd.Flags().StringSliceVar(&licensesAllow, "allow", nil, "許可するライセンス（SPDX 識別子）")
+	licensesCmd.Flags().StringSliceVar(&licensesDeny, "deny", nil, "拒否するライセンス（SPDX 識別子）")
+	licensesCmd.Flags().BoolVar(&licensesJSON, "json", false, "JSON 形式で出力する")
+}
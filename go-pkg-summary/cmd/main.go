@@ -16,84 +16,159 @@ var (
 	outputFile string
 	debug      bool
 	include    []string
+	exclude    []string
 	dryRun     bool
 	autoSearch bool
+	searchMode string
+
+	// --recursive 用のフラグ変数
+	recursive       bool
+	includeInternal bool
+	graphFormat     string
+
+	// 脆弱性データベースのパス
+	vulnDBPath string
+	// README で残すセクション
+	readmeSections []string
+	// サマリーの出力に使用するテンプレートファイル
+	templateFile string
+	// 非推奨の宣言を出力しない
+	hideDeprecated bool
+
+	// 複数のパッケージをまとめて取得する場合のフラグ変数
+	fromFile         string
+	outDir           string
+	batchConcurrency int
 )
 
 // rootCmd はルートコマンドです
 var rootCmd = &cobra.Command{
-	Use:   "go-pkg-summary [package-path][@version]",
+	Use:   "go-pkg-summary [package-path][@version]...",
 	Short: "Goパッケージの型定義、関数、構造体などを解析し、サマリーを生成するツール",
 	Long: `go-pkg-summary はGoパッケージの型定義、関数、構造体などを解析し、サマリーを生成するコマンドラインツールです。
 パッケージパスとオプションのバージョンを指定して実行します。
-完全なインポートパス（例: go.uber.org/zap）を指定するか、--auto-search フラグを使用して短い名前（例: zap）から検索できます。`,
-	Args: cobra.MinimumNArgs(1),
+完全なインポートパス（例: go.uber.org/zap）を指定するか、--auto-search フラグを使用して短い名前（例: zap）から検索できます。
+複数のパッケージを指定するか、--from-file で一覧を読み込むと、並行して取得したサマリーを目次付きの1つの文書、
+または --out-dir のディレクトリにパッケージごとのファイルとして出力します。`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// 設定ファイルを読み込み、指定されていないフラグに既定値を設定
+		loadProjectConfig(cmd)
+	},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && fromFile == "" {
+			return fmt.Errorf("パッケージを指定するか、--from-file でパッケージの一覧を指定してください")
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		// Fetcherを作成
+		f, err := newFetcher(debug)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 複数のパッケージをまとめて取得
+		if len(args) > 1 || fromFile != "" || outDir != "" {
+			runBatch(f, args)
+			return
+		}
+
 		// パッケージパスとバージョンを解析
 		packagePath, version := parsePackageArg(args[0])
 
-		// 自動検索が有効で、パッケージパスにスラッシュが含まれていない場合は検索を行う
-		if autoSearch && !strings.Contains(packagePath, "/") {
-			// Fetcherを作成
-			f, err := internal.NewFetcher(debug)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
-				os.Exit(1)
-			}
+		// 短いパッケージ名を解決
+		packagePath = resolvePackagePath(f, packagePath)
 
-			// パッケージを検索
-			results, err := f.SearchPackage(packagePath, 1)
+		// モジュール内の全パッケージのサマリーを生成
+		if recursive {
+			content, err := getModuleTreeSummary(f, packagePath, version)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "パッケージの検索に失敗しました: %v\n", err)
-				fmt.Fprintf(os.Stderr, "完全なインポートパスを指定してください。\n")
-				os.Exit(1)
-			}
-
-			if len(results) == 0 {
-				fmt.Fprintf(os.Stderr, "パッケージ '%s' が見つかりませんでした。\n", packagePath)
-				fmt.Fprintf(os.Stderr, "完全なインポートパスを指定してください。\n")
+				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
 				os.Exit(1)
 			}
-
-			// 最初の検索結果を使用
-			packagePath = results[0].ImportPath
-			fmt.Printf("パッケージ '%s' を '%s' として解決しました。\n", args[0], packagePath)
+			writeOutput(content)
+			return
 		}
 
 		// オプションを設定
-		opts := internal.GetPackageOptions{
-			UseCache:   !noCache,
-			OutputFile: outputFile,
-			Include:    include,
-			DryRun:     dryRun,
-		}
+		opts, closeVulnDB := packageOptions()
+		defer closeVulnDB()
 
-		// Fetcherを作成
-		f, err := internal.NewFetcher(debug)
+		// パッケージ情報を取得
+		content, err := f.GetPackage(packagePath, version, opts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
 			os.Exit(1)
 		}
 
-		// パッケージ情報を取得
-		content, err := f.GetPackage(packagePath, version, opts)
+		// 結果を出力
+		writeOutput(content)
+	},
+}
+
+// packageOptions はフラグからサマリーの取得オプションを作成します
+// --vulndb を指定した場合は脆弱性データベースを開き、閉じる関数を返します
+func packageOptions() (internal.GetPackageOptions, func()) {
+	opts := internal.GetPackageOptions{
+		UseCache:       !noCache,
+		OutputFile:     outputFile,
+		Include:        include,
+		Exclude:        exclude,
+		DryRun:         dryRun,
+		ReadmeSections: readmeSections,
+		HideDeprecated: hideDeprecated,
+	}
+
+	// テンプレートを読み込む
+	if templateFile != "" {
+		tmpl, err := internal.LoadSummaryTemplate(templateFile)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
 			os.Exit(1)
 		}
+		opts.Template = tmpl
+	}
 
-		// 結果を出力
-		if outputFile != "" {
-			err := os.WriteFile(outputFile, []byte(content), 0644)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "ファイルの書き込みに失敗しました: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Printf("結果を %s に保存しました\n", outputFile)
-		} else {
-			fmt.Println(content)
+	// 脆弱性データベースを開く
+	if vulnDBPath == "" {
+		return opts, func() {}
+	}
+	db, err := internal.OpenVulnDB(vulnDBPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		os.Exit(1)
+	}
+	opts.VulnDB = db
+	return opts, func() { db.Close() }
+}
+
+// getModuleTreeSummary はパッケージを含むモジュール内の全パッケージのサマリーを生成します
+func getModuleTreeSummary(f *internal.Fetcher, packagePath string, version string) (string, error) {
+	format := graphFormat
+	if format == "none" {
+		format = ""
+	}
+
+	tree, err := f.GetModuleTree(packagePath, version, internal.ModuleTreeOptions{IncludeInternal: includeInternal, HideDeprecated: hideDeprecated, UseCache: !noCache})
+	if err != nil {
+		return "", err
+	}
+	return internal.FormatModuleTree(tree, format)
+}
+
+// writeOutput は結果を --out で指定したファイル、または標準出力に書き込みます
+func writeOutput(content string) {
+	if outputFile != "" {
+		err := os.WriteFile(outputFile, []byte(content), 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ファイルの書き込みに失敗しました: %v\n", err)
+			os.Exit(1)
 		}
-	},
+		fmt.Printf("結果を %s に保存しました\n", outputFile)
+	} else {
+		fmt.Println(content)
+	}
 }
 
 // lsCmd はファイル一覧を表示するコマンドです
@@ -106,41 +181,16 @@ var lsCmd = &cobra.Command{
 		// パッケージパスとバージョンを解析
 		packagePath, version := parsePackageArg(args[0])
 
-		// 自動検索が有効で、パッケージパスにスラッシュが含まれていない場合は検索を行う
-		if autoSearch && !strings.Contains(packagePath, "/") {
-			// Fetcherを作成
-			f, err := internal.NewFetcher(debug)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
-				os.Exit(1)
-			}
-
-			// パッケージを検索
-			results, err := f.SearchPackage(packagePath, 1)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "パッケージの検索に失敗しました: %v\n", err)
-				fmt.Fprintf(os.Stderr, "完全なインポートパスを指定してください。\n")
-				os.Exit(1)
-			}
-
-			if len(results) == 0 {
-				fmt.Fprintf(os.Stderr, "パッケージ '%s' が見つかりませんでした。\n", packagePath)
-				fmt.Fprintf(os.Stderr, "完全なインポートパスを指定してください。\n")
-				os.Exit(1)
-			}
-
-			// 最初の検索結果を使用
-			packagePath = results[0].ImportPath
-			fmt.Printf("パッケージ '%s' を '%s' として解決しました。\n", args[0], packagePath)
-		}
-
 		// Fetcherを作成
-		f, err := internal.NewFetcher(debug)
+		f, err := newFetcher(debug)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
 			os.Exit(1)
 		}
 
+		// 短いパッケージ名を解決
+		packagePath = resolvePackagePath(f, packagePath)
+
 		// ファイル一覧を取得
 		files, err := f.ListPackageFiles(packagePath, version)
 		if err != nil {
@@ -165,80 +215,52 @@ var lsCmd = &cobra.Command{
 	},
 }
 
-// readCmd は特定のファイルを表示するコマンドです
-var readCmd = &cobra.Command{
-	Use:   "read [package-path][@version]/[file-path]",
-	Short: "パッケージ内の特定ファイルを表示",
-	Long:  `パッケージ内の特定ファイルを表示します。`,
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		// 引数を解析
-		arg := args[0]
-		slashIndex := strings.LastIndex(arg, "/")
-		if slashIndex == -1 {
-			fmt.Fprintf(os.Stderr, "エラー: 無効な形式です。[package-path][@version]/[file-path] の形式で指定してください\n")
-			os.Exit(1)
-		}
-
-		// パッケージパスとバージョンを解析
-		packageArg := arg[:slashIndex]
-		filePath := arg[slashIndex+1:]
-		packagePath, version := parsePackageArg(packageArg)
-
-		// 自動検索が有効で、パッケージパスにスラッシュが含まれていない場合は検索を行う
-		if autoSearch && !strings.Contains(packagePath, "/") {
-			// Fetcherを作成
-			f, err := internal.NewFetcher(debug)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
-				os.Exit(1)
-			}
-
-			// パッケージを検索
-			results, err := f.SearchPackage(packagePath, 1)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "パッケージの検索に失敗しました: %v\n", err)
-				fmt.Fprintf(os.Stderr, "完全なインポートパスを指定してください。\n")
-				os.Exit(1)
-			}
+// resolvePackagePath は --auto-search が有効な場合に短いパッケージ名を完全なインポートパスに解決します
+// 候補が拮抗している場合、端末から実行されていれば選択肢を提示し、そうでなければ候補を表示して終了します
+func resolvePackagePath(f *internal.Fetcher, packagePath string) string {
+	resolved, err := tryResolvePackagePath(f, packagePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		fmt.Fprintf(os.Stderr, "完全なインポートパスを指定してください。\n")
+		os.Exit(1)
+	}
+	return resolved
+}
 
-			if len(results) == 0 {
-				fmt.Fprintf(os.Stderr, "パッケージ '%s' が見つかりませんでした。\n", packagePath)
-				fmt.Fprintf(os.Stderr, "完全なインポートパスを指定してください。\n")
-				os.Exit(1)
-			}
+// tryResolvePackagePath は短いパッケージ名を完全なインポートパスに解決し、解決できない場合はエラーを返します
+func tryResolvePackagePath(f *internal.Fetcher, packagePath string) (string, error) {
+	// パッケージパスにスラッシュが含まれている場合は完全なインポートパスとみなす
+	// 標準ライブラリのパッケージ（fmt など）も検索しない
+	if !autoSearch || strings.Contains(packagePath, "/") || f.IsStdlibPackage(packagePath) {
+		return packagePath, nil
+	}
 
-			// 最初の検索結果を使用
-			packagePath = results[0].ImportPath
-			fmt.Printf("パッケージ '%s' を '%s' として解決しました。\n", packageArg, packagePath)
-		}
+	// エイリアスストアを作成（失敗した場合はエイリアスなしで解決する）
+	aliases, err := internal.NewAliasStore()
+	if err != nil && debug {
+		fmt.Printf("エイリアスファイルを使用できません: %v\n", err)
+	}
 
-		// Fetcherを作成
-		f, err := internal.NewFetcher(debug)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
-			os.Exit(1)
-		}
+	// 設定ファイルのエイリアスはエイリアスファイルより優先する
+	resolver := internal.NewResolver(f, aliases, os.Stdin, os.Stderr, isInteractive()).WithConfigAliases(projectConfig.Aliases)
+	resolved, err := resolver.Resolve(packagePath, searchMode)
+	if err != nil {
+		return "", err
+	}
 
-		// ファイルを取得
-		content, err := f.ReadPackageFile(packagePath, version, filePath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
-			os.Exit(1)
-		}
+	fmt.Fprintf(os.Stderr, "パッケージ '%s' を '%s' として解決しました。\n", packagePath, resolved)
+	return resolved, nil
+}
 
-		// 結果を出力
-		if outputFile != "" {
-			err := os.WriteFile(outputFile, []byte(content), 0644)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "ファイルの書き込みに失敗しました: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Printf("結果を %s に保存しました\n", outputFile)
-		} else {
-			fmt.Println(content)
+// isInteractive は標準入力と標準エラー出力が端末に接続されているかを判定します
+func isInteractive() bool {
+	for _, file := range []*os.File{os.Stdin, os.Stderr} {
+		info, err := file.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
 		}
-	},
+	}
+	return true
 }
 
 // parsePackageArg はパッケージ引数を解析してパッケージパスとバージョンを返します
@@ -264,12 +286,33 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&outputFile, "out", "o", "", "出力ファイル")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "デバッグモード")
 	rootCmd.PersistentFlags().StringSliceVar(&include, "include", nil, "含めるファイルパターン")
+	rootCmd.PersistentFlags().StringSliceVar(&exclude, "exclude", nil, "除くファイルパターン")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry", false, "ドライラン")
+	rootCmd.PersistentFlags().BoolVar(&hideDeprecated, "hide-deprecated", false, "非推奨（Deprecated:）の宣言を出力しない（card、--recursive、index と .Types を使用する --template に適用）")
 	rootCmd.PersistentFlags().BoolVar(&autoSearch, "auto-search", true, "短いパッケージ名を自動的に検索して解決する")
+	rootCmd.PersistentFlags().StringVar(&searchMode, "search-mode", internal.SearchModePackage, "自動検索のモード（package: パッケージ名で検索, symbol: シンボル名で検索）")
+
+	rootCmd.Flags().BoolVar(&recursive, "recursive", false, "モジュール内の全パッケージのサマリーを生成する")
+	rootCmd.Flags().BoolVar(&includeInternal, "include-internal", false, "--recursive で internal パッケージも含める")
+	rootCmd.Flags().StringVar(&graphFormat, "graph", internal.GraphFormatMermaid, "--recursive で出力するパッケージ依存グラフの形式（mermaid, dot, none）")
+	rootCmd.Flags().StringSliceVar(&readmeSections, "readme-sections", nil, "README で残すセクションの見出し（例: Installation,Usage）")
+	rootCmd.Flags().StringVar(&templateFile, "template", "", "サマリーの出力に使用する text/template 形式のテンプレートファイル")
+	rootCmd.Flags().StringVar(&fromFile, "from-file", "", "パッケージの一覧を1行に1つずつ記載したファイル（- の場合は標準入力）")
+	rootCmd.Flags().StringVar(&outDir, "out-dir", "", "パッケージごとのサマリーを保存するディレクトリ")
+	rootCmd.Flags().IntVar(&batchConcurrency, "concurrency", internal.DefaultBatchConcurrency, "複数のパッケージを同時に取得する数")
+	rootCmd.Flags().StringVar(&vulnDBPath, "vulndb", "", "既知の脆弱性を照合する vuln.go.dev 形式のデータベース（ディレクトリまたは zip）")
 
 	// サブコマンドを追加
 	rootCmd.AddCommand(lsCmd)
 	rootCmd.AddCommand(readCmd)
+	rootCmd.AddCommand(versionsCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(graphCmd)
+	rootCmd.AddCommand(licensesCmd)
+	rootCmd.AddCommand(cardCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(indexCmd)
+	rootCmd.AddCommand(semanticSearchCmd)
 }
 
 func main() {
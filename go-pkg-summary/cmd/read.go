@@ -0,0 +1,163 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// symbolNamePattern は "Name" または "Type.Method" 形式のシンボル名に一致する正規表現です
+// "README.md" や "go.mod" のようなファイル名と区別するため、メソッド名は大文字で始まるものに限ります
+// "LICENSE" のような拡張子のないファイル名にも一致するため、実行時にファイル一覧で判定し直します（preferFileTarget）
+var symbolNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Z][A-Za-z0-9_]*)?$`)
+
+// readTarget は read コマンドの読み込み対象を表す構造体です
+type readTarget struct {
+	// パッケージパス
+	packagePath string
+	// バージョン
+	version string
+	// リポジトリのルートからのファイルパス
+	filePath string
+	// 行範囲（指定がない場合は nil）
+	lines *internal.LineRange
+	// シンボル名（ファイルではなくシンボルを読む場合）
+	symbol string
+}
+
+// readCmd は特定のファイルまたはシンボルを表示するコマンドです
+var readCmd = &cobra.Command{
+	Use:   "read [package-path][@version] -- [file-path][#L10-L80] | [package-path][@version]:[file-path][#L10-L80] | [package-path][@version] [Symbol]",
+	Short: "パッケージ内の特定ファイルまたはシンボルを表示",
+	Long: `パッケージ内の特定ファイル、またはシンボルの宣言を表示します。
+
+ファイルはリポジトリのルートからのパスで指定します。
+  go-pkg-summary read github.com/stretchr/testify@v1.10.0 -- assert/assertions.go
+  go-pkg-summary read github.com/stretchr/testify@v1.10.0:assert/assertions.go#L10-L80
+
+シンボルを指定すると、その宣言のソースコードをドキュメントコメント付きで表示します。
+  go-pkg-summary read github.com/stretchr/testify/assert Equal
+  go-pkg-summary read go.uber.org/zap Logger.Sugar`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// 引数を解析
+		target, err := parseReadArgs(args, cmd.ArgsLenAtDash())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Fetcherを作成
+		f, err := newFetcher(debug)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 短いパッケージ名を解決
+		target.packagePath = resolvePackagePath(f, target.packagePath)
+
+		// シンボル風の名前（LICENSE、Makefile など）は、同じ名前のファイルがあればファイルとして読む
+		if target.symbol != "" {
+			files, err := f.ListPackageFiles(target.packagePath, target.version)
+			if err != nil && debug {
+				fmt.Printf("ファイル一覧の取得に失敗しました: %v\n", err)
+			}
+			target = preferFileTarget(target, files)
+		}
+
+		// ファイルまたはシンボルを取得
+		var content string
+		if target.symbol != "" {
+			decl, err := f.ReadPackageSymbol(target.packagePath, target.version, target.symbol)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+				os.Exit(1)
+			}
+			content = fmt.Sprintf("// %s#%s\n%s", decl.Filename, decl.Lines, decl.Source)
+		} else {
+			content, err = f.ReadPackageFile(target.packagePath, target.version, target.filePath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+				os.Exit(1)
+			}
+			if target.lines != nil {
+				content, err = target.lines.Apply(content)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		}
+
+		// 結果を出力
+		writeOutput(content)
+	},
+}
+
+// preferFileTarget はシンボルとして解析した対象と同じ名前のファイルが files にある場合、ファイルを読む対象に置き換えます
+// "LICENSE" や "Makefile" のような拡張子のないファイル名はシンボル名と区別できないため、ファイル一覧で判定します
+func preferFileTarget(target readTarget, files []string) readTarget {
+	if target.symbol == "" {
+		return target
+	}
+	for _, file := range files {
+		if file == target.symbol {
+			return readTarget{packagePath: target.packagePath, version: target.version, filePath: target.symbol}
+		}
+	}
+	return target
+}
+
+// parseReadArgs は read コマンドの引数を解析します
+// dashIndex は "--" より前の引数の数です（"--" がない場合は -1）
+func parseReadArgs(args []string, dashIndex int) (readTarget, error) {
+	var packageArg, fileArg string
+
+	switch {
+	case len(args) == 2 && dashIndex == 1:
+		// pkg@v -- path/to/file.go
+		packageArg, fileArg = args[0], args[1]
+	case len(args) == 2:
+		// pkg@v Symbol または pkg@v path/to/file.go
+		packageArg = args[0]
+		if symbolNamePattern.MatchString(args[1]) {
+			packagePath, version := parsePackageArg(packageArg)
+			return readTarget{packagePath: packagePath, version: version, symbol: args[1]}, nil
+		}
+		fileArg = args[1]
+	case strings.Contains(args[0], ":"):
+		// pkg@v:path/to/file.go
+		packageArg, fileArg, _ = strings.Cut(args[0], ":")
+	default:
+		// 互換性のための pkg@v/file.go 形式（ファイルはパッケージパスの最後の要素の後ろ）
+		slashIndex := strings.LastIndex(args[0], "/")
+		if slashIndex == -1 {
+			return readTarget{}, fmt.Errorf("無効な形式です。[package-path][@version] -- [file-path] または [package-path][@version]:[file-path] の形式で指定してください")
+		}
+		packageArg, fileArg = args[0][:slashIndex], args[0][slashIndex+1:]
+	}
+
+	packagePath, version := parsePackageArg(packageArg)
+	target := readTarget{packagePath: packagePath, version: version}
+
+	// #L10-L80 形式の行範囲を分離
+	filePath, fragment, hasFragment := strings.Cut(fileArg, "#")
+	if filePath == "" {
+		return readTarget{}, fmt.Errorf("ファイルパスが指定されていません")
+	}
+	target.filePath = filePath
+	if hasFragment {
+		lines, err := internal.ParseLineRange(fragment)
+		if err != nil {
+			return readTarget{}, err
+		}
+		target.lines = &lines
+	}
+
+	return target, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReadArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		dashIndex int
+		want      readTarget
+	}{
+		{
+			name:      "ダッシュ区切り",
+			args:      []string{"github.com/stretchr/testify@v1.10.0", "assert/assertions.go"},
+			dashIndex: 1,
+			want:      readTarget{packagePath: "github.com/stretchr/testify", version: "v1.10.0", filePath: "assert/assertions.go"},
+		},
+		{
+			name:      "コロン区切りと行範囲",
+			args:      []string{"github.com/stretchr/testify@v1.10.0:assert/assertions.go#L10-L80"},
+			dashIndex: -1,
+			want: readTarget{
+				packagePath: "github.com/stretchr/testify",
+				version:     "v1.10.0",
+				filePath:    "assert/assertions.go",
+				lines:       &internal.LineRange{Start: 10, End: 80},
+			},
+		},
+		{
+			name:      "シンボル",
+			args:      []string{"go.uber.org/zap", "Logger.Sugar"},
+			dashIndex: -1,
+			want:      readTarget{packagePath: "go.uber.org/zap", version: "latest", symbol: "Logger.Sugar"},
+		},
+		{
+			name:      "ファイル名はシンボルとみなさない",
+			args:      []string{"go.uber.org/zap", "go.mod"},
+			dashIndex: -1,
+			want:      readTarget{packagePath: "go.uber.org/zap", version: "latest", filePath: "go.mod"},
+		},
+		{
+			name:      "ダッシュの後のシンボル風の名前はファイルとみなす",
+			args:      []string{"go.uber.org/zap", "LICENSE"},
+			dashIndex: 1,
+			want:      readTarget{packagePath: "go.uber.org/zap", version: "latest", filePath: "LICENSE"},
+		},
+		{
+			name:      "従来のスラッシュ区切り",
+			args:      []string{"github.com/stretchr/testify@latest/README.md"},
+			dashIndex: -1,
+			want:      readTarget{packagePath: "github.com/stretchr/testify", version: "latest", filePath: "README.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReadArgs(tt.args, tt.dashIndex)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPreferFileTarget(t *testing.T) {
+	files := []string{"LICENSE", "Makefile", "go.mod", "logger.go"}
+
+	tests := []struct {
+		name string
+		arg  string
+		want readTarget
+	}{
+		{
+			name: "LICENSE はファイルとして読む",
+			arg:  "LICENSE",
+			want: readTarget{packagePath: "go.uber.org/zap", version: "latest", filePath: "LICENSE"},
+		},
+		{
+			name: "Makefile はファイルとして読む",
+			arg:  "Makefile",
+			want: readTarget{packagePath: "go.uber.org/zap", version: "latest", filePath: "Makefile"},
+		},
+		{
+			name: "同じ名前のファイルがないものはシンボルとして読む",
+			arg:  "Logger.Sugar",
+			want: readTarget{packagePath: "go.uber.org/zap", version: "latest", symbol: "Logger.Sugar"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := parseReadArgs([]string{"go.uber.org/zap", tt.arg}, -1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, preferFileTarget(target, files))
+		})
+	}
+
+	target, err := parseReadArgs([]string{"go.uber.org/zap", "Dockerfile"}, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "Dockerfile", preferFileTarget(target, nil).symbol, "ファイル一覧を取得できない場合はシンボルとして読むこと")
+}
+
+func TestParseReadArgsErrors(t *testing.T) {
+	_, err := parseReadArgs([]string{"testify"}, -1)
+	assert.Error(t, err, "ファイルパスがない場合はエラーになること")
+
+	_, err = parseReadArgs([]string{"github.com/stretchr/testify:README.md#L9-L1"}, -1)
+	assert.Error(t, err, "無効な行範囲はエラーになること")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+// serverVersion は MCP サーバーとして名乗るバージョンです
+const serverVersion = "0.1.0"
+
+var (
+	// serve コマンドのフラグ変数
+	serveMCP  bool
+	serveHTTP string
+)
+
+// serveCmd は go-pkg-summary をサーバーとして起動するコマンドです
+var serveCmd = &cobra.Command{
+	Use:   "serve (--mcp | --http [address])",
+	Short: "go-pkg-summary をサーバーとして起動",
+	Long: `go-pkg-summary をサーバーとして起動します。
+
+--mcp を指定すると、標準入出力で Model Context Protocol を話すサーバーとして動作し、
+search, find, summary, ls, read, versions の各ツールを JSON の入出力で提供します。
+標準出力はプロトコルに使用するため、--debug の出力は無効になります。
+
+--http を指定すると、次の REST API を提供します。
+Accept ヘッダーに text/markdown を指定すると Markdown、それ以外は JSON を返します。
+  GET /search?q=zap&limit=10&mode=package|symbol
+  GET /pkg/{path}@{version}
+  GET /pkg/{path}@{version}/files
+  GET /pkg/{path}@{version}/files/{file}?lines=L10-L80
+同じパッケージへの同時リクエストは1回の取得にまとめられ、全てのリクエストで同じキャッシュを共有します。`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if serveMCP == (serveHTTP != "") {
+			fmt.Fprintf(os.Stderr, "エラー: --mcp と --http のどちらか一方を指定してください\n")
+			os.Exit(1)
+		}
+
+		// Fetcherを作成（MCP ではデバッグ出力が標準出力に書かれるため無効にする）
+		f, err := newFetcher(debug && !serveMCP)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 短いパッケージ名は対話なしで解決する
+		var resolver *internal.Resolver
+		if autoSearch {
+			// エイリアスファイルを使用できない場合はエイリアスなしで解決する
+			aliases, _ := internal.NewAliasStore()
+			resolver = internal.NewResolver(f, aliases, nil, nil, false).WithConfigAliases(projectConfig.Aliases)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		if serveMCP {
+			err = runMCPServer(ctx, f, resolver)
+		} else {
+			err = runHTTPServer(ctx, f, resolver, serveHTTP)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// runMCPServer は標準入出力で MCP サーバーを実行します
+func runMCPServer(ctx context.Context, f *internal.Fetcher, resolver *internal.Resolver) error {
+	server := internal.NewMCPServer(f, resolver, serverVersion)
+	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// runHTTPServer は指定したアドレスで REST API サーバーを実行します
+func runHTTPServer(ctx context.Context, f *internal.Fetcher, resolver *internal.Resolver, addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           internal.NewAPIServer(f, resolver),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// 割り込みを受けたら処理中のリクエストを待って終了する
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "HTTP サーバーを %s で起動しました\n", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func init() {
+	serveCmd.Flags().BoolVar(&serveMCP, "mcp", false, "標準入出力で MCP サーバーとして動作する")
+	serveCmd.Flags().StringVar(&serveHTTP, "http", "", "REST API を提供するアドレス（例: :8080）")
+}
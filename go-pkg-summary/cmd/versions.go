@@ -0,0 +1,88 @@
+package main
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// versionsCmd は公開バージョン一覧を表示するコマンドです
+var versionsCmd = &cobra.Command{
+	Use:   "versions [package-path]",
+	Short: "パッケージの公開バージョン一覧を表示",
+	Long: `パッケージを含むモジュールの公開バージョン一覧（タグ付き、プレリリース、撤回済み）を公開日時とともに表示します。
+モジュールプロキシの @v/list と .info を使用し、取得できない場合は pkg.go.dev のバージョンタブにフォールバックします。`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// バージョン指定は無視してパッケージパスのみを使用
+		packagePath, _ := parsePackageArg(args[0])
+
+		// Fetcherを作成
+		f, err := newFetcher(debug)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 短いパッケージ名を解決
+		packagePath = resolvePackagePath(f, packagePath)
+
+		// バージョン一覧を取得
+		versions, err := f.ListVersions(packagePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 結果を出力
+		writeOutput(strings.TrimSuffix(formatVersions(versions), "\n"))
+	},
+}
+
+// formatVersions はバージョン一覧を表形式の文字列に整形します
+func formatVersions(versions *internal.ModuleVersions) string {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("モジュール: %s\n", versions.ModulePath))
+	output.WriteString(fmt.Sprintf("取得元: %s\n\n", versions.Source))
+
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "バージョン\t公開日時\t備考")
+	for _, v := range versions.Versions {
+		published := "-"
+		if !v.Time.IsZero() {
+			published = v.Time.Format("2006-01-02 15:04")
+		}
+
+		var notes []string
+		if v.Pseudo {
+			notes = append(notes, "疑似バージョン")
+		}
+		if v.PreRelease {
+			notes = append(notes, "プレリリース")
+		}
+		if v.Retracted {
+			note := "撤回済み"
+			if v.RetractReason != "" {
+				note += fmt.Sprintf("（%s）", v.RetractReason)
+			}
+			notes = append(notes, note)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Version, published, strings.Join(notes, ", "))
+	}
+	w.Flush()
+
+	if len(versions.MajorSiblings) > 0 {
+		output.WriteString("\n他のメジャーバージョン:\n")
+		for _, sibling := range versions.MajorSiblings {
+			output.WriteString(fmt.Sprintf("- %s\n", sibling))
+		}
+	}
+
+	return output.String()
+}
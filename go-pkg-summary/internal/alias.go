@@ -0,0 +1,99 @@
+// Package alias は短いパッケージ名とインポートパスの対応を保存する機能を提供します
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// AliasFileName はエイリアスファイル名です
+	AliasFileName = "aliases.json"
+)
+
+// AliasStore は短いパッケージ名からインポートパスへの対応をファイルに保存する構造体です
+// serve コマンドでは複数のリクエストから同時に使用されるため、読み書きを排他します
+type AliasStore struct {
+	// エイリアスファイルのパス
+	path string
+	// ファイルの読み込みと、読み込み・変更・書き込みの一連の処理を排他するロック
+	mu sync.RWMutex
+}
+
+// NewAliasStore は ~/.gopkgsummary/aliases.json を使用するエイリアスストアを作成します
+func NewAliasStore() (*AliasStore, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+	}
+
+	return &AliasStore{path: filepath.Join(homeDir, CacheDirName, AliasFileName)}, nil
+}
+
+// NewAliasStoreAt は指定したファイルを使用するエイリアスストアを作成します
+func NewAliasStoreAt(path string) *AliasStore {
+	return &AliasStore{path: path}
+}
+
+// Path はエイリアスファイルのパスを返します
+func (a *AliasStore) Path() string {
+	return a.path
+}
+
+// Get は短い名前に対応するインポートパスを返します
+func (a *AliasStore) Get(name string) (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	aliases, err := a.load()
+	if err != nil {
+		return "", false
+	}
+	importPath, ok := aliases[name]
+	return importPath, ok
+}
+
+// Set は短い名前とインポートパスの対応を保存します
+// 一時ファイルに書き込んでから名前を変更するため、他のプロセスが書き込み途中のファイルを読むことはありません
+func (a *AliasStore) Set(name string, importPath string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	aliases, err := a.load()
+	if err != nil {
+		return err
+	}
+	aliases[name] = importPath
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("エイリアスのエンコードに失敗しました: %w", err)
+	}
+	return writeFileAtomic(a.path, append(data, '\n'), 0644)
+}
+
+// load はエイリアスファイルを読み込みます（存在しない場合は空のマップを返します）
+func (a *AliasStore) load() (map[string]string, error) {
+	aliases := map[string]string{}
+
+	data, err := os.ReadFile(a.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("エイリアスファイルのパースに失敗しました（%s）: %w", a.path, err)
+	}
+	return aliases, nil
+}
@@ -0,0 +1,174 @@
+// Package batch は複数のパッケージのサマリーをまとめて生成する機能を提供します
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// DefaultBatchConcurrency は複数のパッケージを同時に取得する既定の数です
+const DefaultBatchConcurrency = 4
+
+// BatchRequest はまとめて取得するパッケージの1件です
+type BatchRequest struct {
+	// インポートパス
+	ImportPath string
+	// バージョン（"latest" または空の場合は最新）
+	Version string
+}
+
+// String は "インポートパス@バージョン" の形式で返します
+func (r BatchRequest) String() string {
+	if r.Version == "" || r.Version == "latest" {
+		return r.ImportPath
+	}
+	return r.ImportPath + "@" + r.Version
+}
+
+// BatchResult はパッケージごとの取得結果です
+type BatchResult struct {
+	// 取得したパッケージ
+	Request BatchRequest
+	// サマリー（失敗した場合は空）
+	Content string
+	// 取得に失敗した場合のエラー
+	Err error
+}
+
+// ParseBatchList はパッケージの一覧を1行に1つずつ読み込みます
+// 空行と "#" で始まる行は無視します
+func ParseBatchList(r io.Reader) ([]string, error) {
+	var packages []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		packages = append(packages, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("パッケージの一覧の読み込みに失敗しました: %w", err)
+	}
+	return packages, nil
+}
+
+// GetPackages は複数のパッケージのサマリーを並行して取得し、requests と同じ順序で返します
+// 同じパッケージが複数回指定された場合は1回だけ取得し、パッケージ情報は Fetcher の中で共有します
+// 失敗したパッケージは BatchResult.Err に記録し、他のパッケージの取得を続けます
+func (f *Fetcher) GetPackages(requests []BatchRequest, opts GetPackageOptions, concurrency int) []BatchResult {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	var unique []BatchRequest
+	seen := map[string]bool{}
+	for _, req := range requests {
+		if req.Version == "" {
+			req.Version = "latest"
+		}
+		if seen[req.String()] {
+			continue
+		}
+		seen[req.String()] = true
+		unique = append(unique, req)
+	}
+
+	results := make([]BatchResult, len(unique))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, req := range unique {
+		wg.Add(1)
+		go func(i int, req BatchRequest) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			content, err := f.GetPackage(req.ImportPath, req.Version, opts)
+			results[i] = BatchResult{Request: req, Content: content, Err: err}
+		}(i, req)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// FormatBatchDocument は取得したサマリーを目次付きの1つの文書にまとめます
+// 各パッケージの見出しは1段下げ、失敗したパッケージは末尾の一覧に記載します
+func FormatBatchDocument(results []BatchResult) string {
+	var output strings.Builder
+
+	output.WriteString("# パッケージサマリー\n\n")
+	output.WriteString("## 目次\n\n")
+	for _, result := range results {
+		if result.Err != nil {
+			output.WriteString(fmt.Sprintf("- %s（取得に失敗しました）\n", result.Request))
+			continue
+		}
+		output.WriteString(fmt.Sprintf("- [%s](#%s)\n", result.Request, batchAnchor(result.Request)))
+	}
+	output.WriteString("\n")
+
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		output.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", batchAnchor(result.Request)))
+		output.WriteString(strings.TrimSuffix(demoteHeadings(result.Content), "\n"))
+		output.WriteString("\n\n")
+	}
+
+	if failures := FormatBatchReport(results); failures != "" {
+		output.WriteString("## 取得に失敗したパッケージ\n\n")
+		output.WriteString(failures)
+	}
+
+	return output.String()
+}
+
+// FormatBatchReport は取得に失敗したパッケージの一覧を返します（失敗がない場合は空文字列）
+func FormatBatchReport(results []BatchResult) string {
+	var output strings.Builder
+	for _, result := range results {
+		if result.Err != nil {
+			output.WriteString(fmt.Sprintf("- %s: %v\n", result.Request, result.Err))
+		}
+	}
+	return output.String()
+}
+
+// BatchFileName は --out-dir に保存するパッケージのサマリーのファイル名を返します
+// キャッシュのディレクトリと同様に、インポートパスの "/" を "-" に置き換えます
+func BatchFileName(req BatchRequest) string {
+	return strings.ReplaceAll(req.String(), "/", "-") + ".md"
+}
+
+// batchAnchor は目次から参照するパッケージのアンカーを返します
+func batchAnchor(req BatchRequest) string {
+	return "pkg-" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '-'
+	}, req.String())
+}
+
+// demoteHeadings は Markdown の見出しを1段下げます（コードブロック内の行は変更しません）
+func demoteHeadings(content string) string {
+	lines := strings.Split(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && strings.HasPrefix(line, "#") && strings.HasPrefix(strings.TrimLeft(line, "#"), " ") {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
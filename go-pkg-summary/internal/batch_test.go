@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingTransport はホストごとのリクエスト数を数える RoundTripper です
+type countingTransport struct {
+	next  http.RoundTripper
+	host  string
+	count atomic.Int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == c.host {
+		c.count.Add(1)
+	}
+	return c.next.RoundTrip(req)
+}
+
+func TestParseBatchList(t *testing.T) {
+	packages, err := ParseBatchList(strings.NewReader(`# 依存パッケージ
+go.uber.org/zap@v1.27.0
+
+  github.com/spf13/cobra
+fmt
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go.uber.org/zap@v1.27.0", "github.com/spf13/cobra", "fmt"}, packages)
+}
+
+func TestGetPackages(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	counter := &countingTransport{next: newReplayClient(t, "testify-assert").Transport, host: "pkg.go.dev"}
+	f, err := NewFetcher(false, WithHTTPClient(&http.Client{Transport: counter}), WithCache(NewCacheDir(t.TempDir())))
+	require.NoError(t, err)
+
+	results := f.GetPackages([]BatchRequest{
+		{ImportPath: "github.com/stretchr/testify/assert", Version: "latest"},
+		{ImportPath: "net/http", Version: "not-a-version"},
+		{ImportPath: "github.com/stretchr/testify/assert", Version: "v1.10.0"},
+		{ImportPath: "github.com/stretchr/testify/assert"},
+	}, GetPackageOptions{}, 2)
+
+	require.Len(t, results, 3, "同じパッケージは1回だけ取得すること")
+	assert.Equal(t, "github.com/stretchr/testify/assert", results[0].Request.String())
+	assert.NoError(t, results[0].Err)
+	assert.Contains(t, results[0].Content, "バージョン: v1.10.0\n")
+	assert.Equal(t, "net/http@not-a-version", results[1].Request.String())
+	assert.Error(t, results[1].Err)
+	assert.Equal(t, "github.com/stretchr/testify/assert@v1.10.0", results[2].Request.String())
+	assert.NoError(t, results[2].Err)
+
+	// パッケージ情報は GetPackage、ls、read の間で共有し、バージョンごとに1回だけ取得する
+	assert.LessOrEqual(t, int(counter.count.Load()), 2)
+}
+
+func TestFormatBatchDocument(t *testing.T) {
+	results := []BatchResult{
+		{
+			Request: BatchRequest{ImportPath: "go.uber.org/zap", Version: "v1.27.0"},
+			Content: "# zap\n\nインポートパス: go.uber.org/zap\n\n## ファイル一覧\n\n- zap.go\n\n```bash\n# インストール\ngo get go.uber.org/zap\n```\n",
+		},
+		{
+			Request: BatchRequest{ImportPath: "example.com/missing", Version: "latest"},
+			Err:     errors.New("パッケージが見つかりません"),
+		},
+	}
+
+	expected := "# パッケージサマリー\n\n" +
+		"## 目次\n\n" +
+		"- [go.uber.org/zap@v1.27.0](#pkg-go-uber-org-zap-v1-27-0)\n" +
+		"- example.com/missing（取得に失敗しました）\n\n" +
+		"<a id=\"pkg-go-uber-org-zap-v1-27-0\"></a>\n\n" +
+		"## zap\n\nインポートパス: go.uber.org/zap\n\n### ファイル一覧\n\n- zap.go\n\n```bash\n# インストール\ngo get go.uber.org/zap\n```\n\n" +
+		"## 取得に失敗したパッケージ\n\n" +
+		"- example.com/missing: パッケージが見つかりません\n"
+	assert.Equal(t, expected, FormatBatchDocument(results))
+
+	assert.Equal(t, "go.uber.org-zap@v1.27.0.md", BatchFileName(results[0].Request))
+	assert.Equal(t, "example.com-missing.md", BatchFileName(results[1].Request))
+}
@@ -32,6 +32,11 @@ func NewCache() (*Cache, error) {
 	return &Cache{baseDir: baseDir}, nil
 }
 
+// NewCacheDir は baseDir をベースディレクトリとするキャッシュインスタンスを作成します
+func NewCacheDir(baseDir string) *Cache {
+	return &Cache{baseDir: baseDir}
+}
+
 // GetCacheDir はパッケージのキャッシュディレクトリを取得します
 func (c *Cache) GetCacheDir(pkgPath string, version string) string {
 	// パッケージパスを正規化（github.com/user/repo → github.com-user-repo）
@@ -68,6 +73,28 @@ func (c *Cache) SaveContentToCache(pkgPath string, version string, content strin
 	return os.WriteFile(contentPath, []byte(content), 0644)
 }
 
+// writeFileAtomic は同じディレクトリの一時ファイルに書き込んでから名前を変更し、path に data を保存します
+// 同時に読み込む処理が書き込み途中のファイルを読むことはありません
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
 // GenerateHash は文字列からハッシュを生成します
 func GenerateHash(text string) string {
 	hash := sha256.Sum256([]byte(text))
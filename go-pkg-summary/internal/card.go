@@ -0,0 +1,398 @@
+// Package card はパッケージの公開APIを分類し、クイックリファレンス（チートシート）を生成する機能を提供します
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+const (
+	// TypeCategoryConstructor は New で始まるコンストラクタ関数を表します
+	TypeCategoryConstructor = "constructor"
+	// TypeCategoryOptionType はオプションを表す型（Option、Options、Config で終わる型）を表します
+	TypeCategoryOptionType = "option-type"
+	// TypeCategoryOption は With で始まる関数オプションを表します
+	TypeCategoryOption = "functional-option"
+	// TypeCategoryError は Err で始まるエラー変数と、Error で終わるエラー型を表します
+	TypeCategoryError = "error"
+	// TypeCategoryInterface は利用者が実装できるインターフェースを表します
+	TypeCategoryInterface = "interface"
+)
+
+// optionTypeSuffixes はオプションを表す型名の接尾辞です
+var optionTypeSuffixes = []string{"Option", "Options", "Config"}
+
+// ConstructorGroup はコンストラクタ関数を、それが返す型ごとにまとめた構造体です
+type ConstructorGroup struct {
+	// コンストラクタが返す型名（パッケージ外の型は "pkg.Type" の形式）
+	Type string `json:"type"`
+	// 型のドキュメントコメントの最初の行（パッケージ内の型の場合）
+	Comment string `json:"comment,omitempty"`
+	// 型が非推奨かどうか（パッケージ内の型の場合）
+	Deprecated bool `json:"deprecated,omitempty"`
+	// コンストラクタ関数
+	Constructors []TypeInfo `json:"constructors"`
+}
+
+// PackageCard はパッケージのクイックリファレンスを表す構造体です
+type PackageCard struct {
+	// インポートパス
+	ImportPath string `json:"importPath"`
+	// パッケージ名
+	Name string `json:"name"`
+	// バージョン
+	Version string `json:"version"`
+	// パッケージドキュメントの概要
+	Synopsis string `json:"synopsis,omitempty"`
+	// 返す型ごとのコンストラクタ
+	Constructors []ConstructorGroup `json:"constructors,omitempty"`
+	// オプションを表す型
+	OptionTypes []TypeInfo `json:"optionTypes,omitempty"`
+	// 関数オプション
+	Options []TypeInfo `json:"options,omitempty"`
+	// エラー変数とエラー型
+	Errors []TypeInfo `json:"errors,omitempty"`
+	// 利用者が実装できるインターフェース
+	Interfaces []TypeInfo `json:"interfaces,omitempty"`
+}
+
+// CardOptions はクイックリファレンスの生成オプションを表す構造体です
+type CardOptions struct {
+	// 非推奨の宣言を除くかどうか
+	HideDeprecated bool
+	// キャッシュしたモジュールの zip を使用するかどうか
+	UseCache bool
+}
+
+// GetPackageCard はパッケージのソースコードを取得し、クイックリファレンスを生成します
+func (f *Fetcher) GetPackageCard(importPath string, version string, opts CardOptions) (*PackageCard, error) {
+	// ドキュメントコメントのリンクは、クイックリファレンスに記載する宣言へのアンカーにする
+	api, bp, source, err := f.packageAPI(importPath, version, opts.UseCache, func(api []TypeInfo) []TypeInfo {
+		return BuildPackageCard(filterCardAPI(api, opts)).entries()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	card := BuildPackageCard(filterCardAPI(api, opts))
+	card.ImportPath = importPath
+	card.Name = bp.Name
+	card.Version = source.Version
+	card.Synopsis = bp.Doc
+	return card, nil
+}
+
+// filterCardAPI はオプションに応じてクイックリファレンスに含める公開APIを返します
+func filterCardAPI(api []TypeInfo, opts CardOptions) []TypeInfo {
+	api = append([]TypeInfo{}, api...)
+	if opts.HideDeprecated {
+		api = FilterDeprecated(api)
+	}
+	return api
+}
+
+// entries はクイックリファレンスに記載する宣言を返します
+func (c *PackageCard) entries() []TypeInfo {
+	var entries []TypeInfo
+	for _, group := range c.Constructors {
+		entries = append(entries, group.Constructors...)
+	}
+	entries = append(entries, c.OptionTypes...)
+	entries = append(entries, c.Options...)
+	entries = append(entries, c.Errors...)
+	return append(entries, c.Interfaces...)
+}
+
+// ClassifyAPI は公開APIの各要素にクイックリファレンスでの分類を設定します
+func ClassifyAPI(api []TypeInfo) {
+	// 関数オプションが返す型もオプションの型とみなす
+	optionTypes := map[string]bool{}
+	for _, info := range api {
+		if info.Kind == "func" && info.Receiver == "" && strings.HasPrefix(info.Name, "With") && len(info.Results) > 0 {
+			optionTypes[info.Results[0]] = true
+		}
+	}
+
+	for i := range api {
+		info := &api[i]
+		switch info.Kind {
+		case "func":
+			switch {
+			case isTypeDecl(*info):
+				// type Option func(*config) のような関数型
+				if optionTypes[info.Name] || hasOptionSuffix(info.Name) {
+					info.Category = TypeCategoryOptionType
+				}
+			case strings.HasPrefix(info.Name, "New") && len(info.Results) > 0:
+				info.Category = TypeCategoryConstructor
+			case strings.HasPrefix(info.Name, "With"):
+				info.Category = TypeCategoryOption
+			}
+		case "struct", "type":
+			switch {
+			case optionTypes[info.Name] || hasOptionSuffix(info.Name):
+				info.Category = TypeCategoryOptionType
+			case strings.HasSuffix(info.Name, "Error"):
+				info.Category = TypeCategoryError
+			}
+		case "interface":
+			switch {
+			case optionTypes[info.Name] || hasOptionSuffix(info.Name):
+				info.Category = TypeCategoryOptionType
+			case isImplementable(info.Methods):
+				info.Category = TypeCategoryInterface
+			}
+		case "var", "const":
+			if strings.HasPrefix(info.Name, "Err") {
+				info.Category = TypeCategoryError
+			}
+		}
+	}
+}
+
+// BuildPackageCard は公開APIを分類し、クイックリファレンスにまとめます
+func BuildPackageCard(api []TypeInfo) *PackageCard {
+	ClassifyAPI(api)
+
+	types := map[string]TypeInfo{}
+	for _, info := range api {
+		if isTypeDecl(info) {
+			types[info.Name] = info
+		}
+	}
+
+	card := &PackageCard{}
+	groups := map[string]*ConstructorGroup{}
+	var groupOrder []string
+	for _, info := range api {
+		switch info.Category {
+		case TypeCategoryConstructor:
+			typeName := constructedType(info.Results)
+			group, ok := groups[typeName]
+			if !ok {
+				group = &ConstructorGroup{Type: typeName}
+				if t, ok := types[typeName]; ok {
+					group.Comment = firstLine(withoutDeprecation(t.Comment))
+					group.Deprecated = t.Deprecated
+				}
+				groups[typeName] = group
+				groupOrder = append(groupOrder, typeName)
+			}
+			group.Constructors = append(group.Constructors, info)
+		case TypeCategoryOptionType:
+			card.OptionTypes = append(card.OptionTypes, info)
+		case TypeCategoryOption:
+			card.Options = append(card.Options, info)
+		case TypeCategoryError:
+			card.Errors = append(card.Errors, info)
+		case TypeCategoryInterface:
+			card.Interfaces = append(card.Interfaces, info)
+		}
+	}
+
+	sort.Strings(groupOrder)
+	for _, typeName := range groupOrder {
+		card.Constructors = append(card.Constructors, *groups[typeName])
+	}
+	return card
+}
+
+// isTypeDecl は型の宣言かどうかを判定します
+func isTypeDecl(info TypeInfo) bool {
+	switch info.Kind {
+	case "struct", "interface", "constraint", "type":
+		return true
+	case "func":
+		// 関数型の宣言はシグネチャが "type " で始まる
+		return strings.HasPrefix(info.Signature, "type ")
+	}
+	return false
+}
+
+// constructedType はコンストラクタの戻り値のうち、error 以外の最初の型名を返します
+func constructedType(results []string) string {
+	for _, result := range results {
+		if result != "error" && result != "" {
+			return result
+		}
+	}
+	return "error"
+}
+
+// hasOptionSuffix は型名がオプションを表す接尾辞で終わるかを判定します
+func hasOptionSuffix(name string) bool {
+	for _, suffix := range optionTypeSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isImplementable はパッケージ外から実装できるインターフェースかを判定します
+// 非公開のメソッドを含むインターフェースや、メソッドを持たないインターフェースは対象外です
+func isImplementable(methods []string) bool {
+	if len(methods) == 0 {
+		return false
+	}
+	for _, method := range methods {
+		name := method
+		if i := strings.IndexAny(method, "(["); i >= 0 {
+			name = method[:i]
+		}
+		// 埋め込まれた型（io.Reader など）は修飾子を除いた名前で判定する
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if !ast.IsExported(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatPackageCard はクイックリファレンスを Markdown に整形します
+func FormatPackageCard(card *PackageCard) string {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("# %s クイックリファレンス\n\n", card.Name))
+	output.WriteString(fmt.Sprintf("インポートパス: %s\n", card.ImportPath))
+	output.WriteString(fmt.Sprintf("バージョン: %s\n", card.Version))
+	if card.Synopsis != "" {
+		output.WriteString(fmt.Sprintf("概要: %s\n", card.Synopsis))
+	}
+	output.WriteString("\n")
+
+	if len(card.Constructors) > 0 {
+		output.WriteString("## コンストラクタ\n\n")
+		for _, group := range card.Constructors {
+			output.WriteString(fmt.Sprintf("### %s%s\n\n", group.Type, deprecatedMark(group.Deprecated)))
+			if group.Comment != "" {
+				output.WriteString(group.Comment + "\n\n")
+			}
+			for _, info := range group.Constructors {
+				output.WriteString(apiLine(info.Signature, info))
+			}
+			output.WriteString("\n")
+		}
+	}
+
+	if len(card.OptionTypes) > 0 || len(card.Options) > 0 {
+		output.WriteString("## オプション\n\n")
+		for _, info := range card.OptionTypes {
+			definition := info.Signature
+			if definition == "" {
+				definition = info.Definition
+			}
+			output.WriteString(apiLine(definition, info))
+		}
+		for _, info := range card.Options {
+			output.WriteString(apiLine(info.Signature, info))
+		}
+		output.WriteString("\n")
+	}
+
+	if len(card.Errors) > 0 {
+		output.WriteString("## エラー\n\n")
+		for _, info := range card.Errors {
+			output.WriteString(apiLine(info.Definition, info))
+		}
+		output.WriteString("\n")
+	}
+
+	if len(card.Interfaces) > 0 {
+		output.WriteString("## 実装するインターフェース\n\n")
+		for _, info := range card.Interfaces {
+			if info.Anchor != "" {
+				output.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", info.Anchor))
+			}
+			output.WriteString(fmt.Sprintf("### %s%s\n\n", info.Name, deprecatedMark(info.Deprecated)))
+			if summary := apiSummary(info); summary != "" {
+				output.WriteString(summary + "\n\n")
+			}
+			if info.Replacement != "" {
+				output.WriteString("非推奨: " + info.Replacement + "\n\n")
+			}
+			output.WriteString("```go\n")
+			output.WriteString(fmt.Sprintf("type %s interface {\n", info.Name))
+			for _, method := range info.Methods {
+				output.WriteString(fmt.Sprintf("\t%s\n", method))
+			}
+			output.WriteString("}\n```\n\n")
+		}
+	}
+
+	return output.String()
+}
+
+// apiLine は公開APIの1行を整形します（クイックリファレンスとモジュールツリーで使用します）
+// 非推奨の宣言には、ドキュメントコメントの "Deprecated:" の段落を末尾に付けます
+// 宣言にアンカーがある場合は、ドキュメントコメントのリンク先となるよう行頭に付けます
+// ジェネリックな宣言には、インスタンス化の例を示す Example 関数へのリンクを付けます
+func apiLine(definition string, info TypeInfo) string {
+	line := "- "
+	if info.Anchor != "" {
+		line += fmt.Sprintf("<a id=\"%s\"></a>", info.Anchor)
+	}
+	line += fmt.Sprintf("`%s`", definition)
+	if summary := apiSummary(info); summary != "" {
+		line += ": " + summary
+	}
+	if examples := exampleLinks(info.Examples); examples != "" {
+		line += fmt.Sprintf("（例: %s）", examples)
+	}
+	if info.Deprecated {
+		if info.Replacement != "" {
+			line += fmt.Sprintf("（非推奨: %s）", info.Replacement)
+		} else {
+			line += "（非推奨）"
+		}
+	}
+	return line + "\n"
+}
+
+// apiSummary は公開APIの要素のドキュメントコメントの最初の行を返します（リンクを解決した Summary があればそれを使用します）
+func apiSummary(info TypeInfo) string {
+	if info.Summary != "" {
+		return info.Summary
+	}
+	return firstLine(withoutDeprecation(info.Comment))
+}
+
+// exampleLinks は Example 関数へのリンクを "、" で連結して返します
+// リンクのテキストは明示的なインスタンス化（例: Set[int]）で、ない場合は Example 関数名です
+func exampleLinks(examples []ExampleRef) string {
+	var links []string
+	for _, example := range examples {
+		text := example.Instantiation
+		if text == "" {
+			text = example.Name
+		}
+		if example.URL == "" {
+			links = append(links, fmt.Sprintf("`%s`", text))
+			continue
+		}
+		links = append(links, fmt.Sprintf("[`%s`](%s)", text, example.URL))
+	}
+	return strings.Join(links, "、")
+}
+
+// deprecatedMark は非推奨の場合に見出しに付ける印を返します
+func deprecatedMark(deprecated bool) string {
+	if deprecated {
+		return "（非推奨）"
+	}
+	return ""
+}
+
+// FormatPackageCardJSON はクイックリファレンスを JSON に変換します
+func FormatPackageCardJSON(card *PackageCard) (string, error) {
+	data, err := json.MarshalIndent(card, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("クイックリファレンスのエンコードに失敗しました: %w", err)
+	}
+	return string(data), nil
+}
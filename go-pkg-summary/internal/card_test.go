@@ -0,0 +1,277 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cardModuleFiles はクイックリファレンスのテスト用のモジュールのファイルです
+var cardModuleFiles = map[string]string{
+	"go.mod": "module example.com/kv\n\ngo 1.22\n",
+	"kv.go": `// Package kv はキーバリューストアのクライアントを提供します。
+package kv
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	// ErrNotFound はキーが存在しないことを表します
+	ErrNotFound = errors.New("not found")
+	// ErrClosed はクライアントが閉じられていることを表します
+	ErrClosed = errors.New("closed")
+)
+
+// DefaultTimeout は既定のタイムアウトです
+var DefaultTimeout = 10
+
+// Client はストアのクライアントです
+type Client struct{}
+
+// New はクライアントを作成します
+// 接続は最初のリクエストで確立します
+func New(addr string, opts ...Option) (*Client, error) { return &Client{}, nil }
+
+// NewFromEnv は環境変数からクライアントを作成します
+func NewFromEnv() (*Client, error) { return &Client{}, nil }
+
+// Get は値を取得します
+func (c *Client) Get(key string) ([]byte, error) { return nil, nil }
+
+// NewCodec はコーデックを作成します
+func NewCodec() Codec { return nil }
+
+// Option はクライアントの設定を変更します
+type Option func(*settings)
+
+type settings struct{}
+
+// WithTimeout はタイムアウトを設定します
+func WithTimeout(seconds int) Option { return nil }
+
+// Codec は値のエンコード方式です
+type Codec interface {
+	io.Closer
+	Encode(v any) ([]byte, error)
+	Decode(data []byte, v any) error
+}
+
+// Store はパッケージ内でのみ実装されます
+type Store interface {
+	Get(key string) ([]byte, error)
+	sealed()
+}
+
+// KeyError はキーに関するエラーです
+type KeyError struct{ Key string }
+`,
+}
+
+// newCardFetcher はクイックリファレンスのテスト用のモジュールを返すモジュールプロキシを使う Fetcher を作成します
+func newCardFetcher(t *testing.T) *Fetcher {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	data := newTestModuleZip(t, "example.com/kv", "v1.0.0", cardModuleFiles)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/kv/@v/list":
+			_, _ = w.Write([]byte("v1.0.0\n"))
+		case "/example.com/kv/@v/v1.0.0.zip":
+			_, _ = w.Write(data)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(proxy.Close)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.proxyURL = proxy.URL
+	return f
+}
+
+// cardNames は要素の名前の一覧を返します
+func cardNames(infos []TypeInfo) []string {
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+func TestGetPackageCard(t *testing.T) {
+	f := newCardFetcher(t)
+
+	card, err := f.GetPackageCard("example.com/kv", "latest", CardOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "kv", card.Name)
+	assert.Equal(t, "v1.0.0", card.Version)
+	assert.Equal(t, "Package kv はキーバリューストアのクライアントを提供します。", card.Synopsis)
+
+	require.Len(t, card.Constructors, 2)
+	assert.Equal(t, "Client", card.Constructors[0].Type)
+	assert.Equal(t, "Client はストアのクライアントです", card.Constructors[0].Comment)
+	assert.Equal(t, []string{"New", "NewFromEnv"}, cardNames(card.Constructors[0].Constructors), "同じ型を返すコンストラクタがまとめられること")
+	assert.Equal(t, "func New(addr string, opts ...Option) (*Client, error)", card.Constructors[0].Constructors[0].Signature)
+	assert.Equal(t, "Codec", card.Constructors[1].Type)
+
+	assert.Equal(t, []string{"Option"}, cardNames(card.OptionTypes))
+	assert.Equal(t, "type Option func(*settings)", card.OptionTypes[0].Signature)
+	assert.Equal(t, []string{"WithTimeout"}, cardNames(card.Options))
+	assert.Equal(t, []string{"ErrNotFound", "ErrClosed", "KeyError"}, cardNames(card.Errors), "グループ化された変数はそれぞれのコメントを持つこと")
+	assert.Equal(t, "ErrClosed はクライアントが閉じられていることを表します\n", card.Errors[1].Comment)
+
+	require.Equal(t, []string{"Codec"}, cardNames(card.Interfaces), "非公開メソッドを持つインターフェースは含まれないこと")
+	assert.Equal(t, []string{"io.Closer", "Encode(v any) ([]byte, error)", "Decode(data []byte, v any) error"}, card.Interfaces[0].Methods)
+
+	_, err = f.GetPackageCard("example.com/kv/missing", "v1.0.0", CardOptions{})
+	assert.Error(t, err)
+}
+
+func TestClassifyAPI(t *testing.T) {
+	api := []TypeInfo{
+		{Name: "Config", Kind: "struct"},
+		{Name: "Setting", Kind: "interface", Methods: []string{"Apply(*Config)"}},
+		{Name: "WithName", Kind: "func", Signature: "func WithName(name string) Setting", Results: []string{"Setting"}},
+		{Name: "NewServer", Kind: "func", Signature: "func NewServer() *Server", Results: []string{"Server"}},
+		{Name: "Newline", Kind: "const"},
+		{Name: "NewConn", Kind: "method", Receiver: "Server", Signature: "func (s *Server) NewConn() *Conn", Results: []string{"Conn"}},
+		{Name: "Empty", Kind: "interface"},
+	}
+	ClassifyAPI(api)
+
+	categories := map[string]string{}
+	for _, info := range api {
+		categories[info.Name] = info.Category
+	}
+	assert.Equal(t, map[string]string{
+		"Config":    TypeCategoryOptionType,
+		"Setting":   TypeCategoryOptionType,
+		"WithName":  TypeCategoryOption,
+		"NewServer": TypeCategoryConstructor,
+		"Newline":   "",
+		"NewConn":   "",
+		"Empty":     "",
+	}, categories)
+}
+
+func TestFormatPackageCard(t *testing.T) {
+	f := newCardFetcher(t)
+
+	card, err := f.GetPackageCard("example.com/kv", "v1.0.0", CardOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "# kv クイックリファレンス\n"+`
+インポートパス: example.com/kv
+バージョン: v1.0.0
+概要: Package kv はキーバリューストアのクライアントを提供します。
+
+## コンストラクタ
+
+### Client
+
+Client はストアのクライアントです
+
+- <a id="api-example-com-kv-New"></a>`+"`func New(addr string, opts ...Option) (*Client, error)`"+`: New はクライアントを作成します
+- <a id="api-example-com-kv-NewFromEnv"></a>`+"`func NewFromEnv() (*Client, error)`"+`: NewFromEnv は環境変数からクライアントを作成します
+
+### Codec
+
+Codec は値のエンコード方式です
+
+- <a id="api-example-com-kv-NewCodec"></a>`+"`func NewCodec() Codec`"+`: NewCodec はコーデックを作成します
+
+## オプション
+
+- <a id="api-example-com-kv-Option"></a>`+"`type Option func(*settings)`"+`: Option はクライアントの設定を変更します
+- <a id="api-example-com-kv-WithTimeout"></a>`+"`func WithTimeout(seconds int) Option`"+`: WithTimeout はタイムアウトを設定します
+
+## エラー
+
+- <a id="api-example-com-kv-ErrNotFound"></a>`+"`var ErrNotFound`"+`: ErrNotFound はキーが存在しないことを表します
+- <a id="api-example-com-kv-ErrClosed"></a>`+"`var ErrClosed`"+`: ErrClosed はクライアントが閉じられていることを表します
+- <a id="api-example-com-kv-KeyError"></a>`+"`type KeyError struct`"+`: KeyError はキーに関するエラーです
+
+## 実装するインターフェース
+
+<a id="api-example-com-kv-Codec"></a>
+
+### Codec
+
+Codec は値のエンコード方式です
+
+`+"```go"+`
+type Codec interface {
+	io.Closer
+	Encode(v any) ([]byte, error)
+	Decode(data []byte, v any) error
+}
+`+"```"+`
+
+`, FormatPackageCard(card))
+
+	content, err := FormatPackageCardJSON(card)
+	require.NoError(t, err)
+
+	var decoded PackageCard
+	require.NoError(t, json.Unmarshal([]byte(content), &decoded))
+	assert.Equal(t, *card, decoded)
+	assert.Contains(t, content, `"category": "functional-option"`)
+}
+
+func TestFormatPackageCardDeprecated(t *testing.T) {
+	api, err := NewParser(false).ParseFile("conn.go", `package conn
+
+// Conn は接続です
+//
+// Deprecated: Session を使用してください。
+type Conn struct{}
+
+// NewConn は接続を作成します
+func NewConn() *Conn { return nil }
+
+// Dial は接続します
+//
+// Deprecated: DialContext を使用してください。
+func Dial() (*Conn, error) { return nil, nil }
+
+// ErrTimeout はタイムアウトを表します
+//
+// Deprecated:
+var ErrTimeout error
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, `#  クイックリファレンス
+
+インポートパス: 
+バージョン: 
+
+## コンストラクタ
+
+### Conn（非推奨）
+
+Conn は接続です
+
+- `+"`func NewConn() *Conn`"+`: NewConn は接続を作成します
+
+## エラー
+
+- `+"`var ErrTimeout`"+`: ErrTimeout はタイムアウトを表します（非推奨）
+
+`, FormatPackageCard(BuildPackageCard(api)))
+
+	card := BuildPackageCard(FilterDeprecated(api))
+	assert.Len(t, card.Constructors, 1)
+	assert.False(t, card.Constructors[0].Deprecated, "パッケージ外の型とみなされること")
+	assert.Empty(t, card.Errors)
+	assert.Equal(t, "- `func Dial() (*Conn, error)`: Dial は接続します（非推奨: DialContext を使用してください。）\n", apiLine(api[2].Signature, api[2]))
+}
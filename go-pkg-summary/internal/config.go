@@ -0,0 +1,217 @@
+// Package config はプロジェクトとユーザーの設定ファイル（.gopkgsummary.yaml）を読み込む機能を提供します
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName は設定ファイル名です
+const ConfigFileName = ".gopkgsummary.yaml"
+
+// configHosts は hosts に指定できるホストと、接続先を差し替える BaseURLs のフィールドです
+var configHosts = map[string]func(urls *BaseURLs) *string{
+	"pkg.go.dev":          func(urls *BaseURLs) *string { return &urls.PkgGoDev },
+	"proxy.golang.org":    func(urls *BaseURLs) *string { return &urls.Proxy },
+	"github.com":          func(urls *BaseURLs) *string { return &urls.GitHubAPI },
+	"gitlab.com":          func(urls *BaseURLs) *string { return &urls.GitLabAPI },
+	"go.googlesource.com": func(urls *BaseURLs) *string { return &urls.StdlibSource },
+}
+
+// HostConfig はホストごとの接続先と認証の設定です
+type HostConfig struct {
+	// API の URL の基点（GitHub Enterprise やモジュールプロキシのミラーなど）
+	APIURL string `yaml:"api-url,omitempty"`
+	// トークンを読み込む環境変数の名前（トークン自体は設定ファイルに書かない）
+	TokenEnv string `yaml:"token-env,omitempty"`
+}
+
+// Config は設定ファイルの内容です
+type Config struct {
+	// フラグ名ごとの既定値（コマンドラインで指定したフラグが優先されます）
+	Defaults map[string]any `yaml:"defaults,omitempty"`
+	// ホストごとの接続先と認証
+	Hosts map[string]HostConfig `yaml:"hosts,omitempty"`
+	// 短いパッケージ名からインポートパスへの対応
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+	// サマリーのファイル一覧に含めるファイルと除くファイルのパターン（--include と --exclude の既定値）
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	// 読み込んだ設定ファイル（優先度の低い順）
+	Sources []string `yaml:"-"`
+}
+
+// ConfigFiles は dir から読み込む設定ファイルを優先度の低い順に返します
+// ユーザーの設定（$XDG_CONFIG_HOME/.gopkgsummary.yaml）の後に、dir とその親ディレクトリのうち最も近いものを返します
+func ConfigFiles(dir string) []string {
+	var files []string
+	if configDir, err := os.UserConfigDir(); err == nil {
+		if path := filepath.Join(configDir, ConfigFileName); fileExists(path) {
+			files = append(files, path)
+		}
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return files
+	}
+	for {
+		if path := filepath.Join(dir, ConfigFileName); fileExists(path) {
+			if len(files) == 0 || files[0] != path {
+				files = append(files, path)
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return files
+}
+
+// LoadConfig は dir から見つかる設定ファイルを読み込み、プロジェクトの設定がユーザーの設定より優先されるようにまとめます
+// 設定ファイルがない場合は空の設定を返します
+func LoadConfig(dir string) (*Config, error) {
+	config := &Config{}
+	for _, path := range ConfigFiles(dir) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("設定ファイルの読み込みに失敗しました: %w", err)
+		}
+		loaded, err := ParseConfig(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		config.merge(loaded)
+		config.Sources = append(config.Sources, path)
+	}
+	return config, nil
+}
+
+// ParseConfig は設定ファイルの内容を解析します
+// 未知のキーや、hosts に未対応のホストがある場合はエラーを返します
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("設定ファイルの解析に失敗しました: %w", err)
+	}
+
+	for host, hostConfig := range config.Hosts {
+		if _, ok := configHosts[host]; !ok {
+			return nil, fmt.Errorf("hosts に未対応のホストが指定されています: %s（%s のいずれかを指定してください）", host, configHostNames())
+		}
+		if hostConfig.APIURL != "" {
+			if u, err := url.Parse(hostConfig.APIURL); err != nil || u.Host == "" {
+				return nil, fmt.Errorf("%s の api-url が正しくありません: %s", host, hostConfig.APIURL)
+			}
+		}
+	}
+	return config, nil
+}
+
+// merge は other の設定で上書きします（マップはキーごと、スライスは全体を上書きします）
+func (c *Config) merge(other *Config) {
+	if len(other.Defaults) > 0 && c.Defaults == nil {
+		c.Defaults = map[string]any{}
+	}
+	for name, value := range other.Defaults {
+		c.Defaults[name] = value
+	}
+	if len(other.Hosts) > 0 && c.Hosts == nil {
+		c.Hosts = map[string]HostConfig{}
+	}
+	for host, hostConfig := range other.Hosts {
+		c.Hosts[host] = hostConfig
+	}
+	if len(other.Aliases) > 0 && c.Aliases == nil {
+		c.Aliases = map[string]string{}
+	}
+	for name, importPath := range other.Aliases {
+		c.Aliases[name] = importPath
+	}
+	if other.Include != nil {
+		c.Include = other.Include
+	}
+	if other.Exclude != nil {
+		c.Exclude = other.Exclude
+	}
+}
+
+// FetcherOptions は hosts の設定を NewFetcher の関数オプションに変換します
+// トークンは token-env で指定した環境変数から読み込み、API の URL のホストへのリクエストにのみ付与します
+func (c *Config) FetcherOptions() []Option {
+	var urls BaseURLs
+	tokens := map[string]string{}
+	defaults := newOptions(nil).baseURLs
+
+	for host, hostConfig := range c.Hosts {
+		field := configHosts[host]
+		if hostConfig.APIURL != "" {
+			*field(&urls) = hostConfig.APIURL
+		}
+		if hostConfig.TokenEnv == "" {
+			continue
+		}
+		token := os.Getenv(hostConfig.TokenEnv)
+		if token == "" {
+			continue
+		}
+		apiURL := *field(&urls)
+		if apiURL == "" {
+			apiURL = *field(&defaults)
+		}
+		if u, err := url.Parse(apiURL); err == nil {
+			tokens[u.Host] = token
+		}
+	}
+
+	opts := []Option{WithBaseURLs(urls)}
+	if len(tokens) > 0 {
+		opts = append(opts, WithTokens(tokens))
+	}
+	return opts
+}
+
+// configHostNames は hosts に指定できるホストの一覧を返します
+func configHostNames() string {
+	var names []string
+	for host := range configHosts {
+		names = append(names, host)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// fileExists は通常のファイルが存在するかを判定します
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// EffectiveHosts は対応する全てのホストについて、既定値を補った接続先と認証の設定を返します
+func (c *Config) EffectiveHosts() map[string]HostConfig {
+	defaults := newOptions(nil).baseURLs
+	hosts := make(map[string]HostConfig, len(configHosts))
+	for host, field := range configHosts {
+		hostConfig := c.Hosts[host]
+		if hostConfig.APIURL == "" {
+			hostConfig.APIURL = *field(&defaults)
+		}
+		hosts[host] = hostConfig
+	}
+	return hosts
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+	xdg := filepath.Join(root, "xdg")
+	project := filepath.Join(root, "project")
+	work := filepath.Join(project, "cmd", "tool")
+	require.NoError(t, os.MkdirAll(xdg, 0755))
+	require.NoError(t, os.MkdirAll(work, 0755))
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	require.NoError(t, os.WriteFile(filepath.Join(xdg, ConfigFileName), []byte(`defaults:
+  no-cache: true
+  search-mode: symbol
+aliases:
+  zap: go.uber.org/zap
+  cobra: github.com/spf13/cobra
+include: ["*.go"]
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ConfigFileName), []byte(`defaults:
+  search-mode: package
+  readme-sections: [Installation, Usage]
+aliases:
+  zap: example.com/fork/zap
+exclude: ["*_test.go"]
+hosts:
+  github.com:
+    api-url: https://github.example.com/api/v3
+    token-env: TEST_GITHUB_TOKEN
+`), 0644))
+
+	config, err := LoadConfig(work)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(xdg, ConfigFileName), filepath.Join(project, ConfigFileName)}, config.Sources)
+	assert.Equal(t, map[string]any{
+		"no-cache":        true,
+		"search-mode":     "package",
+		"readme-sections": []any{"Installation", "Usage"},
+	}, config.Defaults, "プロジェクトの設定がユーザーの設定より優先されること")
+	assert.Equal(t, map[string]string{"zap": "example.com/fork/zap", "cobra": "github.com/spf13/cobra"}, config.Aliases)
+	assert.Equal(t, []string{"*.go"}, config.Include)
+	assert.Equal(t, []string{"*_test.go"}, config.Exclude)
+	assert.Equal(t, HostConfig{APIURL: "https://github.example.com/api/v3", TokenEnv: "TEST_GITHUB_TOKEN"}, config.Hosts["github.com"])
+
+	hosts := config.EffectiveHosts()
+	assert.Equal(t, DefaultProxyURL, hosts["proxy.golang.org"].APIURL, "指定していないホストは既定の URL になること")
+
+	// 設定ファイルがない場合は空の設定を返す
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	config, err = LoadConfig(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, config.Sources)
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"未知のキー":       "defualts:\n  no-cache: true\n",
+		"未対応のホスト":     "hosts:\n  bitbucket.org:\n    token-env: TOKEN\n",
+		"不正な api-url": "hosts:\n  github.com:\n    api-url: not a url\n",
+	} {
+		_, err := ParseConfig([]byte(content))
+		assert.Error(t, err, name)
+	}
+
+	config, err := ParseConfig(nil)
+	require.NoError(t, err, "空のファイルはエラーにしないこと")
+	assert.Empty(t, config.Defaults)
+}
+
+func TestConfigFetcherOptions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+
+	authorizations := map[string]string{}
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations["github"] = r.Header.Get("Authorization")
+		w.Write([]byte(`[{"name":"go.mod","path":"go.mod","type":"file"}]`))
+	}))
+	defer github.Close()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations["proxy"] = r.Header.Get("Authorization")
+		w.Write([]byte("v1.0.0\n"))
+	}))
+	defer proxy.Close()
+
+	config, err := ParseConfig([]byte(`hosts:
+  github.com:
+    api-url: ` + github.URL + `
+    token-env: TEST_GITHUB_TOKEN
+  proxy.golang.org:
+    api-url: ` + proxy.URL + `
+`))
+	require.NoError(t, err)
+
+	f, err := NewFetcher(false, config.FetcherOptions()...)
+	require.NoError(t, err)
+
+	files, err := f.listGitHubFiles("https://github.com/example/repo", "latest")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go.mod"}, files)
+	_, err = f.proxyVersionList("example.com/mod")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer secret", authorizations["github"])
+	assert.Empty(t, authorizations["proxy"], "トークンは指定したホストにのみ付与すること")
+}
+
+func TestFilterFiles(t *testing.T) {
+	files := []string{"README.md", "go.mod", "zap.go", "zap_test.go", "internal/bufferpool/pool.go", "docs/faq.md"}
+
+	assert.Equal(t, files, FilterFiles(files, nil, nil))
+	assert.Equal(t, []string{"zap.go", "internal/bufferpool/pool.go"}, FilterFiles(files, []string{"*.go"}, []string{"*_test.go"}))
+	assert.Equal(t, []string{"README.md", "go.mod", "zap.go", "zap_test.go"}, FilterFiles(files, nil, []string{"internal/*/*", "docs/*"}))
+}
@@ -0,0 +1,343 @@
+// Package depgraph はパッケージのインポートとモジュールの依存関係からなる依存グラフを生成する機能を提供します
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// NodeKindTarget は依存グラフの対象のパッケージを表します
+	NodeKindTarget = "target"
+	// NodeKindStdlib は標準ライブラリのパッケージを表します
+	NodeKindStdlib = "stdlib"
+	// NodeKindSameModule は対象と同じモジュールのパッケージを表します
+	NodeKindSameModule = "same-module"
+	// NodeKindThirdParty はサードパーティのパッケージを表します
+	NodeKindThirdParty = "third-party"
+	// NodeKindModule はモジュールを表します
+	NodeKindModule = "module"
+
+	// EdgeKindImports はパッケージのインポートを表します
+	EdgeKindImports = "imports"
+	// EdgeKindProvidedBy はパッケージとそれを提供するモジュールの関係を表します
+	EdgeKindProvidedBy = "provided-by"
+	// EdgeKindRequires は go.mod の require による依存を表します
+	EdgeKindRequires = "requires"
+
+	// stdlibLicense は標準ライブラリのライセンスです
+	stdlibLicense = "BSD-3-Clause"
+)
+
+// mermaidNodeStyles はノードの種類ごとの Mermaid のスタイルです
+var mermaidNodeStyles = map[string]string{
+	NodeKindTarget:     "fill:#cde8ff,stroke:#1f6feb",
+	NodeKindStdlib:     "fill:#eeeeee,stroke:#999999",
+	NodeKindSameModule: "fill:#e6f4ea,stroke:#2da44e",
+	NodeKindThirdParty: "fill:#fff4e5,stroke:#d4a72c",
+	NodeKindModule:     "fill:#fbefff,stroke:#8250df",
+}
+
+// dotNodeStyles はノードの種類ごとの DOT のスタイルです
+var dotNodeStyles = map[string]string{
+	NodeKindTarget:     `style=filled, fillcolor="#cde8ff"`,
+	NodeKindStdlib:     `color=gray, fontcolor=gray`,
+	NodeKindSameModule: `style=filled, fillcolor="#e6f4ea"`,
+	NodeKindThirdParty: `style=filled, fillcolor="#fff4e5"`,
+	NodeKindModule:     `shape=component, style=filled, fillcolor="#fbefff"`,
+}
+
+// DependencyGraphOptions は依存グラフの生成オプションを表す構造体です
+type DependencyGraphOptions struct {
+	// go.mod の require をたどる深さ（0 の場合は無制限）
+	Depth int
+	// モジュールのライセンスを取得するかどうか
+	Licenses bool
+	// キャッシュしたモジュールの zip を使用するかどうか
+	UseCache bool
+}
+
+// moduleRequirement はモジュールの依存関係の探索で使用するモジュールとバージョンの組です
+type moduleRequirement struct {
+	path    string
+	version string
+}
+
+// GetDependencyGraph はパッケージのインポートと、go.mod の require を推移的にたどった依存グラフを生成します
+// テスト以外のファイルのインポートを対象とし、モジュールのバージョンは最小バージョン選択と同様に最大のものを採用します
+func (f *Fetcher) GetDependencyGraph(importPath string, version string, opts DependencyGraphOptions) (*Graph, error) {
+	modulePath, resolvedVersion, err := f.ResolveModuleVersion(importPath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := f.DownloadModule(modulePath, resolvedVersion, opts.UseCache)
+	if err != nil {
+		return nil, err
+	}
+
+	// パッケージのインポートを取得
+	dir := strings.TrimPrefix(strings.TrimPrefix(importPath, modulePath), "/")
+	ctxt := moduleBuildContext(source.FS)
+	bp, err := ctxt.ImportDir("/"+dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("パッケージ %s の読み込みに失敗しました: %w", importPath, err)
+	}
+
+	// 対象モジュールの go.mod を取得
+	goMod, err := source.ReadFile("go.mod")
+	if err != nil {
+		goMod = "module " + modulePath + "\n"
+	}
+	mainRequires, err := parseRequirements([]byte(goMod))
+	if err != nil {
+		return nil, err
+	}
+
+	// go.mod の require を推移的にたどり、モジュールごとのバージョンを決定する
+	selected, requires := f.walkRequirements(moduleRequirement{path: modulePath, version: resolvedVersion}, mainRequires, opts.Depth)
+
+	g := &Graph{}
+	g.Nodes = append(g.Nodes, GraphNode{
+		ID:      importPath,
+		Label:   importPath,
+		Kind:    NodeKindTarget,
+		Module:  modulePath,
+		Version: resolvedVersion,
+	})
+
+	// パッケージのインポート
+	for _, imp := range bp.Imports {
+		if imp == "C" {
+			continue
+		}
+
+		node := GraphNode{ID: imp, Label: imp}
+		switch {
+		case isStdlibImportPath(imp):
+			node.Kind = NodeKindStdlib
+			node.License = stdlibLicense
+		case imp == modulePath || strings.HasPrefix(imp, modulePath+"/"):
+			node.Kind = NodeKindSameModule
+			node.Module = modulePath
+			node.Version = resolvedVersion
+		default:
+			node.Kind = NodeKindThirdParty
+			node.Module = providingModule(imp, selected)
+			node.Version = selected[node.Module]
+		}
+
+		g.Nodes = append(g.Nodes, node)
+		g.Edges = append(g.Edges, GraphEdge{From: importPath, To: imp, Kind: EdgeKindImports})
+		if node.Kind == NodeKindThirdParty && node.Module != "" {
+			g.Edges = append(g.Edges, GraphEdge{From: imp, To: moduleNodeID(node.Module, node.Version), Kind: EdgeKindProvidedBy})
+		}
+	}
+
+	// モジュールの依存関係
+	modulePaths := make([]string, 0, len(selected))
+	for p := range selected {
+		modulePaths = append(modulePaths, p)
+	}
+	sort.Slice(modulePaths, func(i, j int) bool {
+		// 対象のモジュールを先頭にする
+		if (modulePaths[i] == modulePath) != (modulePaths[j] == modulePath) {
+			return modulePaths[i] == modulePath
+		}
+		return modulePaths[i] < modulePaths[j]
+	})
+
+	licenses := map[string]string{}
+	if opts.Licenses {
+		licenses = f.moduleLicenses(modulePaths, selected, opts.UseCache)
+	}
+
+	for _, p := range modulePaths {
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID:      moduleNodeID(p, selected[p]),
+			Label:   p,
+			Kind:    NodeKindModule,
+			Module:  p,
+			Version: selected[p],
+			License: licenses[p],
+		})
+	}
+	for _, p := range modulePaths {
+		for _, req := range requires[p] {
+			g.Edges = append(g.Edges, GraphEdge{
+				From: moduleNodeID(p, selected[p]),
+				To:   moduleNodeID(req, selected[req]),
+				Kind: EdgeKindRequires,
+			})
+		}
+	}
+
+	// インポートしているパッケージにもモジュールのライセンスを反映
+	for i := range g.Nodes {
+		if g.Nodes[i].Kind == NodeKindThirdParty || g.Nodes[i].Kind == NodeKindSameModule || g.Nodes[i].Kind == NodeKindTarget {
+			g.Nodes[i].License = licenses[g.Nodes[i].Module]
+		}
+	}
+
+	return g, nil
+}
+
+// walkRequirements は go.mod の require を幅優先でたどり、モジュールごとに選択されたバージョンと依存先を返します
+// 依存先は、モジュールごとに選択されたバージョンの go.mod の require のみから求めます
+func (f *Fetcher) walkRequirements(root moduleRequirement, rootRequires []moduleRequirement, depth int) (map[string]string, map[string][]string) {
+	selected := map[string]string{root.path: root.version}
+	visitedRequires := map[moduleRequirement][]moduleRequirement{}
+	visited := map[moduleRequirement]bool{root: true}
+
+	// バージョンごとの require を記録し、未訪問のものを次の探索対象として返す
+	record := func(from moduleRequirement, reqs []moduleRequirement) []moduleRequirement {
+		visitedRequires[from] = reqs
+		var next []moduleRequirement
+		for _, req := range reqs {
+			// 対象のモジュールのバージョンは変更しない
+			if req.path == root.path {
+				continue
+			}
+			if current, ok := selected[req.path]; !ok || semver.Compare(req.version, current) > 0 {
+				selected[req.path] = req.version
+			}
+			if !visited[req] {
+				visited[req] = true
+				next = append(next, req)
+			}
+		}
+		return next
+	}
+
+	level := record(root, rootRequires)
+	for d := 1; len(level) > 0 && (depth <= 0 || d < depth); d++ {
+		// 同じ深さのモジュールの go.mod を並行して取得
+		results := make([][]moduleRequirement, len(level))
+		sem := make(chan struct{}, versionInfoConcurrency)
+		var wg sync.WaitGroup
+		for i, req := range level {
+			wg.Add(1)
+			go func(i int, req moduleRequirement) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				data, err := f.proxyGoMod(req.path, req.version)
+				if err != nil {
+					if f.debug {
+						fmt.Printf("%s@%s の go.mod の取得に失敗しました: %v\n", req.path, req.version, err)
+					}
+					return
+				}
+				reqs, err := parseRequirements(data)
+				if err != nil {
+					if f.debug {
+						fmt.Printf("%s@%s の go.mod の解析に失敗しました: %v\n", req.path, req.version, err)
+					}
+					return
+				}
+				results[i] = reqs
+			}(i, req)
+		}
+		wg.Wait()
+
+		var next []moduleRequirement
+		for i, req := range level {
+			next = append(next, record(req, results[i])...)
+		}
+		level = next
+	}
+
+	// 選択されたバージョンの require から依存先を求める（選択されなかったバージョンの require は含めない）
+	requires := map[string][]string{}
+	for path, version := range selected {
+		seen := map[string]bool{}
+		for _, req := range visitedRequires[moduleRequirement{path: path, version: version}] {
+			if req.path == path || seen[req.path] {
+				continue
+			}
+			seen[req.path] = true
+			requires[path] = append(requires[path], req.path)
+		}
+		sort.Strings(requires[path])
+	}
+	return selected, requires
+}
+
+// moduleLicenses はモジュールの zip を並行して取得し、ライセンスファイルからライセンスを検出します
+// useCache が false の場合はキャッシュしたモジュールの zip を使用せずに取得し直します
+func (f *Fetcher) moduleLicenses(modulePaths []string, selected map[string]string, useCache bool) map[string]string {
+	licenses := make(map[string]string, len(modulePaths))
+	var mu sync.Mutex
+	sem := make(chan struct{}, versionInfoConcurrency)
+	var wg sync.WaitGroup
+
+	for _, p := range modulePaths {
+		wg.Add(1)
+		go func(p string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			source, err := f.DownloadModule(p, selected[p], useCache)
+			var detected *ModuleLicenses
+			if err == nil {
+				detected, err = DetectLicenses(source)
+			}
+			if err != nil {
+				if f.debug {
+					fmt.Printf("%s のライセンスの検出に失敗しました: %v\n", p, err)
+				}
+				return
+			}
+			mu.Lock()
+			licenses[p] = strings.Join(detected.IDs(), ", ")
+			mu.Unlock()
+		}(p)
+	}
+	wg.Wait()
+
+	return licenses
+}
+
+// parseRequirements は go.mod の require ディレクティブを解析します
+func parseRequirements(data []byte) ([]moduleRequirement, error) {
+	mf, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("go.mod のパースに失敗しました: %w", err)
+	}
+
+	reqs := make([]moduleRequirement, 0, len(mf.Require))
+	for _, r := range mf.Require {
+		reqs = append(reqs, moduleRequirement{path: r.Mod.Path, version: r.Mod.Version})
+	}
+	return reqs, nil
+}
+
+// providingModule は依存モジュールの中からインポートパスを提供するモジュール（最長一致）を返します
+func providingModule(importPath string, selected map[string]string) string {
+	best := ""
+	for p := range selected {
+		if (importPath == p || strings.HasPrefix(importPath, p+"/")) && len(p) > len(best) {
+			best = p
+		}
+	}
+	return best
+}
+
+// moduleNodeID はモジュールのノードIDを返します（パッケージのノードと区別するためバージョンを含めます）
+func moduleNodeID(modulePath string, version string) string {
+	return modulePath + "@" + version
+}
+
+// isStdlibImportPath はインポートパスが標準ライブラリのものかを判定します
+// 最初の要素にドットを含まないパスを標準ライブラリとみなします
+func isStdlibImportPath(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDependencyGraphFetcher は依存関係のあるモジュールを返すプロキシに接続したFetcherを作成します
+func newDependencyGraphFetcher(t *testing.T) *Fetcher {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	moduleZip := newTestModuleZip(t, "example.com/app", "v1.0.0", map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n\nrequire (\n\texample.com/dep v1.0.0\n\texample.com/other v0.1.0 // indirect\n)\n",
+		"app.go": `package app
+
+import (
+	"fmt"
+
+	"example.com/app/util"
+	"example.com/dep/sub"
+)
+
+func Run() { fmt.Println(util.X, sub.Y) }
+`,
+		"app_test.go":  "package app\n\nimport \"example.com/testonly\"\n",
+		"util/util.go": "package util\n\nconst X = 1\n",
+	})
+	depZip := newTestModuleZip(t, "example.com/dep", "v1.0.0", map[string]string{
+		"go.mod":     "module example.com/dep\n\nrequire example.com/deep v0.2.0\n",
+		"LICENSE":    readLicenseFixture(t, "MIT.txt"),
+		"sub/sub.go": "package sub\n\nconst Y = 1\n",
+	})
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/app/@v/list":
+			_, _ = w.Write([]byte("v0.9.0\nv1.0.0\n"))
+		case "/example.com/app/@v/v1.0.0.zip":
+			_, _ = w.Write(moduleZip)
+		case "/example.com/dep/@v/v1.0.0.zip":
+			_, _ = w.Write(depZip)
+		case "/example.com/dep/@v/v1.0.0.mod":
+			_, _ = w.Write([]byte("module example.com/dep\n\nrequire example.com/deep v0.2.0\n"))
+		case "/example.com/deep/@v/v0.2.0.mod":
+			// 推移的な依存から、より新しいバージョンの example.com/other が要求される
+			_, _ = w.Write([]byte("module example.com/deep\n\nrequire example.com/other v0.3.0\n"))
+		case "/example.com/other/@v/v0.1.0.mod":
+			// 選択されない古いバージョンだけが example.com/legacy を要求する
+			_, _ = w.Write([]byte("module example.com/other\n\nrequire example.com/legacy v1.0.0\n"))
+		case "/example.com/other/@v/v0.3.0.mod":
+			_, _ = w.Write([]byte("module example.com/other\n"))
+		case "/example.com/legacy/@v/v1.0.0.mod":
+			_, _ = w.Write([]byte("module example.com/legacy\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(proxy.Close)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.proxyURL = proxy.URL
+	return f
+}
+
+// findNode はIDでノードを探します
+func findNode(t *testing.T, g *Graph, id string) GraphNode {
+	t.Helper()
+	for _, node := range g.Nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+	require.Failf(t, "ノードが見つかりません", "%s", id)
+	return GraphNode{}
+}
+
+func TestGetDependencyGraph(t *testing.T) {
+	f := newDependencyGraphFetcher(t)
+
+	g, err := f.GetDependencyGraph("example.com/app", "latest", DependencyGraphOptions{Licenses: true})
+	require.NoError(t, err)
+
+	target := findNode(t, g, "example.com/app")
+	assert.Equal(t, NodeKindTarget, target.Kind)
+	assert.Equal(t, "v1.0.0", target.Version, "latest は最新のバージョンに解決されること")
+
+	assert.Equal(t, GraphNode{ID: "fmt", Label: "fmt", Kind: NodeKindStdlib, License: "BSD-3-Clause"}, findNode(t, g, "fmt"))
+	assert.Equal(t, NodeKindSameModule, findNode(t, g, "example.com/app/util").Kind)
+
+	sub := findNode(t, g, "example.com/dep/sub")
+	assert.Equal(t, NodeKindThirdParty, sub.Kind)
+	assert.Equal(t, "example.com/dep", sub.Module, "インポートパスを提供するモジュールが特定されること")
+	assert.Equal(t, "v1.0.0", sub.Version)
+	assert.Equal(t, "MIT", sub.License, "モジュールのライセンスファイルから検出したライセンスが反映されること")
+	assert.Equal(t, "MIT", findNode(t, g, "example.com/dep@v1.0.0").License)
+	assert.Empty(t, target.License, "ライセンスファイルのないモジュールはライセンスを空にすること")
+
+	other := findNode(t, g, "example.com/other@v0.3.0")
+	assert.Equal(t, NodeKindModule, other.Kind, "推移的な依存で要求された最大のバージョンが選択されること")
+
+	for _, node := range g.Nodes {
+		assert.NotEqual(t, "example.com/testonly", node.ID, "テストファイルのインポートは含まれないこと")
+	}
+
+	assert.Contains(t, g.Edges, GraphEdge{From: "example.com/app", To: "example.com/dep/sub", Kind: EdgeKindImports})
+	assert.Contains(t, g.Edges, GraphEdge{From: "example.com/dep/sub", To: "example.com/dep@v1.0.0", Kind: EdgeKindProvidedBy})
+	assert.Contains(t, g.Edges, GraphEdge{From: "example.com/dep@v1.0.0", To: "example.com/deep@v0.2.0", Kind: EdgeKindRequires})
+	assert.Contains(t, g.Edges, GraphEdge{From: "example.com/deep@v0.2.0", To: "example.com/other@v0.3.0", Kind: EdgeKindRequires})
+	for _, edge := range g.Edges {
+		assert.NotEqual(t, "example.com/other@v0.3.0", edge.From, "選択されなかったバージョンの require は選択されたバージョンの依存先にしないこと")
+	}
+}
+
+func TestGetDependencyGraphDepth(t *testing.T) {
+	f := newDependencyGraphFetcher(t)
+
+	g, err := f.GetDependencyGraph("example.com/app", "v1.0.0", DependencyGraphOptions{Depth: 1})
+	require.NoError(t, err)
+
+	var modules []string
+	for _, node := range g.Nodes {
+		if node.Kind == NodeKindModule {
+			modules = append(modules, node.ID)
+			assert.Empty(t, node.License, "ライセンスを取得しない場合は空になること")
+		}
+	}
+	assert.Equal(t, []string{
+		"example.com/app@v1.0.0",
+		"example.com/dep@v1.0.0",
+		"example.com/other@v0.1.0",
+	}, modules, "深さ1では直接の require のみをたどること")
+}
+
+func TestDependencyGraphRender(t *testing.T) {
+	g := &Graph{
+		Nodes: []GraphNode{
+			{ID: "example.com/app", Label: "example.com/app", Kind: NodeKindTarget, Version: "v1.0.0"},
+			{ID: "fmt", Label: "fmt", Kind: NodeKindStdlib, License: "BSD-3-Clause"},
+			{ID: "example.com/app@v1.0.0", Label: "example.com/app", Kind: NodeKindModule, Version: "v1.0.0"},
+			{ID: "example.com/dep@v1.0.0", Label: "example.com/dep", Kind: NodeKindModule, Version: "v1.0.0", License: "MIT"},
+		},
+		Edges: []GraphEdge{
+			{From: "example.com/app", To: "fmt", Kind: EdgeKindImports},
+			{From: "example.com/app@v1.0.0", To: "example.com/dep@v1.0.0", Kind: EdgeKindRequires},
+		},
+	}
+
+	mermaid, err := g.Render(GraphFormatMermaid)
+	require.NoError(t, err)
+	assert.Contains(t, mermaid, `n3["example.com/dep<br/>v1.0.0<br/>MIT"]`, "バージョンとライセンスがラベルに含まれること")
+	assert.Contains(t, mermaid, "n2 -.-> n3", "require の辺は点線になること")
+	assert.Contains(t, mermaid, "class n2,n3 module")
+
+	dot, err := g.Render(GraphFormatDOT)
+	require.NoError(t, err)
+	assert.Contains(t, dot, `"fmt" [label="fmt\nBSD-3-Clause", color=gray, fontcolor=gray];`)
+	assert.Contains(t, dot, `"example.com/app@v1.0.0" -> "example.com/dep@v1.0.0" [style=dashed];`)
+
+	jsonOutput, err := g.Render(GraphFormatJSON)
+	require.NoError(t, err)
+	assert.Contains(t, jsonOutput, `"kind": "stdlib"`)
+}
@@ -0,0 +1,192 @@
+// Package doclink は Go 1.19 以降のドキュメントコメントを解析し、リンクを解決した Markdown に変換する機能を提供します
+package internal
+
+import (
+	"go/ast"
+	"go/doc/comment"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// docHeadingLevel はドキュメントコメントの見出し（# で始まる行）を変換する Markdown の見出しのレベルです
+// サマリーの ## と ### の見出しの下に置くため、#### から始めます
+const docHeadingLevel = 4
+
+// DocLinker はドキュメントコメントのリンク（[Name]、[pkg.Name]、[Type.Method]）を解決して Markdown に変換します
+// 同じパッケージのシンボルのうち、サマリー内にアンカーがあるものはアンカーへ、それ以外は pkg.go.dev の URL へのリンクにします
+type DocLinker struct {
+	// パッケージのインポートパス
+	importPath string
+	// パッケージ内のシンボル（"Name" または "Type.Method"）
+	symbols map[string]bool
+	// サマリー内にアンカーがあるシンボル
+	anchors map[string]bool
+	// シンボルごとの、宣言を含むファイルのインポート（[pkg.Name] の解決に使用します）
+	imports map[string]map[string]string
+}
+
+// NewDocLinker はパッケージの公開APIからリンクを解決する DocLinker を作成します
+// anchored に含まれるシンボルへのリンクは、サマリー内のアンカー（SymbolAnchor）へのリンクにします
+// imports はシンボルごとの、宣言を含むファイルのインポートです（parsePackageAPI が返します）
+func NewDocLinker(importPath string, api []TypeInfo, anchored []TypeInfo, imports map[string]map[string]string) *DocLinker {
+	l := &DocLinker{
+		importPath: importPath,
+		symbols:    map[string]bool{},
+		anchors:    map[string]bool{},
+		imports:    imports,
+	}
+	for _, info := range api {
+		l.symbols[apiSymbol(info)] = true
+	}
+	for _, info := range anchored {
+		l.anchors[apiSymbol(info)] = true
+	}
+	return l
+}
+
+// Markdown はドキュメントコメントを Markdown に変換します
+// imports は [pkg.Name] の pkg をインポートパスに解決するための、コメントを含むファイルのインポートです
+func (l *DocLinker) Markdown(text string, imports map[string]string) string {
+	parser := &comment.Parser{
+		LookupPackage: func(name string) (string, bool) {
+			if importPath, ok := imports[name]; ok {
+				return importPath, true
+			}
+			return comment.DefaultLookupPackage(name)
+		},
+		LookupSym: func(recv string, name string) bool {
+			return l.symbols[docSymbol(recv, name)]
+		},
+	}
+	printer := &comment.Printer{
+		HeadingLevel: docHeadingLevel,
+		// 見出しに {#hdr-...} の ID を付けない
+		HeadingID:  func(*comment.Heading) string { return "" },
+		DocLinkURL: l.linkURL,
+	}
+	return strings.TrimSuffix(string(printer.Markdown(parser.Parse(text))), "\n")
+}
+
+// Apply は公開APIの各要素のドキュメントコメントを Markdown に変換して Doc、Summary、Replacement に設定し、
+// アンカーがある場合は Anchor に、Example 関数がある場合はその pkg.go.dev の URL に設定します
+func (l *DocLinker) Apply(api []TypeInfo) {
+	for i := range api {
+		info := &api[i]
+		imports := l.imports[apiSymbol(*info)]
+		info.Doc = l.Markdown(info.Comment, imports)
+		if summary := firstLine(withoutDeprecation(info.Comment)); summary != "" {
+			info.Summary = l.Markdown(summary, imports)
+		}
+		if info.Replacement != "" {
+			info.Replacement = l.Markdown(info.Replacement, imports)
+		}
+		info.Anchor, _ = l.Anchor(*info)
+		for j := range info.Examples {
+			info.Examples[j].URL = DefaultPkgGoDevURL + "/" + l.importPath + "#" + exampleAnchor(info.Examples[j].Name)
+		}
+	}
+}
+
+// Anchor はシンボルにサマリー内のアンカーがある場合にその ID を返します
+func (l *DocLinker) Anchor(info TypeInfo) (string, bool) {
+	symbol := apiSymbol(info)
+	if !l.anchors[symbol] {
+		return "", false
+	}
+	return SymbolAnchor(l.importPath, symbol), true
+}
+
+// linkURL はドキュメントコメントのリンク先の URL を返します
+func (l *DocLinker) linkURL(link *comment.DocLink) string {
+	importPath := link.ImportPath
+	if importPath == "" {
+		importPath = l.importPath
+	}
+
+	symbol := docSymbol(link.Recv, link.Name)
+	if importPath == l.importPath && l.anchors[symbol] {
+		return "#" + SymbolAnchor(l.importPath, symbol)
+	}
+
+	url := DefaultPkgGoDevURL + "/" + importPath
+	if link.Name != "" {
+		url += "#" + symbol
+	}
+	return url
+}
+
+// SymbolAnchor はサマリー内でシンボル（"Name" または "Type.Method"）の宣言に付けるアンカーの ID を返します
+// 複数のパッケージを1つの文書にまとめても重ならないよう、インポートパスを含めます
+func SymbolAnchor(importPath string, symbol string) string {
+	return "api-" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, importPath+"."+symbol)
+}
+
+// apiSymbol は公開APIの要素のシンボル名（メソッドの場合は "Type.Method"）を返します
+func apiSymbol(info TypeInfo) string {
+	return docSymbol(info.Receiver, info.Name)
+}
+
+// docSymbol はレシーバーの型名と名前からシンボル名を返します
+func docSymbol(recv string, name string) string {
+	if recv == "" {
+		return name
+	}
+	return recv + "." + name
+}
+
+// parseImports はファイルのインポートを解析し、パッケージ名からインポートパスへの対応を返します
+func parseImports(filename string, src string) (map[string]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	return fileImports(f), nil
+}
+
+// fileImports はファイルのインポートを、コメントで参照するパッケージ名からインポートパスへの対応にします
+// 別名のないインポートは、インポートパスから推定したパッケージ名を使用します
+func fileImports(f *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, spec := range f.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := assumedPackageName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name != "_" && name != "." {
+			imports[name] = importPath
+		}
+	}
+	return imports
+}
+
+// assumedPackageName はインポートパスからパッケージ名を推定します
+// メジャーバージョンの接尾辞（/v2、gopkg.in の .v3）と "go-" の接頭辞を除き、識別子に使えない文字の前までを名前とします
+func assumedPackageName(importPath string) string {
+	base := path.Base(importPath)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = path.Base(path.Dir(importPath))
+	}
+	if strings.HasPrefix(importPath, "gopkg.in/") {
+		base, _, _ = strings.Cut(base, ".v")
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// docLinkModuleFiles はドキュメントコメントのリンクのテスト用のモジュールのファイルです
+var docLinkModuleFiles = map[string]string{
+	"go.mod": "module example.com/store\n\ngo 1.22\n",
+	"store.go": `// Package store はデータの保存先を提供します
+package store
+
+import (
+	"context"
+	"net/http"
+
+	yaml "gopkg.in/yaml.v3"
+	"example.com/store/v2/codec"
+)
+
+// Store は保存先です。[Open] で作成し、[Store.Close] で閉じます
+//
+// # 使い方
+//
+// [Store.Get] は [context.Context] を受け取り、[http.Client] で取得した値を [yaml.Node] や [codec.Codec] で復号します。
+// [Missing] や [net/url.URL] も参照できます
+type Store struct{}
+
+// Open は [Store] を開きます
+func Open() *Store { return nil }
+
+// Get は値を取得します
+//
+// Deprecated: [Store.Fetch] を使用してください。
+func (s *Store) Get(ctx context.Context) error { return nil }
+
+// Fetch は値を取得します
+func (s *Store) Fetch(ctx context.Context) error { return nil }
+
+// Close は [Store] を閉じます
+func (s *Store) Close() error { return nil }
+`,
+}
+
+func TestDocLinkerModuleTree(t *testing.T) {
+	source, err := NewModuleSourceFromZip("example.com/store", "v1.0.0", newTestModuleZip(t, "example.com/store", "v1.0.0", docLinkModuleFiles))
+	require.NoError(t, err)
+
+	tree, err := BuildModuleTree(source, ModuleTreeOptions{HideDeprecated: true}, false)
+	require.NoError(t, err)
+	require.Len(t, tree.Packages, 1)
+
+	api := map[string]TypeInfo{}
+	for _, info := range tree.Packages[0].API {
+		api[apiSymbol(info)] = info
+	}
+	require.NotContains(t, api, "Store.Get", "非推奨の宣言は除かれること")
+
+	store := api["Store"]
+	assert.Equal(t, "api-example-com-store-Store", store.Anchor)
+	assert.Equal(t, "Store は保存先です。[Open](#api-example-com-store-Open) で作成し、[Store.Close](#api-example-com-store-Store-Close) で閉じます", store.Summary)
+	assert.Equal(t, "Store は保存先です。[Open](#api-example-com-store-Open) で作成し、[Store.Close](#api-example-com-store-Store-Close) で閉じます\n"+
+		"\n"+
+		"#### 使い方\n"+
+		"\n"+
+		"[Store.Get](https://pkg.go.dev/example.com/store#Store.Get) は [context.Context](https://pkg.go.dev/context#Context) を受け取り、"+
+		"[http.Client](https://pkg.go.dev/net/http#Client) で取得した値を [yaml.Node](https://pkg.go.dev/gopkg.in/yaml.v3#Node) や "+
+		"[codec.Codec](https://pkg.go.dev/example.com/store/v2/codec#Codec) で復号します。 "+
+		"\\[Missing] や [net/url.URL](https://pkg.go.dev/net/url#URL) も参照できます", store.Doc,
+		"サマリーにない同じパッケージの宣言は pkg.go.dev へのリンクになること")
+
+	content, err := FormatModuleTree(tree, "")
+	require.NoError(t, err)
+	assert.Contains(t, content, "- <a id=\"api-example-com-store-Open\"></a>`func Open()`: Open は [Store](#api-example-com-store-Store) を開きます\n")
+}
+
+func TestDocLinkerReplacement(t *testing.T) {
+	api, err := NewParser(false).ParseFile("store.go", docLinkModuleFiles["store.go"])
+	require.NoError(t, err)
+
+	NewDocLinker("example.com/store", api, api, nil).Apply(api)
+	for _, info := range api {
+		if info.Deprecated {
+			assert.Equal(t, "[Store.Fetch](#api-example-com-store-Store-Fetch) を使用してください。", info.Replacement)
+			assert.Equal(t, "- <a id=\"api-example-com-store-Store-Get\"></a>`func (*Store) Get()`: Get は値を取得します（非推奨: [Store.Fetch](#api-example-com-store-Store-Fetch) を使用してください。）\n", apiLine(info.Definition, info))
+		}
+	}
+}
+
+func TestAssumedPackageName(t *testing.T) {
+	tests := map[string]string{
+		"net/http":                     "http",
+		"github.com/mattn/go-sqlite3":  "sqlite3",
+		"gopkg.in/yaml.v3":             "yaml",
+		"github.com/go-chi/chi/v5":     "chi",
+		"example.com/store/v2/codec":   "codec",
+		"github.com/google/go-cmp/cmp": "cmp",
+		"example.com/my-lib":           "my",
+	}
+	for importPath, expected := range tests {
+		assert.Equal(t, expected, assumedPackageName(importPath), importPath)
+	}
+}
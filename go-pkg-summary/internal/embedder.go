@@ -0,0 +1,282 @@
+// Package embedder は公開APIのチャンクと検索クエリをベクトルに変換する埋め込みモデルを提供します
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// EmbedderHash は単語の特徴ハッシングによる埋め込みモデルの名前です
+	EmbedderHash = "hash"
+	// DefaultEmbedder は既定の埋め込みモデルです
+	DefaultEmbedder = EmbedderHash
+	// DefaultEmbeddingDimensions は既定の埋め込みの次元数です
+	DefaultEmbeddingDimensions = 256
+	// EmbedderCommandPrefix は外部コマンドで埋め込みを作成するモデルの名前の接頭辞です（例: "command:my-embed --model small"）
+	EmbedderCommandPrefix = "command:"
+	// EmbeddingDimensionsEnv は外部コマンドに次元数を渡す環境変数です
+	EmbeddingDimensionsEnv = "GOPKGSUMMARY_EMBEDDING_DIMENSIONS"
+)
+
+// Embedder はテキストをベクトルに変換する埋め込みモデルです
+// 索引付けと検索で同じ名前と次元数の埋め込みモデルを使用する必要があります
+type Embedder interface {
+	// 埋め込みモデルの名前（索引に記録し、検索時に同じモデルを選ぶために使用します）
+	Name() string
+	// ベクトルの次元数
+	Dimensions() int
+	// テキストをベクトルに変換します（texts と同じ順序で返します）
+	Embed(texts []string) ([][]float64, error)
+}
+
+// EmbedderFactory は次元数を指定して埋め込みモデルを作成する関数です
+type EmbedderFactory func(dimensions int) (Embedder, error)
+
+var (
+	// embedders は名前ごとに登録した埋め込みモデル
+	embedders   = map[string]EmbedderFactory{EmbedderHash: func(dimensions int) (Embedder, error) { return NewHashEmbedder(dimensions), nil }}
+	embeddersMu sync.RWMutex
+)
+
+// RegisterEmbedder は NewEmbedder で名前を指定して使用できる埋め込みモデルを登録します
+// 同じ名前を登録した場合は置き換えます
+func RegisterEmbedder(name string, factory EmbedderFactory) {
+	embeddersMu.Lock()
+	defer embeddersMu.Unlock()
+	embedders[name] = factory
+}
+
+// EmbedderNames は登録されている埋め込みモデルの名前をソートして返します
+func EmbedderNames() []string {
+	embeddersMu.RLock()
+	defer embeddersMu.RUnlock()
+	names := make([]string, 0, len(embedders))
+	for name := range embedders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewEmbedder は名前と次元数から埋め込みモデルを作成します
+// 名前が EmbedderCommandPrefix で始まる場合は、続くコマンドを実行する CommandEmbedder を作成します
+// dimensions が 0 以下の場合は DefaultEmbeddingDimensions を使用します
+func NewEmbedder(name string, dimensions int) (Embedder, error) {
+	if dimensions <= 0 {
+		dimensions = DefaultEmbeddingDimensions
+	}
+	if name == "" {
+		name = DefaultEmbedder
+	}
+	if command, ok := strings.CutPrefix(name, EmbedderCommandPrefix); ok {
+		return NewCommandEmbedder(command, dimensions)
+	}
+
+	embeddersMu.RLock()
+	factory, ok := embedders[name]
+	embeddersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("不明な埋め込みモデルです: %s（%s または %s<コマンド> を使用できます）",
+			name, strings.Join(EmbedderNames(), ", "), EmbedderCommandPrefix)
+	}
+	return factory(dimensions)
+}
+
+// CommandEmbedder は外部コマンドを実行して埋め込みを作成する埋め込みモデルです
+// コマンドは標準入力からテキストの JSON 配列を読み、同じ順序のベクトルの JSON 配列を標準出力に書き込みます
+// 次元数は環境変数 GOPKGSUMMARY_EMBEDDING_DIMENSIONS で渡します
+type CommandEmbedder struct {
+	// 実行するコマンドと引数（空白で区切ったもの）
+	command string
+	// ベクトルの次元数
+	dimensions int
+}
+
+// NewCommandEmbedder は command を実行する dimensions 次元の CommandEmbedder を作成します
+func NewCommandEmbedder(command string, dimensions int) (*CommandEmbedder, error) {
+	if len(strings.Fields(command)) == 0 {
+		return nil, fmt.Errorf("埋め込みモデルのコマンドを指定してください（例: %smy-embed）", EmbedderCommandPrefix)
+	}
+	return &CommandEmbedder{command: command, dimensions: dimensions}, nil
+}
+
+// Name は埋め込みモデルの名前を返します（索引に記録し、検索時に同じコマンドを実行します）
+func (e *CommandEmbedder) Name() string {
+	return EmbedderCommandPrefix + e.command
+}
+
+// Dimensions はベクトルの次元数を返します
+func (e *CommandEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// Embed はコマンドを実行してテキストをベクトルに変換します
+func (e *CommandEmbedder) Embed(texts []string) ([][]float64, error) {
+	input, err := json.Marshal(texts)
+	if err != nil {
+		return nil, fmt.Errorf("埋め込みの入力の作成に失敗しました: %w", err)
+	}
+
+	args := strings.Fields(e.command)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), EmbeddingDimensionsEnv+"="+strconv.Itoa(e.dimensions))
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s の実行に失敗しました: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	var vectors [][]float64
+	if err := json.Unmarshal(output, &vectors); err != nil {
+		return nil, fmt.Errorf("%s の出力の解析に失敗しました: %w", args[0], err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("%s が返したベクトルの数がテキストの数と一致しません: %d 件（テキスト: %d 件）", args[0], len(vectors), len(texts))
+	}
+	for i, vec := range vectors {
+		if len(vec) != e.dimensions {
+			return nil, fmt.Errorf("%s が返したベクトルの次元数が一致しません: %d 件目は %d 次元（指定: %d 次元）", args[0], i+1, len(vec), e.dimensions)
+		}
+	}
+	return vectors, nil
+}
+
+// HashEmbedder は単語と文字の3-gramを特徴ハッシングでベクトルに変換する、決定的な埋め込みモデルです
+// 外部のサービスを使用しないため、オフラインのテストと、語彙の一致による簡易的な検索に使用できます
+type HashEmbedder struct {
+	// ベクトルの次元数
+	dimensions int
+}
+
+// NewHashEmbedder は dimensions 次元の HashEmbedder を作成します
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	return &HashEmbedder{dimensions: dimensions}
+}
+
+// Name は埋め込みモデルの名前を返します
+func (e *HashEmbedder) Name() string {
+	return EmbedderHash
+}
+
+// Dimensions はベクトルの次元数を返します
+func (e *HashEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// Embed はテキストを正規化したベクトルに変換します
+// 単語は識別子の区切り（ParseFile → parse, file）で分割して小文字にし、英語の簡単な語尾を除きます
+// 語形の違いを吸収するため、単語の文字の3-gramも小さい重みで加えます
+func (e *HashEmbedder) Embed(texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vec := make([]float64, e.dimensions)
+		for _, word := range embeddingWords(text) {
+			e.add(vec, "w:"+word, 1)
+			padded := "^" + word + "$"
+			for j := 0; j+3 <= len(padded); j++ {
+				e.add(vec, "g:"+padded[j:j+3], 0.25)
+			}
+		}
+		normalizeVector(vec)
+		vectors[i] = vec
+	}
+	return vectors, nil
+}
+
+// add は特徴のハッシュ値から次元と符号を求め、重みを加えます
+func (e *HashEmbedder) add(vec []float64, feature string, weight float64) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vec[sum%uint64(len(vec))] += weight
+}
+
+// embeddingStopWords は埋め込みで無視する頻出語です
+var embeddingStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true, "to": true, "in": true,
+	"into": true, "for": true, "is": true, "it": true, "on": true, "by": true, "with": true, "from": true,
+	"be": true, "as": true, "at": true, "that": true, "this": true, "if": true, "are": true,
+}
+
+// embeddingWords はテキストを埋め込みに使用する単語に分割します
+func embeddingWords(text string) []string {
+	var words []string
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		for _, word := range splitIdentifier(field) {
+			word = stemWord(strings.ToLower(word))
+			if word == "" || embeddingStopWords[word] {
+				continue
+			}
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// splitIdentifier は識別子を単語の区切りで分割します（例: ParseYAMLFile → Parse, YAML, File）
+func splitIdentifier(s string) []string {
+	runes := []rune(s)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		if unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && unicode.IsLower(next) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// stemWord は英単語の簡単な語尾（-ing、-ed、-s と末尾の e）を除きます
+// parse、parses、parsed、parsing はいずれも pars になります
+func stemWord(word string) string {
+	for _, suffix := range []string{"ing", "ed", "s"} {
+		if len(word) > len(suffix)+2 && strings.HasSuffix(word, suffix) && !strings.HasSuffix(word, "ss") {
+			word = strings.TrimSuffix(word, suffix)
+			break
+		}
+	}
+	if len(word) > 3 && strings.HasSuffix(word, "e") {
+		word = strings.TrimSuffix(word, "e")
+	}
+	return word
+}
+
+// normalizeVector はベクトルを長さ 1 に正規化します（ゼロベクトルはそのままにします）
+func normalizeVector(vec []float64) {
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	if norm == 0 {
+		return
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] /= norm
+	}
+}
@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashEmbedder(t *testing.T) {
+	embedder, err := NewEmbedder("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, EmbedderHash, embedder.Name())
+	assert.Equal(t, DefaultEmbeddingDimensions, embedder.Dimensions())
+
+	texts := []string{
+		"parse a YAML file into a struct",
+		"gopkg.in/yaml.v3 Unmarshal\nfunc Unmarshal(in []byte, out interface{}) (err error)\nUnmarshal parses the YAML document and stores the result in the struct",
+		"encoding/json Marshal\nfunc Marshal(v any) ([]byte, error)\nMarshal returns the JSON encoding of v",
+	}
+	vectors, err := embedder.Embed(texts)
+	require.NoError(t, err)
+	require.Len(t, vectors, len(texts))
+
+	again, err := embedder.Embed(texts[:1])
+	require.NoError(t, err)
+	assert.Equal(t, vectors[0], again[0], "同じテキストは同じベクトルになること")
+
+	var norm float64
+	for _, v := range vectors[0] {
+		norm += v * v
+	}
+	assert.InDelta(t, 1, math.Sqrt(norm), 1e-9, "ベクトルは正規化されること")
+
+	assert.Greater(t, cosineSimilarity(vectors[0], vectors[1]), cosineSimilarity(vectors[0], vectors[2]),
+		"語彙の重なる宣言の方がクエリに近いこと")
+
+	_, err = NewEmbedder("openai", 0)
+	assert.Error(t, err)
+}
+
+// fixedEmbedder は全てのテキストを同じベクトルに変換するテスト用の埋め込みモデルです
+type fixedEmbedder struct {
+	dimensions int
+}
+
+func (e fixedEmbedder) Name() string    { return "fixed" }
+func (e fixedEmbedder) Dimensions() int { return e.dimensions }
+func (e fixedEmbedder) Embed(texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i := range texts {
+		vectors[i] = make([]float64, e.dimensions)
+	}
+	return vectors, nil
+}
+
+func TestRegisterEmbedder(t *testing.T) {
+	RegisterEmbedder("fixed", func(dimensions int) (Embedder, error) { return fixedEmbedder{dimensions: dimensions}, nil })
+	t.Cleanup(func() {
+		embeddersMu.Lock()
+		delete(embedders, "fixed")
+		embeddersMu.Unlock()
+	})
+
+	embedder, err := NewEmbedder("fixed", 8)
+	require.NoError(t, err)
+	assert.Equal(t, "fixed", embedder.Name())
+	assert.Equal(t, 8, embedder.Dimensions())
+	assert.Equal(t, []string{"fixed", EmbedderHash}, EmbedderNames())
+
+	_, err = NewEmbedder("openai", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fixed, hash", "登録されている埋め込みモデルを示すこと")
+}
+
+// TestCommandEmbedderHelper は CommandEmbedder が実行する外部コマンドとして動作します
+// テキストごとに、1 次元目をテキストの長さとしたベクトルを返します（GO_EMBEDDER_HELPER_EXTRA_DIMENSION を指定した場合は次元数を1つ増やします）
+func TestCommandEmbedderHelper(t *testing.T) {
+	if os.Getenv("GO_WANT_EMBEDDER_HELPER") != "1" {
+		return
+	}
+	dimensions, _ := strconv.Atoi(os.Getenv(EmbeddingDimensionsEnv))
+	if os.Getenv("GO_EMBEDDER_HELPER_EXTRA_DIMENSION") == "1" {
+		dimensions++
+	}
+	var texts []string
+	if err := json.NewDecoder(os.Stdin).Decode(&texts); err != nil {
+		os.Exit(2)
+	}
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float64, dimensions)
+		vectors[i][0] = float64(len(text))
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(vectors)
+	os.Exit(0)
+}
+
+func TestCommandEmbedder(t *testing.T) {
+	t.Setenv("GO_WANT_EMBEDDER_HELPER", "1")
+	name := EmbedderCommandPrefix + os.Args[0] + " -test.run=^TestCommandEmbedderHelper$"
+
+	embedder, err := NewEmbedder(name, 3)
+	require.NoError(t, err)
+	assert.Equal(t, name, embedder.Name(), "索引に記録する名前からコマンドを再現できること")
+	assert.Equal(t, 3, embedder.Dimensions())
+
+	vectors, err := embedder.Embed([]string{"a", "abc"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{1, 0, 0}, {3, 0, 0}}, vectors)
+
+	// コマンドが指定と異なる次元数のベクトルを返した場合はエラーになる
+	t.Setenv("GO_EMBEDDER_HELPER_EXTRA_DIMENSION", "1")
+	_, err = embedder.Embed([]string{"a"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "4 次元（指定: 3 次元）")
+
+	_, err = NewEmbedder(EmbedderCommandPrefix, 3)
+	assert.Error(t, err, "コマンドが空の場合はエラーになること")
+}
+
+func TestEmbeddingWords(t *testing.T) {
+	assert.Equal(t, []string{"pars", "yaml", "fil", "struct"}, embeddingWords("parse a YAML file into a struct"))
+	assert.Equal(t, []string{"pars", "yaml", "fil"}, embeddingWords("ParseYAMLFile"))
+	assert.Equal(t, []string{"pars", "pars", "pars"}, embeddingWords("parses parsed parsing"))
+}
+
+// cosineSimilarity は正規化したベクトルのコサイン類似度を返します
+func cosineSimilarity(a, b []float64) float64 {
+	var dot float64
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return dot
+}
@@ -0,0 +1,112 @@
+// Package examples はテストファイルの Example 関数から、ジェネリックな宣言のインスタンス化の例を抽出する機能を提供します
+package internal
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parseExamples はパッケージのテストファイル（_test.go）から Example 関数を抽出します
+// 解析できないファイルは無視します
+func parseExamples(source *ModuleSource, dir string, files []string) ([]*doc.Example, *token.FileSet) {
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for _, file := range files {
+		content, err := source.ReadFile(path.Join(dir, file))
+		if err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, content, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, f)
+	}
+	return doc.Examples(parsed...), fset
+}
+
+// linkExamples はジェネリックな型と関数に、その使用例を示す Example 関数を関連付けます
+// Example 関数の名前が宣言に対応するもの（ExampleMap、ExampleMap_ints）と、
+// 本体で宣言を明示的にインスタンス化しているもの（Map[int, string] や pkg.Set[int]）が対象です
+func linkExamples(api []TypeInfo, examples []*doc.Example, fset *token.FileSet) {
+	for i := range api {
+		info := &api[i]
+		if len(info.TypeParams) == 0 || info.Receiver != "" {
+			continue
+		}
+		for _, example := range examples {
+			instantiation := findInstantiation(fset, example.Code, info.Name)
+			if example.Name != info.Name && instantiation == "" {
+				continue
+			}
+			name := "Example" + example.Name
+			if example.Suffix != "" {
+				name += "_" + example.Suffix
+			}
+			info.Examples = append(info.Examples, ExampleRef{Name: name, Instantiation: instantiation})
+		}
+	}
+}
+
+// findInstantiation は Example 関数の本体から name の明示的なインスタンス化を探し、最初のものを返します
+// 同じパッケージのテスト（Map[int]）と外部テストパッケージ（pkg.Map[int]）の両方に対応します
+func findInstantiation(fset *token.FileSet, code ast.Node, name string) string {
+	if code == nil {
+		return ""
+	}
+
+	var found string
+	ast.Inspect(code, func(n ast.Node) bool {
+		if found != "" {
+			return false
+		}
+		var x ast.Expr
+		switch e := n.(type) {
+		case *ast.IndexExpr:
+			x = e.X
+		case *ast.IndexListExpr:
+			x = e.X
+		default:
+			return true
+		}
+		switch x := x.(type) {
+		case *ast.Ident:
+			if x.Name == name {
+				found = formatNode(fset, n)
+			}
+		case *ast.SelectorExpr:
+			if x.Sel.Name == name {
+				found = formatNode(fset, n)
+			}
+		}
+		return found == ""
+	})
+	return found
+}
+
+// exampleAnchor は pkg.go.dev の Example のアンカーを返します
+// ExampleSet_Add は "example-Set.Add"、ExampleMap_ints は "example-Map-Ints"、Example は "example-package" になります
+func exampleAnchor(name string) string {
+	symbol, suffix := strings.TrimPrefix(name, "Example"), ""
+	if i := strings.LastIndex(symbol, "_"); i >= 0 {
+		if r, _ := utf8.DecodeRuneInString(symbol[i+1:]); unicode.IsLower(r) {
+			symbol, suffix = symbol[:i], symbol[i+1:]
+		}
+	}
+
+	anchor := "example-" + strings.Replace(strings.TrimPrefix(symbol, "_"), "_", ".", 1)
+	if symbol == "" {
+		anchor = "example-package"
+	}
+	if suffix != "" {
+		r, size := utf8.DecodeRuneInString(suffix)
+		anchor += "-" + string(unicode.ToUpper(r)) + suffix[size:]
+	}
+	return anchor
+}
@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exampleModuleFiles は Example 関数の関連付けのテスト用のモジュールのファイルです
+var exampleModuleFiles = map[string]string{
+	"go.mod": "module example.com/collection\n\ngo 1.22\n",
+	"collection.go": `// Package collection はジェネリックなコレクションを提供します
+package collection
+
+// Set は要素の集合です
+type Set[T comparable] struct{}
+
+// NewSet は空の [Set] を作成します
+func NewSet[T comparable]() *Set[T] { return &Set[T]{} }
+
+// Add は要素を追加します
+func (s *Set[T]) Add(v T) {}
+
+// Map はスライスの各要素を変換します
+func Map[T, U any](s []T, f func(T) U) []U { return nil }
+
+// Filter は条件を満たす要素を返します
+func Filter[T any](s []T, f func(T) bool) []T { return nil }
+
+// Len は要素数を返します
+func Len(s []int) int { return len(s) }
+`,
+	"example_test.go": `package collection_test
+
+import (
+	"fmt"
+	"strconv"
+
+	"example.com/collection"
+)
+
+func ExampleSet() {
+	s := collection.NewSet[string]()
+	s.Add("a")
+}
+
+func ExampleMap_ints() {
+	fmt.Println(collection.Map[int, string]([]int{1, 2}, strconv.Itoa))
+	// Output: [1 2]
+}
+
+func ExampleFilter() {
+	fmt.Println(collection.Filter([]int{1, 2}, func(v int) bool { return v > 1 }))
+	// Output: [2]
+}
+`,
+	"set_test.go": `package collection
+
+func ExampleSet_Add() {
+	var s Set[int]
+	s.Add(1)
+}
+`,
+}
+
+func TestLinkExamples(t *testing.T) {
+	source, err := NewModuleSourceFromZip("example.com/collection", "v1.0.0", newTestModuleZip(t, "example.com/collection", "v1.0.0", exampleModuleFiles))
+	require.NoError(t, err)
+
+	tree, err := BuildModuleTree(source, ModuleTreeOptions{}, false)
+	require.NoError(t, err)
+	require.Len(t, tree.Packages, 1)
+
+	api := map[string]TypeInfo{}
+	for _, info := range tree.Packages[0].API {
+		api[apiSymbol(info)] = info
+	}
+
+	assert.Equal(t, []ExampleRef{
+		{Name: "ExampleSet", URL: "https://pkg.go.dev/example.com/collection#example-Set"},
+		{Name: "ExampleSet_Add", Instantiation: "Set[int]", URL: "https://pkg.go.dev/example.com/collection#example-Set.Add"},
+	}, api["Set"].Examples, "名前の一致する Example 関数と、型をインスタンス化する Example 関数を関連付けること")
+	assert.Equal(t, []ExampleRef{
+		{Name: "ExampleSet", Instantiation: "collection.NewSet[string]", URL: "https://pkg.go.dev/example.com/collection#example-Set"},
+	}, api["NewSet"].Examples, "外部テストパッケージのインスタンス化も対象とすること")
+	assert.Equal(t, []ExampleRef{
+		{Name: "ExampleMap_ints", Instantiation: "collection.Map[int, string]", URL: "https://pkg.go.dev/example.com/collection#example-Map-Ints"},
+	}, api["Map"].Examples)
+	assert.Equal(t, []ExampleRef{
+		{Name: "ExampleFilter", URL: "https://pkg.go.dev/example.com/collection#example-Filter"},
+	}, api["Filter"].Examples, "型引数を推論する場合はインスタンス化を空にすること")
+	assert.Empty(t, api["Set.Add"].Examples, "メソッドには関連付けないこと")
+	assert.Empty(t, api["Len"].Examples, "ジェネリックでない宣言には関連付けないこと")
+
+	content, err := FormatModuleTree(tree, "")
+	require.NoError(t, err)
+	assert.Contains(t, content, "`func Map[T, U any]()`: Map はスライスの各要素を変換します"+
+		"（例: [`collection.Map[int, string]`](https://pkg.go.dev/example.com/collection#example-Map-Ints)）\n")
+}
+
+func TestExampleAnchor(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Example", "example-package"},
+		{"Example_basic", "example-package-Basic"},
+		{"ExampleMap", "example-Map"},
+		{"ExampleMap_ints", "example-Map-Ints"},
+		{"ExampleSet_Add", "example-Set.Add"},
+		{"ExampleSet_Add_twice", "example-Set.Add-Twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exampleAnchor(tt.name))
+		})
+	}
+}
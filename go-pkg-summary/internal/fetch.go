@@ -9,34 +9,94 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultGitHubAPIURL は GitHub の API の URL です
+	DefaultGitHubAPIURL = "https://api.github.com"
+	// DefaultGitLabAPIURL は GitLab の API の URL です
+	DefaultGitLabAPIURL = "https://gitlab.com/api/v4"
 )
 
 // Fetcher はパッケージ情報を取得する構造体です
 type Fetcher struct {
-	scraper *Scraper
-	cache   *Cache
-	client  *http.Client
-	debug   bool
+	scraper  *Scraper
+	cache    *Cache
+	client   *http.Client
+	proxyURL string
+	debug    bool
+
+	// GitHub と GitLab の API の URL
+	githubAPIURL string
+	gitlabAPIURL string
+
+	// GOPRIVATE と GONOSUMDB のパターン（一致するモジュールは git で直接取得する）
+	privatePatterns string
+	// go-import のディスカバリーの URL の基点（空の場合は https://<インポートパス>）
+	goGetBaseURL string
+	// インポートパスごとに解決したリポジトリ（*RepoRoot）
+	repoRoots sync.Map
+	// "インポートパス@バージョン" ごとに取得したパッケージ情報（*Package）と、同時の取得をまとめるグループ
+	packageInfos     sync.Map
+	packageInfoGroup singleflight.Group
+	// "モジュールパス@バージョン" ごとにモジュールの同時の取得をまとめるグループと、キャッシュを使用せずに取得し直したモジュール
+	moduleGroup      singleflight.Group
+	refreshedModules sync.Map
+
+	// ローカルの Go の GOROOT とバージョン（初回の使用時に goEnv で取得し、見つからない場合は空文字列）
+	goEnv     func() (string, string)
+	goEnvOnce sync.Once
+	goroot    string
+	goVersion string
+	// 標準ライブラリのソースコードを取得する Go のリポジトリとミラーの URL
+	stdlibSourceURL string
+	stdlibMirrorURL string
 }
 
 // NewFetcher は新しいFetcherインスタンスを作成します
-func NewFetcher(debug bool) (*Fetcher, error) {
-	c, err := NewCache()
-	if err != nil {
-		return nil, err
+// opts で HTTP クライアント、外部サービスの URL、キャッシュを差し替えられます
+func NewFetcher(debug bool, opts ...Option) (*Fetcher, error) {
+	o := newOptions(opts)
+
+	c := o.cache
+	if c == nil {
+		var err error
+		c, err = NewCache()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client := &http.Client{}
+	if o.httpClient != nil {
+		client.Transport = o.httpClient.Transport
+		client.Jar = o.httpClient.Jar
+		client.Timeout = o.httpClient.Timeout
+	}
+	client.Transport = o.transport(client.Transport)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
 	}
 
 	return &Fetcher{
-		scraper: NewScraper(debug),
-		cache:   c,
-		client: &http.Client{
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		},
-		debug: debug,
+		scraper:         NewScraper(debug, opts...),
+		cache:           c,
+		client:          client,
+		proxyURL:        o.baseURLs.Proxy,
+		debug:           debug,
+		githubAPIURL:    o.baseURLs.GitHubAPI,
+		gitlabAPIURL:    o.baseURLs.GitLabAPI,
+		goGetBaseURL:    o.baseURLs.GoGet,
+		goEnv:           localGoEnv,
+		privatePatterns: privateModulePatterns(),
+		stdlibSourceURL: o.baseURLs.StdlibSource,
+		stdlibMirrorURL: o.baseURLs.StdlibMirror,
 	}, nil
 }
 
@@ -45,8 +105,22 @@ func (f *Fetcher) SearchPackage(query string, limit int) ([]Package, error) {
 	return f.scraper.SearchPackage(query, limit)
 }
 
+// SearchSymbol はpkg.go.devでシンボルを検索します
+func (f *Fetcher) SearchSymbol(query string, limit int) ([]Symbol, error) {
+	return f.scraper.SearchSymbol(query, limit)
+}
+
 // GetPackage はパッケージ情報を取得します
 func (f *Fetcher) GetPackage(importPath string, version string, opts GetPackageOptions) (string, error) {
+	// サマリーのキャッシュを使用しない場合も、--no-cache を指定していなければモジュールの zip のキャッシュは使用する
+	useModuleCache := opts.UseCache
+
+	// README のセクションやファイル一覧を絞り込む場合や、テンプレートを指定した場合は内容が変わるため、キャッシュを使用しない
+	// HideDeprecated は既定のテンプレートの出力を変えない（宣言を出力しない）ため、キャッシュを使用する
+	if len(opts.ReadmeSections) > 0 || len(opts.Include) > 0 || len(opts.Exclude) > 0 || opts.Template != nil {
+		opts.UseCache = false
+	}
+
 	// キャッシュから取得を試みる
 	if opts.UseCache {
 		content, err := f.cache.GetContentFromCache(importPath, version)
@@ -54,12 +128,12 @@ func (f *Fetcher) GetPackage(importPath string, version string, opts GetPackageO
 			if f.debug {
 				fmt.Printf("キャッシュからパッケージ情報を取得しました: %s@%s\n", importPath, version)
 			}
-			return content, nil
+			return f.withSecuritySection(content, importPath, version, opts.VulnDB), nil
 		}
 	}
 
 	// パッケージ情報を取得
-	pkg, err := f.scraper.GetPackageInfo(importPath, version)
+	pkg, err := f.getPackageInfo(importPath, version)
 	if err != nil {
 		return "", fmt.Errorf("パッケージ情報の取得に失敗しました: %w", err)
 	}
@@ -70,72 +144,238 @@ func (f *Fetcher) GetPackage(importPath string, version string, opts GetPackageO
 		actualVersion = "latest"
 	}
 
-	// 出力を構築
-	var output strings.Builder
-
-	// パッケージ情報
-	output.WriteString(fmt.Sprintf("# %s\n\n", pkg.Name))
-	output.WriteString(fmt.Sprintf("インポートパス: %s\n", pkg.ImportPath))
-	if pkg.Version != "" {
-		output.WriteString(fmt.Sprintf("バージョン: %s\n", pkg.Version))
+	// モジュールのライセンスファイルからライセンスを検出（検出できない場合は pkg.go.dev の情報を使用）
+	// 標準ライブラリはモジュールプロキシに存在しないため検出しない
+	license := pkg.License
+	if !f.IsStdlibPackage(importPath) {
+		licenses, err := f.GetModuleLicenses(importPath, actualVersion, useModuleCache)
+		if err != nil {
+			if f.debug {
+				fmt.Printf("ライセンスの検出に失敗しました: %v\n", err)
+			}
+		} else if detected := licenses.String(); detected != "" {
+			license = detected
+		}
 	}
-	if pkg.Synopsis != "" {
-		output.WriteString(fmt.Sprintf("概要: %s\n", pkg.Synopsis))
+
+	// テンプレートに渡すデータを構築
+	summaryPkg := *pkg
+	summaryPkg.License = license
+	data := &SummaryData{
+		Package: &summaryPkg,
+		types:   f.lazyTypes(importPath, actualVersion, opts.HideDeprecated, useModuleCache),
 	}
-	output.WriteString(fmt.Sprintf("ドキュメントURL: %s\n", pkg.DocURL))
-	if pkg.RepoURL != "" {
-		output.WriteString(fmt.Sprintf("リポジトリURL: %s\n", pkg.RepoURL))
+
+	// モジュールの非推奨とバージョンの撤回を確認（標準ライブラリは対象外）
+	if !f.IsStdlibPackage(importPath) {
+		status, err := f.GetModuleStatus(importPath, actualVersion)
+		if err != nil {
+			if f.debug {
+				fmt.Printf("モジュールの状態の確認に失敗しました: %v\n", err)
+			}
+		} else {
+			data.Status = status
+		}
 	}
-	output.WriteString("\n")
 
 	// ファイル一覧を取得
 	files, err := f.ListPackageFiles(importPath, actualVersion)
 	if err != nil {
 		return "", fmt.Errorf("ファイル一覧の取得に失敗しました: %w", err)
 	}
-
-	// ファイル一覧を出力
-	output.WriteString("## ファイル一覧\n\n")
-	for _, file := range files {
-		output.WriteString(fmt.Sprintf("- %s\n", file))
-	}
-	output.WriteString("\n")
-
-	// 主要なファイルの内容を取得
-	output.WriteString("## 主要なファイル\n\n")
+	data.Files = FilterFiles(files, opts.Include, opts.Exclude)
 
 	// go.mod ファイルを取得
 	goModContent, err := f.ReadPackageFile(importPath, actualVersion, "go.mod")
 	if err == nil {
-		output.WriteString("### go.mod\n\n")
-		output.WriteString("```go\n")
-		output.WriteString(goModContent)
-		output.WriteString("\n```\n\n")
+		data.GoMod = parseGoModInfo(goModContent)
 	}
 
-	// README.md ファイルを取得
+	// README.md ファイルを取得し、バッジの削除や相対リンクの書き換えを行う
 	readmeContent, err := f.ReadPackageFile(importPath, actualVersion, "README.md")
 	if err == nil {
-		output.WriteString("### README.md\n\n")
-		output.WriteString(readmeContent)
-		output.WriteString("\n\n")
+		linkBase, imageBase := ReadmeBaseURLs(pkg.RepoURL, actualVersion)
+		data.Readme = ProcessReadme(readmeContent, ReadmeOptions{
+			LinkBase:  linkBase,
+			ImageBase: imageBase,
+			Sections:  opts.ReadmeSections,
+		})
+	}
+
+	// テンプレートを適用
+	tmpl := opts.Template
+	if tmpl == nil {
+		tmpl = DefaultSummaryTemplate()
+	}
+	content, err := RenderSummary(tmpl, data)
+	if err != nil {
+		return "", err
 	}
 
 	// 結果をキャッシュに保存
 	if opts.UseCache {
-		err = f.cache.SaveContentToCache(importPath, actualVersion, output.String())
+		err = f.cache.SaveContentToCache(importPath, actualVersion, content)
 		if err != nil && f.debug {
 			fmt.Printf("キャッシュへの保存に失敗しました: %v\n", err)
 		}
 	}
 
-	return output.String(), nil
+	return f.withSecuritySection(content, importPath, actualVersion, opts.VulnDB), nil
+}
+
+// FilterFiles は include のいずれかに一致し、exclude のいずれにも一致しないファイルを返します
+// include が空の場合は全てのファイルを対象とします
+// パターンは path.Match の形式で、パス全体またはファイル名に一致するかを判定します
+func FilterFiles(files []string, include []string, exclude []string) []string {
+	matchAny := func(file string, patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, file); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, path.Base(file)); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	var filtered []string
+	for _, file := range files {
+		if len(include) > 0 && !matchAny(file, include) {
+			continue
+		}
+		if matchAny(file, exclude) {
+			continue
+		}
+		filtered = append(filtered, file)
+	}
+	return filtered
+}
+
+// withSecuritySection は脆弱性データベースが指定されている場合に、サマリーのヘッダーの直後へ Security セクションを挿入します
+// 標準ライブラリのパッケージは、バージョン（"latest" の場合はローカルの Go のバージョン）を解決して照合します
+func (f *Fetcher) withSecuritySection(content string, importPath string, version string, db *VulnDB) string {
+	if db == nil || !f.IsStdlibPackage(importPath) {
+		return withSecuritySection(content, importPath, version, false, db)
+	}
+	if tag, err := f.ResolveStdlibVersion(version); err == nil {
+		version = tag
+	}
+	return withSecuritySection(content, importPath, version, true, db)
+}
+
+// withSecuritySection は脆弱性データベースが指定されている場合に、サマリーのヘッダーの直後へ Security セクションを挿入します
+// stdlib が true の場合は、標準ライブラリのパッケージとして Go のバージョン（例: go1.22.0）で照合します
+// 照合結果はデータベースによって変わるため、キャッシュには保存しません
+func withSecuritySection(content string, importPath string, version string, stdlib bool, db *VulnDB) string {
+	if db == nil {
+		return content
+	}
+
+	var section string
+	var err error
+	if stdlib {
+		var vulns []Vulnerability
+		vulns, err = db.QueryStdlib(importPath, version)
+		if err == nil {
+			section = FormatSecuritySection(db, importPath, version, vulns)
+		}
+	} else {
+		var modulePath string
+		modulePath, err = db.ModuleFor(importPath)
+		if err == nil {
+			if modulePath == "" {
+				modulePath = importPath
+			}
+			var vulns []Vulnerability
+			vulns, err = db.Query(modulePath, version)
+			if err == nil {
+				section = FormatSecuritySection(db, modulePath, version, vulns)
+			}
+		}
+	}
+	if err != nil {
+		section = fmt.Sprintf("## Security\n\n脆弱性の照合に失敗しました: %v\n\n", err)
+	}
+
+	index := strings.Index(content, "\n## ")
+	if index == -1 {
+		return content + "\n" + section
+	}
+	return content[:index+1] + section + content[index+1:]
+}
+
+// getPackageInfo はパッケージ情報を取得します
+// 同じパッケージへの同時の取得は1回にまとめ、バージョンを指定した取得の結果は Fetcher ごとに保持します
+// "latest" の結果は解決したバージョンで保持するため、長時間動作するサーバーでも最新のバージョンを取得し直します
+func (f *Fetcher) getPackageInfo(importPath string, version string) (*Package, error) {
+	key := importPath + "@" + version
+	if cached, ok := f.packageInfos.Load(key); ok {
+		pkg := *cached.(*Package)
+		return &pkg, nil
+	}
+
+	v, err, _ := f.packageInfoGroup.Do(key, func() (any, error) {
+		pkg, err := f.loadPackageInfo(importPath, version)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.Version != "" {
+			f.packageInfos.Store(importPath+"@"+pkg.Version, pkg)
+		}
+		return pkg, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	pkg := *v.(*Package)
+	return &pkg, nil
+}
+
+// loadPackageInfo はパッケージ情報を取得します
+// 標準ライブラリと非公開モジュールの場合はソースコードから、それ以外の場合は pkg.go.dev から取得し、リポジトリは go-import のディスカバリーで解決します
+func (f *Fetcher) loadPackageInfo(importPath string, version string) (*Package, error) {
+	if f.IsStdlibPackage(importPath) {
+		return f.getStdlibPackageInfo(importPath, version)
+	}
+	if f.IsPrivateModule(importPath) {
+		return f.getPrivatePackageInfo(importPath, version)
+	}
+
+	pkg, err := f.scraper.GetPackageInfo(importPath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// go-import のディスカバリーでリポジトリを解決し、失敗した場合は pkg.go.dev のリンクを使用する
+	root, err := f.DiscoverRepoRoot(importPath)
+	if err != nil {
+		if f.debug {
+			fmt.Printf("リポジトリの解決に失敗しました: %v\n", err)
+		}
+	} else {
+		pkg.RepoURL = root.RepoURL
+	}
+	return pkg, nil
 }
 
 // ListPackageFiles はパッケージ内のファイル一覧を取得します
+// 標準ライブラリの場合は、パッケージのディレクトリ以下のファイルを GOROOT/src からのパスで返します
+// 非公開モジュールの場合は、モジュール内の全てのファイルをモジュールのルートからのパスで返します
 func (f *Fetcher) ListPackageFiles(importPath string, version string) ([]string, error) {
+	if f.IsStdlibPackage(importPath) {
+		return f.listStdlibFiles(importPath, version)
+	}
+	if f.IsPrivateModule(importPath) {
+		source, _, err := f.packageSource(importPath, version, true)
+		if err != nil {
+			return nil, err
+		}
+		return source.Files()
+	}
+
 	// パッケージ情報を取得
-	pkg, err := f.scraper.GetPackageInfo(importPath, version)
+	pkg, err := f.getPackageInfo(importPath, version)
 	if err != nil {
 		return nil, fmt.Errorf("パッケージ情報の取得に失敗しました: %w", err)
 	}
@@ -203,7 +443,7 @@ func (f *Fetcher) listGitHubFiles(repoURL string, version string) ([]string, err
 	}
 
 	repoPath := strings.TrimSuffix(parts[1], "/")
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/contents", repoPath)
+	apiURL := fmt.Sprintf("%s/repos/%s/contents", f.githubAPIURL, repoPath)
 
 	// バージョンが指定されている場合はrefパラメータを追加
 	if version != "" && version != "latest" {
@@ -326,7 +566,7 @@ func (f *Fetcher) listGitLabFiles(repoURL string, version string) ([]string, err
 	}
 
 	repoPath := strings.TrimSuffix(parts[1], "/")
-	apiURL := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/repository/tree", url.PathEscape(repoPath))
+	apiURL := fmt.Sprintf("%s/projects/%s/repository/tree", f.gitlabAPIURL, url.PathEscape(repoPath))
 
 	// バージョンが指定されている場合はrefパラメータを追加
 	if version != "" && version != "latest" {
@@ -382,9 +622,18 @@ func (f *Fetcher) listGitLabFiles(repoURL string, version string) ([]string, err
 }
 
 // ReadPackageFile はパッケージ内の特定ファイルを読み込みます
+// 標準ライブラリの場合は GOROOT/src からのパス、非公開モジュールの場合はモジュールのルートからのパスで指定します
 func (f *Fetcher) ReadPackageFile(importPath string, version string, filePath string) (string, error) {
+	if f.IsStdlibPackage(importPath) || f.IsPrivateModule(importPath) {
+		source, _, err := f.packageSource(importPath, version, true)
+		if err != nil {
+			return "", err
+		}
+		return source.ReadFile(filePath)
+	}
+
 	// パッケージ情報を取得
-	pkg, err := f.scraper.GetPackageInfo(importPath, version)
+	pkg, err := f.getPackageInfo(importPath, version)
 	if err != nil {
 		return "", fmt.Errorf("パッケージ情報の取得に失敗しました: %w", err)
 	}
@@ -451,7 +700,7 @@ func (f *Fetcher) readGitHubFile(repoURL string, version string, filePath string
 	}
 
 	repoPath := strings.TrimSuffix(parts[1], "/")
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/contents/%s", repoPath, filePath)
+	apiURL := fmt.Sprintf("%s/repos/%s/contents/%s", f.githubAPIURL, repoPath, filePath)
 
 	// バージョンが指定されている場合はrefパラメータを追加
 	if version != "" && version != "latest" {
@@ -515,8 +764,8 @@ func (f *Fetcher) readGitLabFile(repoURL string, version string, filePath string
 	}
 
 	repoPath := strings.TrimSuffix(parts[1], "/")
-	apiURL := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/repository/files/%s/raw",
-		url.PathEscape(repoPath), url.PathEscape(filePath))
+	apiURL := fmt.Sprintf("%s/projects/%s/repository/files/%s/raw",
+		f.gitlabAPIURL, url.PathEscape(repoPath), url.PathEscape(filePath))
 
 	// バージョンが指定されている場合はrefパラメータを追加
 	if version != "" && version != "latest" {
@@ -598,3 +847,41 @@ func (f *Fetcher) DownloadFile(url string, destPath string) error {
 	_, err = io.Copy(out, resp.Body)
 	return err
 }
+
+// ReadPackageSymbol はパッケージ内のシンボルの宣言をドキュメントコメント付きで取得します
+// シンボルは "Name" または "Type.Method" の形式で指定します
+// モジュールの zip（標準ライブラリの場合は GOROOT）から、現在の環境のビルド制約に一致するファイルのみを探します
+func (f *Fetcher) ReadPackageSymbol(importPath string, version string, symbol string) (*Declaration, error) {
+	source, dir, err := f.packageSource(importPath, version, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ctxt := moduleBuildContext(source.FS)
+	bp, err := ctxt.ImportDir("/"+dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("パッケージ %s の読み込みに失敗しました: %w", importPath, err)
+	}
+
+	p := NewParser(f.debug)
+	for _, name := range bp.GoFiles {
+		file := path.Join(dir, name)
+		content, err := source.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		decl, err := p.FindDeclaration(file, content, symbol)
+		if err != nil {
+			if f.debug {
+				fmt.Printf("ファイル %s の解析に失敗しました: %v\n", file, err)
+			}
+			continue
+		}
+		if decl != nil {
+			return decl, nil
+		}
+	}
+
+	return nil, fmt.Errorf("シンボル %s が %s に見つかりません", symbol, importPath)
+}
@@ -0,0 +1,204 @@
+// Package goimport は go get と同じ ?go-get=1 のディスカバリーで、インポートパスからリポジトリを解決する機能を提供します
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// VCSMod は go-import タグでモジュールプロキシを表す VCS の種類です
+	VCSMod = "mod"
+
+	// goGetTimeout は ?go-get=1 のリクエストのタイムアウトです
+	goGetTimeout = 10 * time.Second
+)
+
+// RepoRoot はインポートパスを含むリポジトリを表す構造体です
+type RepoRoot struct {
+	// リポジトリのルートに対応するインポートパスの接頭辞
+	ImportPrefix string `json:"importPrefix"`
+	// VCS の種類（git、hg、svn、bzr、fossil、mod）
+	VCS string `json:"vcs"`
+	// go-import タグで指定されたリポジトリの URL
+	Root string `json:"root"`
+	// モジュールがリポジトリのサブディレクトリにある場合のディレクトリ
+	SubDir string `json:"subDir,omitempty"`
+	// ソースコードを閲覧できるリポジトリの URL（GitHub や GitLab の場合は API で使用）
+	RepoURL string `json:"repoURL"`
+	// go-source タグで指定されたホームページ
+	SourceHome string `json:"sourceHome,omitempty"`
+	// go-source タグで指定されたディレクトリの URL のテンプレート（{dir} を含む）
+	SourceDir string `json:"sourceDir,omitempty"`
+	// go-source タグで指定されたファイルの URL のテンプレート（{dir}、{file}、{line} を含む）
+	SourceFile string `json:"sourceFile,omitempty"`
+}
+
+// PackageDir はリポジトリのルートから見たパッケージのディレクトリを返します
+func (r *RepoRoot) PackageDir(importPath string) string {
+	rel := strings.TrimPrefix(strings.TrimPrefix(importPath, r.ImportPrefix), "/")
+	return strings.TrimPrefix(path.Join(r.SubDir, rel), ".")
+}
+
+// goImport は go-import タグの内容を表す構造体です
+type goImport struct {
+	prefix string
+	vcs    string
+	root   string
+	subDir string
+}
+
+// DiscoverRepoRoot はインポートパスを含むリポジトリを解決します
+// GitHub、GitLab、Bitbucket のインポートパスはパスから直接求め、それ以外は https://<importPath>?go-get=1 の
+// go-import タグと go-source タグから求めます
+// 結果は Fetcher ごとに保持し、同じインポートパスでは再度リクエストしません
+func (f *Fetcher) DiscoverRepoRoot(importPath string) (*RepoRoot, error) {
+	if cached, ok := f.repoRoots.Load(importPath); ok {
+		return cached.(*RepoRoot), nil
+	}
+
+	var root *RepoRoot
+	if repoURL := repoURLFromImportPath(importPath); repoURL != "" {
+		parts := strings.SplitN(importPath, "/", 4)
+		root = &RepoRoot{
+			ImportPrefix: strings.Join(parts[:3], "/"),
+			VCS:          "git",
+			Root:         repoURL,
+			RepoURL:      repoURL,
+		}
+	} else {
+		var err error
+		root, err = f.discoverGoImport(importPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f.repoRoots.Store(importPath, root)
+	return root, nil
+}
+
+// discoverGoImport は ?go-get=1 のレスポンスの meta タグからリポジトリを解決します
+func (f *Fetcher) discoverGoImport(importPath string) (*RepoRoot, error) {
+	discoveryURL := fmt.Sprintf("https://%s?go-get=1", importPath)
+	if f.goGetBaseURL != "" {
+		discoveryURL = fmt.Sprintf("%s/%s?go-get=1", f.goGetBaseURL, importPath)
+	}
+
+	if f.debug {
+		fmt.Printf("go-import のディスカバリー URL: %s\n", discoveryURL)
+	}
+
+	// ホストによってはリダイレクトするため、リダイレクトを追跡するクライアントを使用する
+	// 非公開のドメインに対応するため、.netrc に認証情報があれば使用する
+	client := &http.Client{Transport: f.client.Transport, Timeout: goGetTimeout}
+	req, err := http.NewRequest("GET", discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+	}
+	setNetrcAuth(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("go-import のディスカバリーに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// go コマンドと同様に、エラーのステータスでも meta タグがあれば使用する
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("HTML のパースに失敗しました: %w", err)
+	}
+
+	var imports []goImport
+	doc.Find(`meta[name="go-import"]`).Each(func(i int, sel *goquery.Selection) {
+		content, _ := sel.Attr("content")
+		fields := strings.Fields(content)
+		if len(fields) != 3 && len(fields) != 4 {
+			return
+		}
+		imp := goImport{prefix: fields[0], vcs: fields[1], root: fields[2]}
+		if len(fields) == 4 {
+			imp.subDir = fields[3]
+		}
+		if hasPathPrefix(importPath, imp.prefix) {
+			imports = append(imports, imp)
+		}
+	})
+
+	imp, err := selectGoImport(imports)
+	if err != nil {
+		return nil, fmt.Errorf("%s の go-import タグ: %w", importPath, err)
+	}
+
+	root := &RepoRoot{
+		ImportPrefix: imp.prefix,
+		VCS:          imp.vcs,
+		Root:         imp.root,
+		SubDir:       imp.subDir,
+	}
+
+	doc.Find(`meta[name="go-source"]`).EachWithBreak(func(i int, sel *goquery.Selection) bool {
+		content, _ := sel.Attr("content")
+		fields := strings.Fields(content)
+		if len(fields) != 4 || fields[0] != imp.prefix {
+			return true
+		}
+		root.SourceHome, root.SourceDir, root.SourceFile = sourceField(fields[1]), sourceField(fields[2]), sourceField(fields[3])
+		return false
+	})
+
+	root.RepoURL = browsableRepoURL(root)
+	return root, nil
+}
+
+// selectGoImport はインポートパスに一致する go-import タグから使用するものを選びます
+// モジュールプロキシ（mod）とリポジトリの両方がある場合は、ソースコードを参照するためリポジトリを優先します
+func selectGoImport(imports []goImport) (goImport, error) {
+	var repos []goImport
+	for _, imp := range imports {
+		if imp.vcs != VCSMod {
+			repos = append(repos, imp)
+		}
+	}
+
+	switch {
+	case len(repos) == 1:
+		return repos[0], nil
+	case len(repos) > 1:
+		return goImport{}, fmt.Errorf("一致するタグが複数あります（%s、%s）", repos[0].root, repos[1].root)
+	case len(imports) > 0:
+		return imports[0], nil
+	}
+	return goImport{}, fmt.Errorf("一致するタグが見つかりません")
+}
+
+// sourceField は go-source タグの値を返します（"_" は未指定を表します）
+func sourceField(value string) string {
+	if value == "_" {
+		return ""
+	}
+	return value
+}
+
+// browsableRepoURL はソースコードを取得できるリポジトリの URL を返します
+// go-import のリポジトリが GitHub や GitLab でない場合（gopkg.in など）は、go-source のホームページから求めます
+func browsableRepoURL(root *RepoRoot) string {
+	candidates := []string{root.Root, root.SourceHome, root.SourceDir}
+	for _, candidate := range candidates {
+		importPath := strings.TrimPrefix(strings.TrimPrefix(candidate, "https://"), "http://")
+		if repoURL := repoURLFromImportPath(strings.TrimSuffix(importPath, ".git")); repoURL != "" {
+			return repoURL
+		}
+	}
+	return strings.TrimSuffix(root.Root, ".git")
+}
+
+// hasPathPrefix はインポートパスが要素単位で接頭辞に一致するかを判定します
+func hasPathPrefix(importPath string, prefix string) bool {
+	return importPath == prefix || strings.HasPrefix(importPath, prefix+"/")
+}
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goImportPages はインポートパスの接頭辞ごとの ?go-get=1 のレスポンスに含める meta タグです
+var goImportPages = map[string]string{
+	"go.uber.org/zap": `<meta name="go-import" content="go.uber.org/zap git https://github.com/uber-go/zap">
+<meta name="go-source" content="go.uber.org/zap https://github.com/uber-go/zap https://github.com/uber-go/zap/tree/master{/dir} https://github.com/uber-go/zap/tree/master{/dir}/{file}#L{line}">`,
+	"gopkg.in/yaml.v3": `<meta name="go-import" content="gopkg.in/yaml.v3 git https://gopkg.in/yaml.v3">
+<meta name="go-source" content="gopkg.in/yaml.v3 _ https://github.com/go-yaml/yaml/tree/v3.0.1{/dir} https://github.com/go-yaml/yaml/blob/v3.0.1{/dir}/{file}#L{line}">`,
+	"corp.example.com/platform": `<meta name="go-import" content="corp.example.com/platform mod https://goproxy.corp.example.com">
+<meta name="go-import" content="corp.example.com/platform git https://git.corp.example.com/platform/monorepo.git go/platform">
+<meta name="go-import" content="corp.example.com/other git https://git.corp.example.com/other.git">`,
+	"corp.example.com/ambiguous": `<meta name="go-import" content="corp.example.com/ambiguous git https://git.corp.example.com/a.git">
+<meta name="go-import" content="corp.example.com/ambiguous hg https://hg.corp.example.com/a">`,
+}
+
+// newGoImportServer は go-import タグを返すサーバーを起動し、リクエスト数を数えるカウンタを返します
+// 接頭辞以下のどのパスにも、go コマンドが期待するのと同じく接頭辞の meta タグを返します
+func newGoImportServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Query().Get("go-get") != "1" {
+			http.Error(w, "go-get=1 がありません", http.StatusBadRequest)
+			return
+		}
+
+		importPath := strings.TrimPrefix(r.URL.Path, "/")
+		for prefix, meta := range goImportPages {
+			if hasPathPrefix(importPath, prefix) {
+				fmt.Fprintf(w, "<!DOCTYPE html>\n<html><head>\n%s\n</head><body>go get %s</body></html>\n", meta, importPath)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestDiscoverRepoRoot(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server, _ := newGoImportServer(t)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.goGetBaseURL = server.URL
+
+	tests := []struct {
+		name       string
+		importPath string
+		expected   RepoRoot
+		packageDir string
+	}{
+		{
+			name:       "バニティドメインのサブパッケージ",
+			importPath: "go.uber.org/zap/zapcore",
+			expected: RepoRoot{
+				ImportPrefix: "go.uber.org/zap",
+				VCS:          "git",
+				Root:         "https://github.com/uber-go/zap",
+				RepoURL:      "https://github.com/uber-go/zap",
+				SourceHome:   "https://github.com/uber-go/zap",
+				SourceDir:    "https://github.com/uber-go/zap/tree/master{/dir}",
+				SourceFile:   "https://github.com/uber-go/zap/tree/master{/dir}/{file}#L{line}",
+			},
+			packageDir: "zapcore",
+		},
+		{
+			name:       "go-source からリポジトリを求める",
+			importPath: "gopkg.in/yaml.v3",
+			expected: RepoRoot{
+				ImportPrefix: "gopkg.in/yaml.v3",
+				VCS:          "git",
+				Root:         "https://gopkg.in/yaml.v3",
+				RepoURL:      "https://github.com/go-yaml/yaml",
+				SourceDir:    "https://github.com/go-yaml/yaml/tree/v3.0.1{/dir}",
+				SourceFile:   "https://github.com/go-yaml/yaml/blob/v3.0.1{/dir}/{file}#L{line}",
+			},
+			packageDir: "",
+		},
+		{
+			name:       "モジュールプロキシよりリポジトリを優先し、サブディレクトリを考慮する",
+			importPath: "corp.example.com/platform/auth",
+			expected: RepoRoot{
+				ImportPrefix: "corp.example.com/platform",
+				VCS:          "git",
+				Root:         "https://git.corp.example.com/platform/monorepo.git",
+				SubDir:       "go/platform",
+				RepoURL:      "https://git.corp.example.com/platform/monorepo",
+			},
+			packageDir: "go/platform/auth",
+		},
+		{
+			name:       "ホスティングサービスはパスから求める",
+			importPath: "github.com/stretchr/testify/assert",
+			expected: RepoRoot{
+				ImportPrefix: "github.com/stretchr/testify",
+				VCS:          "git",
+				Root:         "https://github.com/stretchr/testify",
+				RepoURL:      "https://github.com/stretchr/testify",
+			},
+			packageDir: "assert",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := f.DiscoverRepoRoot(tt.importPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, *root)
+			assert.Equal(t, tt.packageDir, root.PackageDir(tt.importPath))
+		})
+	}
+
+	for _, importPath := range []string{"corp.example.com/ambiguous/pkg", "corp.example.com/missing"} {
+		_, err := f.DiscoverRepoRoot(importPath)
+		assert.Error(t, err, importPath)
+	}
+}
+
+func TestDiscoverRepoRootUsesCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server, hits := newGoImportServer(t)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.goGetBaseURL = server.URL
+
+	for i := 0; i < 2; i++ {
+		_, err := f.DiscoverRepoRoot("go.uber.org/zap")
+		require.NoError(t, err)
+	}
+	_, err = f.DiscoverRepoRoot("github.com/spf13/cobra")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), hits.Load(), "同じインポートパスとホスティングサービスではリクエストしないこと")
+}
+
+func TestGetPackageInfoDiscoversRepoURL(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server, _ := newGoImportServer(t)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.goGetBaseURL = server.URL
+	f.scraper = newFixtureScraper(t, map[string]string{
+		"/go.uber.org/zap":                    "legacy-layout.html",
+		"/corp.example.com/missing":           "legacy-layout.html",
+		"/github.com/stretchr/testify/assert": "testify-assert.html",
+	})
+
+	pkg, err := f.getPackageInfo("go.uber.org/zap", "latest")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/uber-go/zap", pkg.RepoURL, "pkg.go.dev にリンクがなくても go-import から解決すること")
+
+	pkg, err = f.getPackageInfo("corp.example.com/missing", "latest")
+	require.NoError(t, err, "ディスカバリーに失敗しても pkg.go.dev の情報を使うこと")
+	assert.Empty(t, pkg.RepoURL)
+
+	pkg, err = f.getPackageInfo("github.com/stretchr/testify/assert", "latest")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/stretchr/testify", pkg.RepoURL)
+}
@@ -0,0 +1,154 @@
+// Package graph は依存グラフを Mermaid や DOT 形式で出力する機能を提供します
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// GraphFormatMermaid は Mermaid 形式のグラフ出力を表します
+	GraphFormatMermaid = "mermaid"
+	// GraphFormatDOT は Graphviz の DOT 形式のグラフ出力を表します
+	GraphFormatDOT = "dot"
+	// GraphFormatJSON は JSON 形式のグラフ出力を表します
+	GraphFormatJSON = "json"
+)
+
+// GraphNode はグラフのノードを表す構造体です
+type GraphNode struct {
+	// ノードの識別子（インポートパスなど）
+	ID string `json:"id"`
+	// 表示名
+	Label string `json:"label"`
+	// ノードの種類（依存グラフの場合のみ、NodeKind* のいずれか）
+	Kind string `json:"kind,omitempty"`
+	// ノードが属するモジュールのパス
+	Module string `json:"module,omitempty"`
+	// モジュールのバージョン
+	Version string `json:"version,omitempty"`
+	// ライセンス（SPDX 識別子）
+	License string `json:"license,omitempty"`
+}
+
+// GraphEdge はグラフの有向辺を表す構造体です
+type GraphEdge struct {
+	// 辺の始点のノードID
+	From string `json:"from"`
+	// 辺の終点のノードID
+	To string `json:"to"`
+	// 辺の種類（依存グラフの場合のみ、EdgeKind* のいずれか）
+	Kind string `json:"kind,omitempty"`
+}
+
+// Graph は有向グラフを表す構造体です
+type Graph struct {
+	// ノード一覧
+	Nodes []GraphNode `json:"nodes"`
+	// 辺の一覧
+	Edges []GraphEdge `json:"edges"`
+}
+
+// Render は指定した形式でグラフを文字列に変換します
+func (g *Graph) Render(format string) (string, error) {
+	switch format {
+	case GraphFormatMermaid:
+		return g.Mermaid(), nil
+	case GraphFormatDOT:
+		return g.DOT(), nil
+	case GraphFormatJSON:
+		data, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("グラフのエンコードに失敗しました: %w", err)
+		}
+		return string(data) + "\n", nil
+	default:
+		return "", fmt.Errorf("不明なグラフ形式です: %s（mermaid, dot または json を指定してください）", format)
+	}
+}
+
+// annotations はノードのバージョンとライセンスを表示用に返します
+func (n GraphNode) annotations() []string {
+	var notes []string
+	if n.Version != "" {
+		notes = append(notes, n.Version)
+	}
+	if n.License != "" {
+		notes = append(notes, n.License)
+	}
+	return notes
+}
+
+// Mermaid はグラフを Mermaid の flowchart 形式に変換します
+// Mermaid のノードIDには記号を使えないため、出現順の連番をIDとして使用します
+func (g *Graph) Mermaid() string {
+	var output strings.Builder
+	output.WriteString("graph TD\n")
+
+	ids := make(map[string]string, len(g.Nodes))
+	kinds := map[string][]string{}
+	var kindOrder []string
+	for i, node := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node.ID] = id
+		label := strings.Join(append([]string{node.Label}, node.annotations()...), "<br/>")
+		output.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", id, strings.ReplaceAll(label, `"`, "#quot;")))
+		if node.Kind != "" {
+			if _, ok := kinds[node.Kind]; !ok {
+				kindOrder = append(kindOrder, node.Kind)
+			}
+			kinds[node.Kind] = append(kinds[node.Kind], id)
+		}
+	}
+	for _, edge := range g.Edges {
+		from, fromOK := ids[edge.From]
+		to, toOK := ids[edge.To]
+		if !fromOK || !toOK {
+			continue
+		}
+		arrow := "-->"
+		if edge.Kind == EdgeKindRequires {
+			arrow = "-.->"
+		}
+		output.WriteString(fmt.Sprintf("    %s %s %s\n", from, arrow, to))
+	}
+
+	// ノードの種類ごとにスタイルを設定
+	for _, kind := range kindOrder {
+		class := strings.ReplaceAll(kind, "-", "")
+		if style, ok := mermaidNodeStyles[kind]; ok {
+			output.WriteString(fmt.Sprintf("    classDef %s %s\n", class, style))
+		}
+		output.WriteString(fmt.Sprintf("    class %s %s\n", strings.Join(kinds[kind], ","), class))
+	}
+
+	return output.String()
+}
+
+// DOT はグラフを Graphviz の DOT 形式に変換します
+func (g *Graph) DOT() string {
+	var output strings.Builder
+	output.WriteString("digraph {\n")
+	output.WriteString("    rankdir=LR;\n")
+	output.WriteString("    node [shape=box];\n")
+
+	for _, node := range g.Nodes {
+		label := strings.Join(append([]string{node.Label}, node.annotations()...), "\n")
+		attrs := fmt.Sprintf("label=%q", label)
+		if style, ok := dotNodeStyles[node.Kind]; ok {
+			attrs += ", " + style
+		}
+		output.WriteString(fmt.Sprintf("    %q [%s];\n", node.ID, attrs))
+	}
+	for _, edge := range g.Edges {
+		if edge.Kind == EdgeKindRequires {
+			output.WriteString(fmt.Sprintf("    %q -> %q [style=dashed];\n", edge.From, edge.To))
+			continue
+		}
+		output.WriteString(fmt.Sprintf("    %q -> %q;\n", edge.From, edge.To))
+	}
+
+	output.WriteString("}\n")
+	return output.String()
+}
@@ -0,0 +1,344 @@
+// Package httpapi は go-pkg-summary の機能を REST API として公開する機能を提供します
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// contentTypeJSON は JSON レスポンスの Content-Type です
+	contentTypeJSON = "application/json; charset=utf-8"
+	// contentTypeMarkdown は Markdown レスポンスの Content-Type です
+	contentTypeMarkdown = "text/markdown; charset=utf-8"
+)
+
+// errRouteNotFound は API のパスに該当するエンドポイントがないことを表すエラーです
+var errRouteNotFound = errors.New("エンドポイントが見つかりません")
+
+// APIServer は Fetcher の機能を REST API として提供する HTTP ハンドラーです
+// 同じパッケージへの同時リクエストは singleflight でまとめ、上流へのアクセスを1回にします
+type APIServer struct {
+	fetcher  *Fetcher
+	resolver *Resolver
+	group    singleflight.Group
+	mux      *http.ServeMux
+}
+
+// NewAPIServer は新しいAPIServerインスタンスを作成します
+// resolver が nil の場合、短いパッケージ名は解決せずにそのまま使用します
+func NewAPIServer(fetcher *Fetcher, resolver *Resolver) *APIServer {
+	s := &APIServer{
+		fetcher:  fetcher,
+		resolver: resolver,
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /search", s.handleSearch)
+	s.mux.HandleFunc("GET /pkg/{rest...}", s.handlePackage)
+	return s
+}
+
+// ServeHTTP は http.Handler を実装します
+func (s *APIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleSearch は GET /search?q=...&limit=...&mode=package|symbol を処理します
+func (s *APIServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("q を指定してください"))
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("無効な limit です: %s", value))
+			return
+		}
+		limit = n
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = SearchModePackage
+	}
+
+	key := fmt.Sprintf("search:%s:%d:%s", mode, limit, query)
+	switch mode {
+	case SearchModePackage:
+		result, err := s.do(key, func() (any, error) {
+			return s.fetcher.SearchPackage(query, limit)
+		})
+		if err != nil {
+			s.writeError(w, r, http.StatusBadGateway, err)
+			return
+		}
+		packages := result.([]Package)
+		if packages == nil {
+			packages = []Package{}
+		}
+		s.write(w, r, SearchOutput{Packages: packages}, func() string {
+			return formatSearchMarkdown(query, packages)
+		})
+	case SearchModeSymbol:
+		result, err := s.do(key, func() (any, error) {
+			return s.fetcher.SearchSymbol(query, limit)
+		})
+		if err != nil {
+			s.writeError(w, r, http.StatusBadGateway, err)
+			return
+		}
+		symbols := result.([]Symbol)
+		if symbols == nil {
+			symbols = []Symbol{}
+		}
+		s.write(w, r, FindOutput{Symbols: symbols}, func() string {
+			return formatSymbolsMarkdown(query, symbols)
+		})
+	default:
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("不明な検索モードです: %s（package または symbol を指定してください）", mode))
+	}
+}
+
+// handlePackage は /pkg/{path}@{v}、/pkg/{path}@{v}/files、/pkg/{path}@{v}/files/{file} を処理します
+func (s *APIServer) handlePackage(w http.ResponseWriter, r *http.Request) {
+	route, err := parsePackageRoute(r.PathValue("rest"))
+	if errors.Is(err, errRouteNotFound) {
+		s.writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	importPath, err := s.resolver.ResolveIfShort(route.importPath, SearchModePackage)
+	if err != nil {
+		var ambiguous *AmbiguousPackageError
+		if errors.As(err, &ambiguous) {
+			s.writeError(w, r, http.StatusConflict, err)
+		} else {
+			s.writeError(w, r, http.StatusBadGateway, err)
+		}
+		return
+	}
+	version := route.version
+
+	switch {
+	case route.file != "":
+		s.handleFile(w, r, importPath, version, route.file)
+	case route.files:
+		result, err := s.do("files:"+importPath+"@"+version, func() (any, error) {
+			return s.fetcher.ListPackageFiles(importPath, version)
+		})
+		if err != nil {
+			s.writeError(w, r, http.StatusBadGateway, err)
+			return
+		}
+		files := result.([]string)
+		if files == nil {
+			files = []string{}
+		}
+		s.write(w, r, ListOutput{Package: importPath, Version: version, Files: files}, func() string {
+			return formatFilesMarkdown(importPath, version, files)
+		})
+	default:
+		result, err := s.do("summary:"+importPath+"@"+version, func() (any, error) {
+			return summarize(s.fetcher, importPath, version, true)
+		})
+		if err != nil {
+			s.writeError(w, r, http.StatusBadGateway, err)
+			return
+		}
+		out := result.(SummaryOutput)
+		s.write(w, r, out, func() string {
+			return out.Content
+		})
+	}
+}
+
+// handleFile はファイルの内容を返します（?lines=L10-L80 で行範囲を指定できます）
+func (s *APIServer) handleFile(w http.ResponseWriter, r *http.Request, importPath string, version string, file string) {
+	var lines *LineRange
+	if value := r.URL.Query().Get("lines"); value != "" {
+		lr, err := ParseLineRange(value)
+		if err != nil {
+			s.writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		lines = &lr
+	}
+
+	result, err := s.do("file:"+importPath+"@"+version+":"+file, func() (any, error) {
+		return s.fetcher.ReadPackageFile(importPath, version, file)
+	})
+	if err != nil {
+		s.writeError(w, r, http.StatusBadGateway, err)
+		return
+	}
+
+	content := result.(string)
+	if lines != nil {
+		content, err = lines.Apply(content)
+		if err != nil {
+			s.writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	s.write(w, r, ReadOutput{Package: importPath, Version: version, File: file, Lines: lines, Content: content}, func() string {
+		return formatFileMarkdown(file, content)
+	})
+}
+
+// do は同じキーの同時実行をまとめて、上流へのアクセスを1回にします
+func (s *APIServer) do(key string, fn func() (any, error)) (any, error) {
+	result, err, shared := s.group.Do(key, fn)
+	if shared && s.fetcher.debug {
+		fmt.Printf("同時リクエストの結果を共有しました: %s\n", key)
+	}
+	return result, err
+}
+
+// write は Accept ヘッダーに応じて JSON または Markdown でレスポンスを書き込みます
+func (s *APIServer) write(w http.ResponseWriter, r *http.Request, value any, markdown func() string) {
+	if prefersMarkdown(r) {
+		w.Header().Set("Content-Type", contentTypeMarkdown)
+		_, _ = w.Write([]byte(markdown()))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
+
+// writeError は Accept ヘッダーに応じて JSON または Markdown でエラーを書き込みます
+func (s *APIServer) writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if prefersMarkdown(r) {
+		w.Header().Set("Content-Type", contentTypeMarkdown)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(fmt.Sprintf("エラー: %v\n", err)))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// prefersMarkdown は Accept ヘッダーで JSON より Markdown が優先されているかを判定します
+// 指定がない場合は JSON とみなします
+func prefersMarkdown(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json":
+			return false
+		case "text/markdown", "text/plain":
+			return true
+		}
+	}
+	return false
+}
+
+// packageRoute は /pkg/ 以下のパスを解析した結果です
+type packageRoute struct {
+	importPath string
+	version    string
+	// ファイル一覧を要求しているかどうか
+	files bool
+	// 要求しているファイルのパス
+	file string
+}
+
+// parsePackageRoute は "{path}@{v}"、"{path}@{v}/files"、"{path}@{v}/files/{file}" 形式のパスを解析します
+// バージョンを省略した "{path}" は最新バージョンの概要として扱います
+func parsePackageRoute(rest string) (packageRoute, error) {
+	importPath, tail, hasVersion := strings.Cut(rest, "@")
+	importPath = strings.Trim(importPath, "/")
+	if importPath == "" {
+		return packageRoute{}, fmt.Errorf("パッケージパスを指定してください")
+	}
+	if !hasVersion {
+		return packageRoute{importPath: importPath, version: "latest"}, nil
+	}
+
+	version, sub, _ := strings.Cut(tail, "/")
+	if version == "" {
+		return packageRoute{}, fmt.Errorf("バージョンを指定してください")
+	}
+
+	route := packageRoute{importPath: importPath, version: version}
+	switch {
+	case sub == "":
+	case sub == "files":
+		route.files = true
+	case strings.HasPrefix(sub, "files/") && len(sub) > len("files/"):
+		route.file = strings.TrimPrefix(sub, "files/")
+	default:
+		return packageRoute{}, fmt.Errorf("%w: %s", errRouteNotFound, sub)
+	}
+	return route, nil
+}
+
+// formatSearchMarkdown はパッケージの検索結果を Markdown に整形します
+func formatSearchMarkdown(query string, packages []Package) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("# 検索結果: %s\n\n", query))
+	for _, pkg := range packages {
+		output.WriteString(fmt.Sprintf("- %s（インポート数: %d）\n", pkg.ImportPath, pkg.ImportedBy))
+		if pkg.Synopsis != "" {
+			output.WriteString(fmt.Sprintf("  %s\n", pkg.Synopsis))
+		}
+	}
+	return output.String()
+}
+
+// formatSymbolsMarkdown はシンボルの検索結果を Markdown に整形します
+func formatSymbolsMarkdown(query string, symbols []Symbol) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("# 検索結果: %s\n\n", query))
+	for _, symbol := range symbols {
+		output.WriteString(fmt.Sprintf("- %s.%s（%s）\n", symbol.PackagePath, symbol.Name, symbol.Kind))
+		if symbol.Signature != "" {
+			output.WriteString(fmt.Sprintf("  `%s`\n", symbol.Signature))
+		}
+	}
+	return output.String()
+}
+
+// formatFilesMarkdown はファイル一覧を Markdown に整形します
+func formatFilesMarkdown(importPath string, version string, files []string) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("# %s@%s のファイル一覧\n\n", importPath, version))
+	for _, file := range files {
+		output.WriteString(fmt.Sprintf("- %s\n", file))
+	}
+	return output.String()
+}
+
+// formatFileMarkdown はファイルの内容を Markdown に整形します
+// Markdown ファイルはそのまま、それ以外は拡張子を言語としたコードブロックにします
+func formatFileMarkdown(file string, content string) string {
+	ext := strings.TrimPrefix(path.Ext(file), ".")
+	if ext == "md" {
+		return content
+	}
+	return fmt.Sprintf("```%s\n%s\n```\n", ext, strings.TrimRight(content, "\n"))
+}
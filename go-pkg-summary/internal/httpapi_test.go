@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAPIServer はフィクスチャに接続したAPIサーバーを起動します
+func newTestAPIServer(t *testing.T, scraper *Scraper) *httptest.Server {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.scraper = scraper
+
+	server := httptest.NewServer(NewAPIServer(f, nil))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// get は Accept ヘッダーを指定して GET リクエストを送信します
+func get(t *testing.T, url string, accept string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestParsePackageRoute(t *testing.T) {
+	tests := []struct {
+		rest    string
+		want    packageRoute
+		wantErr bool
+	}{
+		{rest: "go.uber.org/zap", want: packageRoute{importPath: "go.uber.org/zap", version: "latest"}},
+		{rest: "go.uber.org/zap@v1.27.0", want: packageRoute{importPath: "go.uber.org/zap", version: "v1.27.0"}},
+		{rest: "go.uber.org/zap@latest/files", want: packageRoute{importPath: "go.uber.org/zap", version: "latest", files: true}},
+		{rest: "github.com/stretchr/testify@v1.10.0/files/assert/assertions.go", want: packageRoute{importPath: "github.com/stretchr/testify", version: "v1.10.0", file: "assert/assertions.go"}},
+		{rest: "go.uber.org/zap@v1.27.0/other", wantErr: true},
+		{rest: "go.uber.org/zap@/files", wantErr: true},
+		{rest: "@v1.0.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rest, func(t *testing.T) {
+			got, err := parsePackageRoute(tt.rest)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAPISearch(t *testing.T) {
+	server := newTestAPIServer(t, newFixtureScraper(t, map[string]string{
+		"/search":                 "search-zap.html",
+		"/search?m=symbol":        "search-symbol-newlogger.html",
+		"/search?m=symbol&page=2": "search-empty.html",
+	}))
+
+	t.Run("JSON", func(t *testing.T) {
+		resp := get(t, server.URL+"/search?q=zap&limit=2", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, contentTypeJSON, resp.Header.Get("Content-Type"), "Accept の指定がない場合は JSON を返すこと")
+
+		var out SearchOutput
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		require.Len(t, out.Packages, 2)
+		assert.Equal(t, "go.uber.org/zap", out.Packages[0].ImportPath)
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		resp := get(t, server.URL+"/search?q=zap&limit=2", "text/markdown")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, contentTypeMarkdown, resp.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "- go.uber.org/zap（インポート数: 30412）")
+	})
+
+	t.Run("シンボル", func(t *testing.T) {
+		resp := get(t, server.URL+"/search?q=NewLogger&mode=symbol", "application/json")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var out FindOutput
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		require.Len(t, out.Symbols, 2)
+		assert.Equal(t, "Logger.Sugar", out.Symbols[1].Name)
+	})
+}
+
+func TestAPIErrors(t *testing.T) {
+	server := newTestAPIServer(t, newFixtureScraper(t, map[string]string{}))
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "q なし", path: "/search", status: http.StatusBadRequest},
+		{name: "無効な limit", path: "/search?q=zap&limit=x", status: http.StatusBadRequest},
+		{name: "不明なモード", path: "/search?q=zap&mode=other", status: http.StatusBadRequest},
+		{name: "不明なサブパス", path: "/pkg/go.uber.org/zap@v1.27.0/other", status: http.StatusNotFound},
+		{name: "無効な行範囲", path: "/pkg/go.uber.org/zap@v1.27.0/files/go.mod?lines=L9-L1", status: http.StatusBadRequest},
+		{name: "上流のエラー", path: "/pkg/go.uber.org/zap@v1.27.0", status: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := get(t, server.URL+tt.path, "")
+			assert.Equal(t, tt.status, resp.StatusCode)
+
+			var out map[string]string
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+			assert.NotEmpty(t, out["error"], "エラーメッセージが JSON で返ること")
+		})
+	}
+}
+
+func TestAPICoalescesConcurrentRequests(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "pkggodev", "search-zap.html"))
+	require.NoError(t, err)
+
+	// 上流へのリクエスト数を数え、全てのリクエストが揃うまで応答を保留する
+	var upstreamHits atomic.Int32
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamHits.Add(1)
+		<-release
+		_, _ = w.Write(fixture)
+	}))
+	t.Cleanup(upstream.Close)
+
+	scraper := NewScraper(false)
+	scraper.baseURL = upstream.URL
+	server := newTestAPIServer(t, scraper)
+
+	const concurrency = 5
+	var wg sync.WaitGroup
+	statuses := make([]int, concurrency)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := http.Get(server.URL + "/search?q=zap&limit=1")
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			statuses[i] = resp.StatusCode
+		}(i)
+	}
+
+	// 最初のリクエストが上流に届いてから、残りのリクエストが合流するのを待つ
+	require.Eventually(t, func() bool { return upstreamHits.Load() == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), upstreamHits.Load(), "同時リクエストは上流へのアクセス1回にまとめられること")
+	for _, status := range statuses {
+		assert.Equal(t, http.StatusOK, status)
+	}
+}
@@ -0,0 +1,292 @@
+// Package license はモジュールのライセンスファイルからライセンスを検出し、許可リストに照らして判定する機能を提供します
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/licensecheck"
+	"golang.org/x/mod/modfile"
+)
+
+const (
+	// licenseCoverageThreshold はライセンスとみなすために必要な、ファイル全体に対する一致率（%）です
+	licenseCoverageThreshold = 75
+	// LicenseUnknown はライセンスファイルの内容を判別できなかったことを表します
+	LicenseUnknown = "UNKNOWN"
+)
+
+// licenseFilePrefixes はライセンスファイルとみなすファイル名の接頭辞です（大文字で比較）
+var licenseFilePrefixes = []string{"LICENSE", "LICENCE", "COPYING", "MIT-LICENSE"}
+
+// DetectedLicense は1つのライセンスファイルから検出したライセンスを表す構造体です
+type DetectedLicense struct {
+	// モジュールのルートからのファイルパス
+	File string `json:"file"`
+	// SPDX 識別子（判別できない場合は UNKNOWN）
+	IDs []string `json:"ids"`
+	// ファイル全体に対するライセンス本文の一致率（%）
+	Coverage float64 `json:"coverage"`
+}
+
+// ModuleLicenses はモジュールのライセンスを表す構造体です
+type ModuleLicenses struct {
+	// モジュールパス
+	ModulePath string `json:"modulePath"`
+	// バージョン
+	Version string `json:"version"`
+	// ライセンスファイルごとの検出結果
+	Files []DetectedLicense `json:"files"`
+}
+
+// IDs は検出した SPDX 識別子を重複なしでソートして返します
+func (m *ModuleLicenses) IDs() []string {
+	seen := map[string]bool{}
+	var ids []string
+	for _, file := range m.Files {
+		for _, id := range file.IDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// String はライセンスを表示用の文字列に変換します（例: "MIT（LICENSE）"）
+func (m *ModuleLicenses) String() string {
+	ids := m.IDs()
+	if len(ids) == 0 {
+		return ""
+	}
+	files := make([]string, 0, len(m.Files))
+	for _, file := range m.Files {
+		files = append(files, file.File)
+	}
+	return fmt.Sprintf("%s（%s）", strings.Join(ids, ", "), strings.Join(files, ", "))
+}
+
+// GetModuleLicenses はインポートパスを含むモジュールを取得し、ライセンスを検出します
+// useCache が false の場合はキャッシュしたモジュールの zip を使用せずに取得し直します
+func (f *Fetcher) GetModuleLicenses(importPath string, version string, useCache bool) (*ModuleLicenses, error) {
+	modulePath, resolvedVersion, err := f.ResolveModuleVersion(importPath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := f.DownloadModule(modulePath, resolvedVersion, useCache)
+	if err != nil {
+		return nil, err
+	}
+	return DetectLicenses(source)
+}
+
+// DetectLicenses はモジュールのルートにある LICENSE や COPYING などのファイルからライセンスを検出します
+func DetectLicenses(source *ModuleSource) (*ModuleLicenses, error) {
+	entries, err := fs.ReadDir(source.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("モジュールのファイル一覧の取得に失敗しました: %w", err)
+	}
+
+	result := &ModuleLicenses{ModulePath: source.ModulePath, Version: source.Version}
+	for _, entry := range entries {
+		if entry.IsDir() || !isLicenseFileName(entry.Name()) {
+			continue
+		}
+
+		data, err := fs.ReadFile(source.FS, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("ファイル %s の読み込みに失敗しました: %w", entry.Name(), err)
+		}
+		result.Files = append(result.Files, classifyLicense(entry.Name(), data))
+	}
+	return result, nil
+}
+
+// isLicenseFileName はファイル名がライセンスファイルのものかを判定します
+// "LICENSE"、"LICENSE.md"、"LICENSE-APACHE" のように接頭辞の直後が区切り文字のものを対象とし、Go のソースファイルは除外します
+func isLicenseFileName(name string) bool {
+	if path.Ext(name) == ".go" {
+		return false
+	}
+
+	upper := strings.ToUpper(name)
+	for _, prefix := range licenseFilePrefixes {
+		rest, ok := strings.CutPrefix(upper, prefix)
+		if ok && (rest == "" || strings.ContainsRune(".-_", rune(rest[0]))) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyLicense はライセンスファイルの本文からライセンスを判別します
+// 一致率がしきい値に満たない場合は、独自の条項を含む可能性があるため UNKNOWN とします
+func classifyLicense(name string, data []byte) DetectedLicense {
+	coverage := licensecheck.Scan(data)
+	detected := DetectedLicense{File: name, Coverage: coverage.Percent}
+	if coverage.Percent < licenseCoverageThreshold {
+		detected.IDs = []string{LicenseUnknown}
+		return detected
+	}
+
+	seen := map[string]bool{}
+	for _, match := range coverage.Match {
+		if !seen[match.ID] {
+			seen[match.ID] = true
+			detected.IDs = append(detected.IDs, match.ID)
+		}
+	}
+	return detected
+}
+
+// LicensePolicy はライセンスの許可リストと拒否リストを表す構造体です
+// 各要素は SPDX 識別子で、"GPL-*" のように path.Match のパターンを使用できます
+type LicensePolicy struct {
+	// 許可するライセンス（空の場合は拒否リストにないものを全て許可）
+	Allow []string `json:"allow,omitempty"`
+	// 拒否するライセンス
+	Deny []string `json:"deny,omitempty"`
+}
+
+// LoadLicensePolicy は JSON ファイルからライセンスポリシーを読み込みます
+func LoadLicensePolicy(filePath string) (*LicensePolicy, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("ライセンスポリシーの読み込みに失敗しました: %w", err)
+	}
+
+	var policy LicensePolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("ライセンスポリシーのパースに失敗しました: %w", err)
+	}
+	return &policy, nil
+}
+
+// Check はライセンスがポリシーで許可されているかを判定し、許可されていない場合は理由を返します
+// 複数のライセンスが検出された場合は、全てのライセンスが許可されている必要があります
+func (p *LicensePolicy) Check(ids []string) (bool, string) {
+	if len(ids) == 0 {
+		if len(p.Allow) > 0 {
+			return false, "ライセンスファイルが見つかりません"
+		}
+		return true, ""
+	}
+
+	for _, id := range ids {
+		if matchLicense(p.Deny, id) {
+			return false, fmt.Sprintf("%s は拒否リストに含まれています", id)
+		}
+		if len(p.Allow) > 0 && !matchLicense(p.Allow, id) {
+			return false, fmt.Sprintf("%s は許可リストに含まれていません", id)
+		}
+	}
+	return true, ""
+}
+
+// matchLicense はライセンスがパターンのいずれかに一致するかを判定します
+func matchLicense(patterns []string, id string) bool {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, id); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LicenseReport は依存モジュール1つのライセンスの判定結果を表す構造体です
+type LicenseReport struct {
+	// モジュールパス
+	ModulePath string `json:"modulePath"`
+	// バージョン
+	Version string `json:"version"`
+	// 間接的な依存かどうか
+	Indirect bool `json:"indirect,omitempty"`
+	// 検出した SPDX 識別子
+	Licenses []string `json:"licenses"`
+	// ポリシーで許可されているかどうか
+	Allowed bool `json:"allowed"`
+	// 許可されていない理由、または判定しなかった理由
+	Reason string `json:"reason,omitempty"`
+}
+
+// CheckRequirementLicenses は go.mod の全ての require のライセンスを検出し、ポリシーに照らして判定します
+// replace ディレクティブで別のモジュールに置き換えられている場合は置き換え先を、ローカルのディレクトリの場合は判定の対象外とします
+// useCache が false の場合はキャッシュしたモジュールの zip を使用せずに取得し直します
+func (f *Fetcher) CheckRequirementLicenses(goMod []byte, policy *LicensePolicy, useCache bool) ([]LicenseReport, error) {
+	mf, err := modfile.Parse("go.mod", goMod, nil)
+	if err != nil {
+		return nil, fmt.Errorf("go.mod のパースに失敗しました: %w", err)
+	}
+
+	reports := make([]LicenseReport, len(mf.Require))
+	sem := make(chan struct{}, versionInfoConcurrency)
+	var wg sync.WaitGroup
+
+	for i, req := range mf.Require {
+		report := LicenseReport{ModulePath: req.Mod.Path, Version: req.Mod.Version, Indirect: req.Indirect}
+		modulePath, version := req.Mod.Path, req.Mod.Version
+		if replaced := findReplace(mf, modulePath, version); replaced != nil {
+			if replaced.New.Version == "" {
+				report.Allowed = true
+				report.Reason = fmt.Sprintf("ローカルのディレクトリ %s に置き換えられています", replaced.New.Path)
+				reports[i] = report
+				continue
+			}
+			modulePath, version = replaced.New.Path, replaced.New.Version
+		}
+
+		wg.Add(1)
+		go func(i int, report LicenseReport, modulePath string, version string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			source, err := f.DownloadModule(modulePath, version, useCache)
+			if err == nil {
+				var licenses *ModuleLicenses
+				licenses, err = DetectLicenses(source)
+				if err == nil {
+					report.Licenses = licenses.IDs()
+					report.Allowed, report.Reason = policy.Check(report.Licenses)
+				}
+			}
+			if err != nil {
+				report.Reason = fmt.Sprintf("ライセンスの検出に失敗しました: %v", err)
+			}
+			reports[i] = report
+		}(i, report, modulePath, version)
+	}
+	wg.Wait()
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].ModulePath < reports[j].ModulePath
+	})
+	return reports, nil
+}
+
+// findReplace はモジュールに適用される replace ディレクティブを返します
+// バージョンを指定した replace を、バージョンを指定しないものより優先します
+func findReplace(mf *modfile.File, modulePath string, version string) *modfile.Replace {
+	var found *modfile.Replace
+	for _, r := range mf.Replace {
+		if r.Old.Path != modulePath {
+			continue
+		}
+		if r.Old.Version == version {
+			return r
+		}
+		if r.Old.Version == "" {
+			found = r
+		}
+	}
+	return found
+}
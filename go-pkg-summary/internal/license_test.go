@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readLicenseFixture は testdata/licenses のライセンス本文を読み込みます
+func readLicenseFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "licenses", name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestDetectLicenses(t *testing.T) {
+	files := map[string]string{
+		"go.mod":          "module example.com/lib\n",
+		"LICENSE":         readLicenseFixture(t, "MIT.txt"),
+		"COPYING.txt":     "このソフトウェアは社内での利用に限ります。\n",
+		"licenses/BSD.md": readLicenseFixture(t, "BSD-3-Clause.txt"),
+		"license.go":      "package lib\n",
+	}
+	source, err := NewModuleSourceFromZip("example.com/lib", "v1.0.0", newTestModuleZip(t, "example.com/lib", "v1.0.0", files))
+	require.NoError(t, err)
+
+	licenses, err := DetectLicenses(source)
+	require.NoError(t, err)
+
+	require.Len(t, licenses.Files, 2, "ルートの LICENSE、COPYING で始まるファイルが対象になること")
+	assert.Equal(t, "COPYING.txt", licenses.Files[0].File)
+	assert.Equal(t, []string{LicenseUnknown}, licenses.Files[0].IDs, "判別できない本文は UNKNOWN になること")
+	assert.Equal(t, "LICENSE", licenses.Files[1].File)
+	assert.Equal(t, []string{"MIT"}, licenses.Files[1].IDs)
+	assert.Greater(t, licenses.Files[1].Coverage, 90.0)
+
+	assert.Equal(t, []string{"MIT", "UNKNOWN"}, licenses.IDs())
+	assert.Equal(t, "MIT, UNKNOWN（COPYING.txt, LICENSE）", licenses.String())
+}
+
+func TestIsLicenseFileName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"LICENSE":         true,
+		"LICENSE.md":      true,
+		"License-APACHE":  true,
+		"LICENCE":         true,
+		"COPYING":         true,
+		"MIT-LICENSE.txt": true,
+		"README.md":       false,
+		"license.go":      false,
+		"LICENSING.md":    false,
+		"NOTICE":          false,
+	} {
+		assert.Equal(t, want, isLicenseFileName(name), name)
+	}
+}
+
+func TestLicensePolicyCheck(t *testing.T) {
+	policy := &LicensePolicy{Allow: []string{"MIT", "BSD-*", "Apache-2.0"}, Deny: []string{"BSD-4-Clause"}}
+
+	tests := []struct {
+		name    string
+		ids     []string
+		allowed bool
+		reason  string
+	}{
+		{name: "許可リストに一致", ids: []string{"MIT"}, allowed: true},
+		{name: "パターンに一致", ids: []string{"BSD-3-Clause", "Apache-2.0"}, allowed: true},
+		{name: "拒否リストが優先", ids: []string{"BSD-4-Clause"}, reason: "BSD-4-Clause は拒否リストに含まれています"},
+		{name: "全てのライセンスが許可されている必要がある", ids: []string{"MIT", "GPL-3.0"}, reason: "GPL-3.0 は許可リストに含まれていません"},
+		{name: "ライセンスなし", ids: nil, reason: "ライセンスファイルが見つかりません"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, reason := policy.Check(tt.ids)
+			assert.Equal(t, tt.allowed, allowed)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+
+	allowed, _ := (&LicensePolicy{Deny: []string{"AGPL-*"}}).Check(nil)
+	assert.True(t, allowed, "許可リストがない場合はライセンスなしも許可されること")
+}
+
+func TestLoadLicensePolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"allow": ["MIT"], "deny": ["GPL-*"]}`), 0644))
+
+	policy, err := LoadLicensePolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, &LicensePolicy{Allow: []string{"MIT"}, Deny: []string{"GPL-*"}}, policy)
+
+	_, err = LoadLicensePolicy(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestCheckRequirementLicenses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	zips := map[string][]byte{
+		"/example.com/mit/@v/v1.0.0.zip": newTestModuleZip(t, "example.com/mit", "v1.0.0", map[string]string{
+			"LICENSE": readLicenseFixture(t, "MIT.txt"),
+		}),
+		"/example.com/fork/@v/v1.1.0.zip": newTestModuleZip(t, "example.com/fork", "v1.1.0", map[string]string{
+			"LICENSE": readLicenseFixture(t, "BSD-3-Clause.txt"),
+		}),
+		"/example.com/nolicense/@v/v0.1.0.zip": newTestModuleZip(t, "example.com/nolicense", "v0.1.0", map[string]string{
+			"go.mod": "module example.com/nolicense\n",
+		}),
+	}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := zips[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(proxy.Close)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.proxyURL = proxy.URL
+
+	goMod := []byte(`module example.com/app
+
+go 1.22
+
+require (
+	example.com/mit v1.0.0
+	example.com/original v1.0.0
+	example.com/local v0.0.0
+	example.com/nolicense v0.1.0 // indirect
+	example.com/missing v1.0.0 // indirect
+)
+
+replace example.com/original => example.com/fork v1.1.0
+
+replace example.com/local => ../local
+`)
+
+	reports, err := f.CheckRequirementLicenses(goMod, &LicensePolicy{Allow: []string{"MIT", "BSD-3-Clause"}}, true)
+	require.NoError(t, err)
+	require.Len(t, reports, 5)
+
+	assert.Equal(t, LicenseReport{ModulePath: "example.com/local", Version: "v0.0.0", Allowed: true, Reason: "ローカルのディレクトリ ../local に置き換えられています"}, reports[0])
+	assert.Equal(t, "example.com/missing", reports[1].ModulePath)
+	assert.False(t, reports[1].Allowed, "取得できないモジュールは許可しないこと")
+	assert.Contains(t, reports[1].Reason, "ライセンスの検出に失敗しました")
+	assert.Equal(t, LicenseReport{ModulePath: "example.com/mit", Version: "v1.0.0", Licenses: []string{"MIT"}, Allowed: true}, reports[2])
+	assert.Equal(t, LicenseReport{ModulePath: "example.com/nolicense", Version: "v0.1.0", Indirect: true, Allowed: false, Reason: "ライセンスファイルが見つかりません"}, reports[3])
+	assert.Equal(t, LicenseReport{ModulePath: "example.com/original", Version: "v1.0.0", Licenses: []string{"BSD-3-Clause"}, Allowed: true}, reports[4], "replace の置き換え先のライセンスを検出すること")
+
+	_, err = f.CheckRequirementLicenses([]byte("module"), &LicensePolicy{}, true)
+	assert.Error(t, err)
+}
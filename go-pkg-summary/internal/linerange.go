@@ -0,0 +1,62 @@
+// Package linerange はファイル内の行範囲の指定と切り出し機能を提供します
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lineRangePattern は "L10-L80" または "L10" 形式の行範囲に一致する正規表現です
+var lineRangePattern = regexp.MustCompile(`^L(\d+)(?:-L?(\d+))?$`)
+
+// LineRange はファイル内の行範囲（1始まり、終端を含む）を表す構造体です
+type LineRange struct {
+	// 開始行
+	Start int `json:"start"`
+	// 終了行
+	End int `json:"end"`
+}
+
+// ParseLineRange は "L10-L80" または "L10" 形式の文字列から行範囲を解析します
+func ParseLineRange(text string) (LineRange, error) {
+	m := lineRangePattern.FindStringSubmatch(strings.TrimPrefix(text, "#"))
+	if m == nil {
+		return LineRange{}, fmt.Errorf("無効な行範囲です: %s（#L10-L80 の形式で指定してください）", text)
+	}
+
+	start, _ := strconv.Atoi(m[1])
+	end := start
+	if m[2] != "" {
+		end, _ = strconv.Atoi(m[2])
+	}
+
+	if start < 1 || end < start {
+		return LineRange{}, fmt.Errorf("無効な行範囲です: %s", text)
+	}
+	return LineRange{Start: start, End: end}, nil
+}
+
+// Apply はコンテンツから行範囲の部分を切り出します
+// 終了行がファイルの行数を超える場合はファイルの末尾までを返します
+func (r LineRange) Apply(content string) (string, error) {
+	lines := strings.Split(content, "\n")
+	if r.Start > len(lines) {
+		return "", fmt.Errorf("開始行 %d がファイルの行数 %d を超えています", r.Start, len(lines))
+	}
+
+	end := r.End
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return strings.Join(lines[r.Start-1:end], "\n"), nil
+}
+
+// String は "L10-L80" 形式の文字列を返します
+func (r LineRange) String() string {
+	if r.Start == r.End {
+		return fmt.Sprintf("L%d", r.Start)
+	}
+	return fmt.Sprintf("L%d-L%d", r.Start, r.End)
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LineRange
+		wantErr bool
+	}{
+		{input: "#L10-L80", want: LineRange{Start: 10, End: 80}},
+		{input: "L10-80", want: LineRange{Start: 10, End: 80}},
+		{input: "L7", want: LineRange{Start: 7, End: 7}},
+		{input: "L0", wantErr: true},
+		{input: "L80-L10", wantErr: true},
+		{input: "10-80", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLineRange(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLineRangeApply(t *testing.T) {
+	content := "one\ntwo\nthree\nfour"
+
+	got, err := LineRange{Start: 2, End: 3}.Apply(content)
+	require.NoError(t, err)
+	assert.Equal(t, "two\nthree", got)
+
+	got, err = LineRange{Start: 3, End: 100}.Apply(content)
+	require.NoError(t, err)
+	assert.Equal(t, "three\nfour", got, "終了行がファイルの行数を超える場合は末尾までを返すこと")
+
+	_, err = LineRange{Start: 10, End: 20}.Apply(content)
+	assert.Error(t, err)
+}
@@ -0,0 +1,294 @@
+// Package mcp は go-pkg-summary の機能を Model Context Protocol のツールとして公開する機能を提供します
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// MCPServerName は MCP サーバーの実装名です
+	MCPServerName = "go-pkg-summary"
+	// defaultSearchLimit は検索で件数が指定されなかった場合の件数です
+	defaultSearchLimit = 10
+)
+
+// SearchInput は search ツールと find ツールの入力です
+type SearchInput struct {
+	Query string `json:"query" jsonschema:"検索する名前（例: zap, NewLogger）"`
+	Limit int    `json:"limit,omitempty" jsonschema:"取得する最大件数（既定: 10）"`
+}
+
+// SearchOutput は search ツールの出力です
+type SearchOutput struct {
+	Packages []Package `json:"packages"`
+}
+
+// FindOutput は find ツールの出力です
+type FindOutput struct {
+	Symbols []Symbol `json:"symbols"`
+}
+
+// PackageInput はパッケージを指定するツールの入力です
+type PackageInput struct {
+	Package string `json:"package" jsonschema:"インポートパス（例: go.uber.org/zap）。スラッシュを含まない短い名前は検索して解決する"`
+	Version string `json:"version,omitempty" jsonschema:"バージョン（既定: latest）"`
+}
+
+// SummaryInput は summary ツールの入力です
+type SummaryInput struct {
+	PackageInput
+	NoCache bool `json:"noCache,omitempty" jsonschema:"キャッシュを使用しない"`
+}
+
+// SummaryOutput は summary ツールの出力です
+type SummaryOutput struct {
+	Package string          `json:"package"`
+	Version string          `json:"version"`
+	License *ModuleLicenses `json:"license,omitempty"`
+	Content string          `json:"content"`
+}
+
+// ListOutput は ls ツールの出力です
+type ListOutput struct {
+	Package string   `json:"package"`
+	Version string   `json:"version"`
+	Files   []string `json:"files"`
+}
+
+// ReadInput は read ツールの入力です
+type ReadInput struct {
+	PackageInput
+	File   string `json:"file,omitempty" jsonschema:"リポジトリのルートからのファイルパス（symbol と排他）"`
+	Lines  string `json:"lines,omitempty" jsonschema:"行範囲（例: L10-L80）"`
+	Symbol string `json:"symbol,omitempty" jsonschema:"シンボル名（例: Logger, Logger.Sugar）。file と排他"`
+}
+
+// ReadOutput は read ツールの出力です
+type ReadOutput struct {
+	Package string     `json:"package"`
+	Version string     `json:"version"`
+	File    string     `json:"file"`
+	Lines   *LineRange `json:"lines,omitempty"`
+	Content string     `json:"content"`
+}
+
+// VersionsInput は versions ツールの入力です
+type VersionsInput struct {
+	Package string `json:"package" jsonschema:"インポートパス（例: go.uber.org/zap）"`
+}
+
+// mcpHandler は MCP ツールの処理を行う構造体です
+type mcpHandler struct {
+	fetcher  *Fetcher
+	resolver *Resolver
+}
+
+// NewMCPServer は Fetcher の機能をツールとして公開する MCP サーバーを作成します
+// resolver が nil の場合、短いパッケージ名は解決せずにそのまま使用します
+func NewMCPServer(fetcher *Fetcher, resolver *Resolver, version string) *mcp.Server {
+	h := &mcpHandler{fetcher: fetcher, resolver: resolver}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: MCPServerName, Version: version}, nil)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "search",
+		Description: "pkg.go.dev でパッケージを名前で検索し、インポート数などの情報とともに返します",
+	}, h.search)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "find",
+		Description: "pkg.go.dev でシンボル（関数、型、メソッドなど）を検索し、定義しているパッケージとともに返します",
+	}, h.find)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "summary",
+		Description: "パッケージの概要、ファイル一覧、go.mod と README を Markdown で返します",
+	}, h.summary)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "ls",
+		Description: "パッケージを含むリポジトリのファイル一覧を返します",
+	}, h.list)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "read",
+		Description: "リポジトリ内のファイル（行範囲の指定可）、またはシンボルの宣言をドキュメントコメント付きで返します",
+	}, h.read)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "versions",
+		Description: "パッケージを含むモジュールの公開バージョン一覧（プレリリース、撤回済みを含む）を返します",
+	}, h.versions)
+
+	return server
+}
+
+// search は search ツールの処理です
+func (h *mcpHandler) search(ctx context.Context, req *mcp.CallToolRequest, in SearchInput) (*mcp.CallToolResult, SearchOutput, error) {
+	packages, err := h.fetcher.SearchPackage(in.Query, searchLimit(in.Limit))
+	if err != nil {
+		return nil, SearchOutput{}, err
+	}
+	if packages == nil {
+		packages = []Package{}
+	}
+	return nil, SearchOutput{Packages: packages}, nil
+}
+
+// find は find ツールの処理です
+func (h *mcpHandler) find(ctx context.Context, req *mcp.CallToolRequest, in SearchInput) (*mcp.CallToolResult, FindOutput, error) {
+	symbols, err := h.fetcher.SearchSymbol(in.Query, searchLimit(in.Limit))
+	if err != nil {
+		return nil, FindOutput{}, err
+	}
+	if symbols == nil {
+		symbols = []Symbol{}
+	}
+	return nil, FindOutput{Symbols: symbols}, nil
+}
+
+// summary は summary ツールの処理です
+func (h *mcpHandler) summary(ctx context.Context, req *mcp.CallToolRequest, in SummaryInput) (*mcp.CallToolResult, SummaryOutput, error) {
+	importPath, version, err := h.resolve(in.PackageInput)
+	if err != nil {
+		return nil, SummaryOutput{}, err
+	}
+
+	out, err := summarize(h.fetcher, importPath, version, !in.NoCache)
+	if err != nil {
+		return nil, SummaryOutput{}, err
+	}
+
+	// 本文は Markdown のまま返す
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: out.Content}}}
+	return result, out, nil
+}
+
+// summarize はパッケージのサマリーと、モジュールから検出したライセンスを取得します
+// ライセンスを検出できない場合もサマリーは返します
+func summarize(f *Fetcher, importPath string, version string, useCache bool) (SummaryOutput, error) {
+	content, err := f.GetPackage(importPath, version, GetPackageOptions{UseCache: useCache})
+	if err != nil {
+		return SummaryOutput{}, err
+	}
+
+	out := SummaryOutput{Package: importPath, Version: version, Content: content}
+	// --no-cache の場合も GetPackage で取得し直したモジュールの zip を使用する
+	if licenses, err := f.GetModuleLicenses(importPath, version, true); err == nil && len(licenses.Files) > 0 {
+		out.License = licenses
+	}
+	return out, nil
+}
+
+// list は ls ツールの処理です
+func (h *mcpHandler) list(ctx context.Context, req *mcp.CallToolRequest, in PackageInput) (*mcp.CallToolResult, ListOutput, error) {
+	importPath, version, err := h.resolve(in)
+	if err != nil {
+		return nil, ListOutput{}, err
+	}
+
+	files, err := h.fetcher.ListPackageFiles(importPath, version)
+	if err != nil {
+		return nil, ListOutput{}, err
+	}
+	if files == nil {
+		files = []string{}
+	}
+	return nil, ListOutput{Package: importPath, Version: version, Files: files}, nil
+}
+
+// read は read ツールの処理です
+func (h *mcpHandler) read(ctx context.Context, req *mcp.CallToolRequest, in ReadInput) (*mcp.CallToolResult, ReadOutput, error) {
+	if (in.File == "") == (in.Symbol == "") {
+		return nil, ReadOutput{}, fmt.Errorf("file と symbol のどちらか一方を指定してください")
+	}
+
+	var lines *LineRange
+	if in.Lines != "" {
+		if in.Symbol != "" {
+			return nil, ReadOutput{}, fmt.Errorf("lines は file と組み合わせて指定してください")
+		}
+		r, err := ParseLineRange(in.Lines)
+		if err != nil {
+			return nil, ReadOutput{}, err
+		}
+		lines = &r
+	}
+
+	importPath, version, err := h.resolve(in.PackageInput)
+	if err != nil {
+		return nil, ReadOutput{}, err
+	}
+
+	out := ReadOutput{Package: importPath, Version: version}
+	if in.Symbol != "" {
+		decl, err := h.fetcher.ReadPackageSymbol(importPath, version, in.Symbol)
+		if err != nil {
+			return nil, ReadOutput{}, err
+		}
+		out.File = decl.Filename
+		out.Lines = &decl.Lines
+		out.Content = decl.Source
+	} else {
+		content, err := h.fetcher.ReadPackageFile(importPath, version, in.File)
+		if err != nil {
+			return nil, ReadOutput{}, err
+		}
+		if lines != nil {
+			content, err = lines.Apply(content)
+			if err != nil {
+				return nil, ReadOutput{}, err
+			}
+		}
+		out.File = in.File
+		out.Lines = lines
+		out.Content = content
+	}
+
+	// 本文はソースコードのまま返す
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: out.Content}}}
+	return result, out, nil
+}
+
+// versions は versions ツールの処理です
+func (h *mcpHandler) versions(ctx context.Context, req *mcp.CallToolRequest, in VersionsInput) (*mcp.CallToolResult, *ModuleVersions, error) {
+	importPath, _, err := h.resolve(PackageInput{Package: in.Package})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	versions, err := h.fetcher.ListVersions(importPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, versions, nil
+}
+
+// resolve はツールの入力からインポートパスとバージョンを決定します
+// スラッシュを含まない短い名前は resolver で解決し、候補が拮抗している場合はエラーを返します
+func (h *mcpHandler) resolve(in PackageInput) (string, string, error) {
+	importPath, version, _ := strings.Cut(in.Package, "@")
+	if in.Version != "" {
+		version = in.Version
+	}
+	if version == "" {
+		version = "latest"
+	}
+
+	if importPath == "" {
+		return "", "", fmt.Errorf("package を指定してください")
+	}
+
+	importPath, err := h.resolver.ResolveIfShort(importPath, SearchModePackage)
+	if err != nil {
+		return "", "", err
+	}
+	return importPath, version, nil
+}
+
+// searchLimit は検索件数の指定を既定値で補います
+func searchLimit(limit int) int {
+	if limit <= 0 {
+		return defaultSearchLimit
+	}
+	return limit
+}
@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMCPSession はフィクスチャに接続した MCP サーバーとインメモリで接続したクライアントセッションを作成します
+func newTestMCPSession(t *testing.T) *mcp.ClientSession {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	// モジュールプロキシの代わりに固定のレスポンスを返す
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/go.uber.org/zap/@v/list":
+			_, _ = w.Write([]byte("v1.26.0\nv1.27.0\n"))
+		case "/go.uber.org/zap/@v/v1.26.0.info":
+			_, _ = w.Write([]byte(`{"Version":"v1.26.0","Time":"2023-09-14T00:00:00Z"}`))
+		case "/go.uber.org/zap/@v/v1.27.0.info":
+			_, _ = w.Write([]byte(`{"Version":"v1.27.0","Time":"2024-02-20T00:00:00Z"}`))
+		case "/go.uber.org/zap/@v/v1.27.0.mod":
+			_, _ = w.Write([]byte("module go.uber.org/zap\n\nretract v1.26.0 // 誤ったリリース\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(proxy.Close)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.scraper = newFixtureScraper(t, map[string]string{
+		"/search":                 "search-zap.html",
+		"/search?page=2":          "search-zap-page2.html",
+		"/search?m=symbol":        "search-symbol-newlogger.html",
+		"/search?m=symbol&page=2": "search-empty.html",
+	})
+	f.proxyURL = proxy.URL
+
+	server := NewMCPServer(f, nil, "test")
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	ctx := context.Background()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "test"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	return session
+}
+
+// callTool はツールを呼び出し、構造化された出力を out にデコードします
+func callTool(t *testing.T, session *mcp.ClientSession, name string, args any, out any) *mcp.CallToolResult {
+	t.Helper()
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	require.NoError(t, err)
+	if out != nil && !result.IsError {
+		data, err := json.Marshal(result.StructuredContent)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, out))
+	}
+	return result
+}
+
+func TestMCPListTools(t *testing.T) {
+	session := newTestMCPSession(t)
+
+	result, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+		assert.NotNil(t, tool.InputSchema, "%s に入力スキーマがあること", tool.Name)
+		assert.NotNil(t, tool.OutputSchema, "%s に出力スキーマがあること", tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"search", "find", "summary", "ls", "read", "versions"}, names)
+}
+
+func TestMCPSearch(t *testing.T) {
+	session := newTestMCPSession(t)
+
+	var out SearchOutput
+	result := callTool(t, session, "search", map[string]any{"query": "zap", "limit": 2}, &out)
+	require.False(t, result.IsError)
+
+	require.Len(t, out.Packages, 2)
+	assert.Equal(t, "go.uber.org/zap", out.Packages[0].ImportPath)
+	assert.Equal(t, 30412, out.Packages[0].ImportedBy)
+	assert.Equal(t, "MIT", out.Packages[0].License)
+}
+
+func TestMCPFind(t *testing.T) {
+	session := newTestMCPSession(t)
+
+	var out FindOutput
+	result := callTool(t, session, "find", map[string]any{"query": "NewLogger"}, &out)
+	require.False(t, result.IsError)
+
+	require.Len(t, out.Symbols, 2)
+	assert.Equal(t, "NewProduction", out.Symbols[0].Name)
+	assert.Equal(t, "go.uber.org/zap", out.Symbols[0].PackagePath)
+	assert.Equal(t, "Logger.Sugar", out.Symbols[1].Name)
+}
+
+func TestMCPVersions(t *testing.T) {
+	session := newTestMCPSession(t)
+
+	var out ModuleVersions
+	result := callTool(t, session, "versions", map[string]any{"package": "go.uber.org/zap"}, &out)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, "go.uber.org/zap", out.ModulePath)
+	assert.Equal(t, VersionSourceProxy, out.Source)
+	require.Len(t, out.Versions, 2)
+	assert.Equal(t, "v1.27.0", out.Versions[0].Version)
+	assert.True(t, out.Versions[1].Retracted, "retract ディレクティブが反映されること")
+	assert.Equal(t, "誤ったリリース", out.Versions[1].RetractReason)
+}
+
+func TestMCPToolErrors(t *testing.T) {
+	session := newTestMCPSession(t)
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+	}{
+		{name: "file と symbol の両方を指定", tool: "read", args: map[string]any{"package": "go.uber.org/zap", "file": "go.mod", "symbol": "Logger"}},
+		{name: "file も symbol も未指定", tool: "read", args: map[string]any{"package": "go.uber.org/zap"}},
+		{name: "無効な行範囲", tool: "read", args: map[string]any{"package": "go.uber.org/zap", "file": "go.mod", "lines": "L9-L1"}},
+		{name: "存在しないモジュール", tool: "versions", args: map[string]any{"package": "example.com/missing"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, session, tt.tool, tt.args, nil)
+			assert.True(t, result.IsError, "ツールのエラーとして返ること")
+			require.NotEmpty(t, result.Content)
+			text, ok := result.Content[0].(*mcp.TextContent)
+			require.True(t, ok)
+			assert.NotEmpty(t, text.Text, "エラーメッセージが含まれること")
+		})
+	}
+}
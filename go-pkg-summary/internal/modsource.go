@@ -0,0 +1,191 @@
+// Package modsource はモジュールプロキシからモジュールのソースコード一式を取得する機能を提供します
+package internal
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// moduleZipFileName はキャッシュに保存するモジュールの zip ファイル名です
+	moduleZipFileName = "module.zip"
+)
+
+// ModuleSource はモジュールの特定バージョンのソースコード一式を表す構造体です
+type ModuleSource struct {
+	// モジュールパス
+	ModulePath string
+	// バージョン
+	Version string
+	// モジュールのルートを基点とするファイルシステム
+	FS fs.FS
+}
+
+// ResolveModuleVersion はインポートパスを含むモジュールのパスと、具体的なバージョンを返します
+// version が "latest" または空の場合は、公開されている最新のバージョンを返します
+// 非公開モジュールの場合は git のタグから求めます
+func (f *Fetcher) ResolveModuleVersion(importPath string, version string) (string, string, error) {
+	if f.IsPrivateModule(importPath) {
+		return f.resolvePrivateModuleVersion(importPath, version)
+	}
+
+	modulePath, versions, err := f.ResolveModulePath(importPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	if version == "" || version == "latest" {
+		version = LatestVersion(versions)
+		if version == "" {
+			return "", "", fmt.Errorf("%s の公開バージョンが見つかりません", modulePath)
+		}
+	}
+	return modulePath, version, nil
+}
+
+// packageSource はパッケージを含むソースコード一式と、その中でのパッケージのディレクトリを返します
+// 標準ライブラリの場合は GOROOT/src を基点とし、それ以外の場合はモジュールプロキシからモジュールを取得します
+// useCache が false の場合はキャッシュしたモジュールの zip を使用せずに取得し直します
+func (f *Fetcher) packageSource(importPath string, version string, useCache bool) (*ModuleSource, string, error) {
+	if f.IsStdlibPackage(importPath) {
+		source, err := f.GetStdlibSource(importPath, version)
+		if err != nil {
+			return nil, "", err
+		}
+		return source, importPath, nil
+	}
+
+	modulePath, resolvedVersion, err := f.ResolveModuleVersion(importPath, version)
+	if err != nil {
+		return nil, "", err
+	}
+
+	source, err := f.DownloadModule(modulePath, resolvedVersion, useCache)
+	if err != nil {
+		return nil, "", err
+	}
+	return source, strings.TrimPrefix(strings.TrimPrefix(importPath, modulePath), "/"), nil
+}
+
+// DownloadModule はモジュールプロキシからモジュールの zip を取得します
+// 取得した zip はキャッシュディレクトリに保存し、useCache が true の場合は次回以降はキャッシュから読み込みます
+// useCache が false の場合はキャッシュを読まずに取得し直し、キャッシュを置き換えます
+// 同じモジュールとバージョンの同時の取得は1回にまとめ、同じ Fetcher で一度取得し直したモジュールはキャッシュから読み込みます
+// 非公開モジュールの場合は git で取得します
+func (f *Fetcher) DownloadModule(modulePath string, version string, useCache bool) (*ModuleSource, error) {
+	moduleKey := modulePath + "@" + version
+	key := moduleKey
+	if !useCache {
+		if _, ok := f.refreshedModules.Load(moduleKey); ok {
+			// ライセンスや公開APIなど同じモジュールを何度も参照する場合に、その都度取得し直さないようにする
+			useCache = true
+		} else {
+			// キャッシュを使用する取得の結果を、取得し直す要求に返さないようにする
+			key += "#no-cache"
+		}
+	}
+
+	v, err, _ := f.moduleGroup.Do(key, func() (any, error) {
+		var source *ModuleSource
+		var err error
+		if f.IsPrivateModule(modulePath) {
+			source, err = f.downloadPrivateModule(modulePath, version, useCache)
+		} else {
+			source, err = f.downloadProxyModule(modulePath, version, useCache)
+		}
+		if err == nil && !useCache {
+			f.refreshedModules.Store(moduleKey, true)
+		}
+		return source, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ModuleSource), nil
+}
+
+// downloadProxyModule はモジュールプロキシからモジュールの zip を取得し、キャッシュに保存します
+// キャッシュには一時ファイルに書き込んでから名前を変更して保存し、書き込み途中の zip を読まないようにします
+func (f *Fetcher) downloadProxyModule(modulePath string, version string, useCache bool) (*ModuleSource, error) {
+	zipPath := filepath.Join(f.cache.GetCacheDir(modulePath, version), moduleZipFileName)
+
+	var data []byte
+	cached := false
+	if useCache {
+		if cachedData, err := os.ReadFile(zipPath); err == nil {
+			data, cached = cachedData, true
+		}
+	}
+	if !cached {
+		escapedPath, escapedVersion, err := escapeModuleVersion(modulePath, version)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err = f.proxyGet(fmt.Sprintf("%s/%s/@v/%s.zip", f.proxyURL, escapedPath, escapedVersion))
+		if err != nil {
+			return nil, fmt.Errorf("モジュールの取得に失敗しました: %w", err)
+		}
+
+		if err := f.cache.EnsureDir(filepath.Dir(zipPath)); err == nil {
+			if err := writeFileAtomic(zipPath, data, 0644); err != nil && f.debug {
+				fmt.Printf("モジュールのキャッシュへの保存に失敗しました: %v\n", err)
+			}
+		}
+	} else if f.debug {
+		fmt.Printf("キャッシュからモジュールを取得しました: %s@%s\n", modulePath, version)
+	}
+
+	return NewModuleSourceFromZip(modulePath, version, data)
+}
+
+// NewModuleSourceFromZip はモジュールプロキシ形式の zip からModuleSourceを作成します
+// zip 内のファイルは "モジュールパス@バージョン/" を接頭辞として格納されています
+func NewModuleSourceFromZip(modulePath string, version string, data []byte) (*ModuleSource, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("モジュールの zip の読み込みに失敗しました: %w", err)
+	}
+
+	root, err := fs.Sub(reader, modulePath+"@"+version)
+	if err != nil {
+		return nil, fmt.Errorf("モジュールの zip の読み込みに失敗しました: %w", err)
+	}
+
+	return &ModuleSource{ModulePath: modulePath, Version: version, FS: root}, nil
+}
+
+// Files はモジュール内の全てのファイルのパスをソートして返します
+func (m *ModuleSource) Files() ([]string, error) {
+	var files []string
+	err := fs.WalkDir(m.FS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ファイル一覧の取得に失敗しました: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// ReadFile はモジュールのルートからのパスでファイルを読み込みます
+func (m *ModuleSource) ReadFile(name string) (string, error) {
+	data, err := fs.ReadFile(m.FS, strings.TrimPrefix(name, "/"))
+	if err != nil {
+		return "", fmt.Errorf("ファイル %s の読み込みに失敗しました: %w", name, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,366 @@
+// Package modtree はモジュール内の全パッケージを探索し、階層的なサマリーを生成する機能を提供します
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ModulePackage はモジュール内の1つのパッケージを表す構造体です
+type ModulePackage struct {
+	// インポートパス
+	ImportPath string `json:"importPath"`
+	// モジュールのルートからのディレクトリ（ルートの場合は空文字列）
+	Dir string `json:"dir"`
+	// パッケージ名
+	Name string `json:"name"`
+	// パッケージドキュメントの概要（最初の1文）
+	Synopsis string `json:"synopsis,omitempty"`
+	// エクスポートされている型、関数、変数、定数
+	API []TypeInfo `json:"api,omitempty"`
+	// 同じモジュール内でインポートしているパッケージ
+	Imports []string `json:"imports,omitempty"`
+}
+
+// ModuleTree はモジュール内のパッケージ一覧を表す構造体です
+type ModuleTree struct {
+	// モジュールパス
+	ModulePath string `json:"modulePath"`
+	// バージョン
+	Version string `json:"version"`
+	// インポートパス順のパッケージ一覧
+	Packages []ModulePackage `json:"packages"`
+}
+
+// ModuleTreeOptions はモジュールツリーの探索オプションを表す構造体です
+type ModuleTreeOptions struct {
+	// internal パッケージを含めるかどうか
+	IncludeInternal bool
+	// 指定した場合、このインポートパス以下のパッケージのみを対象とする
+	Root string
+	// 非推奨の宣言を公開APIから除くかどうか
+	HideDeprecated bool
+	// キャッシュしたモジュールの zip を使用するかどうか
+	UseCache bool
+}
+
+// GetModuleTree はインポートパスを含むモジュールを取得し、モジュール内の全パッケージを解析します
+// モジュールのルートより深いインポートパスを指定した場合は、そのパス以下のパッケージのみを対象とします
+func (f *Fetcher) GetModuleTree(importPath string, version string, opts ModuleTreeOptions) (*ModuleTree, error) {
+	modulePath, resolvedVersion, err := f.ResolveModuleVersion(importPath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := f.DownloadModule(modulePath, resolvedVersion, opts.UseCache)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Root == "" && importPath != modulePath {
+		opts.Root = importPath
+	}
+	return BuildModuleTree(source, opts, f.debug)
+}
+
+// BuildModuleTree はモジュールのソースコードからパッケージを探索して解析します
+// testdata、vendor、"_" や "." で始まるディレクトリ、入れ子のモジュールは対象外です
+func BuildModuleTree(source *ModuleSource, opts ModuleTreeOptions, debug bool) (*ModuleTree, error) {
+	dirs, err := packageDirs(source.FS, opts.IncludeInternal)
+	if err != nil {
+		return nil, err
+	}
+
+	ctxt := moduleBuildContext(source.FS)
+	p := NewParser(debug)
+
+	tree := &ModuleTree{ModulePath: source.ModulePath, Version: source.Version}
+	for _, dir := range dirs {
+		importPath := source.ModulePath
+		if dir != "" {
+			importPath = source.ModulePath + "/" + dir
+		}
+		if opts.Root != "" && importPath != opts.Root && !strings.HasPrefix(importPath, opts.Root+"/") {
+			continue
+		}
+
+		bp, err := ctxt.ImportDir("/"+dir, 0)
+		if err != nil {
+			var noGo *build.NoGoError
+			if !errors.As(err, &noGo) && debug {
+				fmt.Printf("パッケージ %s の読み込みに失敗しました: %v\n", importPath, err)
+			}
+			continue
+		}
+
+		pkg := ModulePackage{
+			ImportPath: importPath,
+			Dir:        dir,
+			Name:       bp.Name,
+			Synopsis:   bp.Doc,
+		}
+
+		// 同じモジュール内のインポートのみを依存として残す
+		for _, imp := range bp.Imports {
+			if imp == source.ModulePath || strings.HasPrefix(imp, source.ModulePath+"/") {
+				pkg.Imports = append(pkg.Imports, imp)
+			}
+		}
+
+		// エクスポートされている宣言を抽出
+		api, imports := parsePackageAPI(source, dir, bp, p, debug)
+		pkg.API = api
+		if opts.HideDeprecated {
+			pkg.API = FilterDeprecated(pkg.API)
+		}
+
+		// ドキュメントコメントのリンクは、サマリーに記載する宣言へのアンカーにする
+		NewDocLinker(importPath, api, pkg.API, imports).Apply(pkg.API)
+
+		tree.Packages = append(tree.Packages, pkg)
+	}
+
+	if len(tree.Packages) == 0 {
+		return nil, fmt.Errorf("%s@%s にパッケージが見つかりません", source.ModulePath, source.Version)
+	}
+	return tree, nil
+}
+
+// parsePackageAPI はパッケージのファイルを解析し、エクスポートされている宣言と、
+// ドキュメントコメントのリンクの解決に使う宣言ごとのファイルのインポートを返します
+// ジェネリックな宣言には、テストファイルの Example 関数のうちその使用例を示すものを関連付けます
+func parsePackageAPI(source *ModuleSource, dir string, bp *build.Package, p *Parser, debug bool) ([]TypeInfo, map[string]map[string]string) {
+	var all []TypeInfo
+	var allImports []map[string]string
+	for _, file := range bp.GoFiles {
+		content, err := source.ReadFile(path.Join(dir, file))
+		if err != nil {
+			continue
+		}
+		infos, err := p.ParseFile(file, content)
+		if err != nil {
+			if debug {
+				fmt.Printf("ファイル %s の解析に失敗しました: %v\n", file, err)
+			}
+			continue
+		}
+		fileImports, _ := parseImports(file, content)
+		for _, info := range infos {
+			all = append(all, info)
+			allImports = append(allImports, fileImports)
+		}
+	}
+
+	// 他のファイルや非公開の型制約を埋め込んだインターフェースも型制約とする
+	markEmbeddedConstraints(all)
+
+	var api []TypeInfo
+	imports := map[string]map[string]string{}
+	for i, info := range all {
+		// 非公開の型のメソッドは公開APIに含めない
+		if ast.IsExported(info.Name) && (info.Receiver == "" || ast.IsExported(info.Receiver)) {
+			api = append(api, info)
+			imports[apiSymbol(info)] = allImports[i]
+		}
+	}
+
+	examples, fset := parseExamples(source, dir, slices.Concat(bp.TestGoFiles, bp.XTestGoFiles))
+	linkExamples(api, examples, fset)
+	return api, imports
+}
+
+// packageDirs は Go ファイルを含む可能性のあるディレクトリをソートして返します
+func packageDirs(fsys fs.FS, includeInternal bool) ([]string, error) {
+	var dirs []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p == "." {
+			dirs = append(dirs, "")
+			return nil
+		}
+
+		name := d.Name()
+		switch {
+		case name == "testdata", name == "vendor", strings.HasPrefix(name, "_"), strings.HasPrefix(name, "."):
+			return fs.SkipDir
+		case name == "internal" && !includeInternal:
+			return fs.SkipDir
+		}
+
+		// go.mod を含むディレクトリは別のモジュール
+		if _, err := fs.Stat(fsys, path.Join(p, "go.mod")); err == nil {
+			return fs.SkipDir
+		}
+
+		dirs = append(dirs, p)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ディレクトリの探索に失敗しました: %w", err)
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// moduleBuildContext はモジュールのファイルシステムを "/" をルートとして読む go/build のコンテキストを作成します
+// ビルド制約はこのツールを実行している環境の GOOS/GOARCH で評価します
+func moduleBuildContext(fsys fs.FS) build.Context {
+	ctxt := build.Default
+	ctxt.GOROOT = ""
+	ctxt.GOPATH = ""
+
+	rel := func(p string) string {
+		p = strings.TrimPrefix(path.Clean(p), "/")
+		if p == "" {
+			return "."
+		}
+		return p
+	}
+
+	ctxt.JoinPath = path.Join
+	ctxt.IsAbsPath = path.IsAbs
+	ctxt.IsDir = func(p string) bool {
+		info, err := fs.Stat(fsys, rel(p))
+		return err == nil && info.IsDir()
+	}
+	ctxt.HasSubdir = func(root, dir string) (string, bool) {
+		return "", false
+	}
+	ctxt.ReadDir = func(dir string) ([]fs.FileInfo, error) {
+		entries, err := fs.ReadDir(fsys, rel(dir))
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]fs.FileInfo, 0, len(entries))
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos, info)
+		}
+		return infos, nil
+	}
+	ctxt.OpenFile = func(p string) (io.ReadCloser, error) {
+		return fsys.Open(rel(p))
+	}
+
+	return ctxt
+}
+
+// Graph はモジュール内のパッケージ間の依存グラフを返します
+func (t *ModuleTree) Graph() *Graph {
+	g := &Graph{}
+	included := make(map[string]bool, len(t.Packages))
+	for _, pkg := range t.Packages {
+		included[pkg.ImportPath] = true
+		g.Nodes = append(g.Nodes, GraphNode{ID: pkg.ImportPath, Label: t.relativePath(pkg.ImportPath)})
+	}
+	for _, pkg := range t.Packages {
+		for _, imp := range pkg.Imports {
+			if included[imp] {
+				g.Edges = append(g.Edges, GraphEdge{From: pkg.ImportPath, To: imp})
+			}
+		}
+	}
+	return g
+}
+
+// relativePath はモジュールパスからの相対パスを返します（ルートのパッケージはモジュールパスの最後の要素）
+func (t *ModuleTree) relativePath(importPath string) string {
+	if importPath == t.ModulePath {
+		return path.Base(t.ModulePath)
+	}
+	return strings.TrimPrefix(importPath, t.ModulePath+"/")
+}
+
+// FormatModuleTree はモジュールツリーを Markdown に整形します
+// graphFormat が空文字列の場合は依存グラフを出力しません
+func FormatModuleTree(tree *ModuleTree, graphFormat string) (string, error) {
+	var output strings.Builder
+
+	output.WriteString(fmt.Sprintf("# %s\n\n", tree.ModulePath))
+	output.WriteString(fmt.Sprintf("モジュールパス: %s\n", tree.ModulePath))
+	output.WriteString(fmt.Sprintf("バージョン: %s\n", tree.Version))
+	output.WriteString(fmt.Sprintf("パッケージ数: %d\n\n", len(tree.Packages)))
+
+	// パッケージ構成（最も浅いパッケージを基準にディレクトリの深さでインデント）
+	output.WriteString("## パッケージ構成\n\n")
+	baseDepth := -1
+	for _, pkg := range tree.Packages {
+		if depth := dirDepth(pkg.Dir); baseDepth == -1 || depth < baseDepth {
+			baseDepth = depth
+		}
+	}
+	for _, pkg := range tree.Packages {
+		indent := strings.Repeat("  ", dirDepth(pkg.Dir)-baseDepth)
+		line := fmt.Sprintf("%s- %s", indent, pkg.ImportPath)
+		if pkg.Synopsis != "" {
+			line += ": " + pkg.Synopsis
+		}
+		output.WriteString(line + "\n")
+	}
+	output.WriteString("\n")
+
+	// パッケージ依存グラフ
+	if graphFormat != "" {
+		graph, err := tree.Graph().Render(graphFormat)
+		if err != nil {
+			return "", err
+		}
+		output.WriteString("## パッケージ依存グラフ\n\n")
+		output.WriteString(fmt.Sprintf("```%s\n%s```\n\n", graphFormat, graph))
+	}
+
+	// パッケージごとのセクション
+	output.WriteString("## パッケージ\n\n")
+	for _, pkg := range tree.Packages {
+		output.WriteString(fmt.Sprintf("### %s\n\n", pkg.ImportPath))
+		output.WriteString(fmt.Sprintf("パッケージ名: %s\n", pkg.Name))
+		if pkg.Synopsis != "" {
+			output.WriteString(fmt.Sprintf("概要: %s\n", pkg.Synopsis))
+		}
+		if len(pkg.Imports) > 0 {
+			output.WriteString(fmt.Sprintf("モジュール内の依存: %s\n", strings.Join(pkg.Imports, ", ")))
+		}
+		output.WriteString("\n")
+
+		if len(pkg.API) > 0 {
+			output.WriteString("#### 公開API\n\n")
+			for _, info := range pkg.API {
+				output.WriteString(apiLine(info.Definition, info))
+			}
+			output.WriteString("\n")
+		}
+	}
+
+	return output.String(), nil
+}
+
+// dirDepth はモジュールのルートからのディレクトリの深さを返します（ルートは0）
+func dirDepth(dir string) int {
+	if dir == "" {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
+
+// firstLine はコメントの最初の行を返します
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,311 @@
+package internal
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestModuleZip はモジュールプロキシ形式の zip を作成します
+func newTestModuleZip(t *testing.T, modulePath string, version string, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		fw, err := w.Create(modulePath + "@" + version + "/" + name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// sampleModuleFiles はテスト用のモジュールのファイルです
+var sampleModuleFiles = map[string]string{
+	"go.mod": "module example.com/lib\n\ngo 1.22\n",
+	"lib.go": `// Package lib はサンプルのライブラリです。
+package lib
+
+import (
+	"fmt"
+
+	"example.com/lib/codec"
+	"example.com/lib/internal/pool"
+)
+
+// Client はクライアントです
+type Client struct{}
+
+// Do はリクエストを実行します
+func (c *Client) Do() { fmt.Println(codec.Name, pool.Size) }
+
+type state struct{}
+
+// Reset は非公開の型のメソッドです
+func (s *state) Reset() {}
+
+func helper() {}
+`,
+	"lib_test.go":           "package lib\n\nfunc TestHelper() {}\n",
+	"gen.go":                "//go:build ignore\n\npackage main\n\nfunc main() {}\n",
+	"codec/codec.go":        "// Package codec はエンコーダーを提供します。\npackage codec\n\n// Name はコーデック名です\nconst Name = \"json\"\n",
+	"codec/json/json.go":    "package json\n\nimport \"example.com/lib/codec\"\n\n// Encode はエンコードします\nfunc Encode() string { return codec.Name }\n",
+	"internal/pool/pool.go": "package pool\n\n// Size はプールのサイズです\nconst Size = 4\n",
+	"testdata/fixture.go":   "package fixture\n",
+	"tools/go.mod":          "module example.com/lib/tools\n",
+	"tools/tools.go":        "package tools\n",
+	"docs/README.md":        "# docs\n",
+}
+
+func TestBuildModuleTree(t *testing.T) {
+	source, err := NewModuleSourceFromZip("example.com/lib", "v1.2.0", newTestModuleZip(t, "example.com/lib", "v1.2.0", sampleModuleFiles))
+	require.NoError(t, err)
+
+	tree, err := BuildModuleTree(source, ModuleTreeOptions{}, false)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, pkg := range tree.Packages {
+		paths = append(paths, pkg.ImportPath)
+	}
+	assert.Equal(t, []string{
+		"example.com/lib",
+		"example.com/lib/codec",
+		"example.com/lib/codec/json",
+	}, paths, "internal、testdata、入れ子のモジュール、Go ファイルのないディレクトリは除外されること")
+
+	root := tree.Packages[0]
+	assert.Equal(t, "lib", root.Name, "ビルド制約で除外されたファイルのパッケージ名は使われないこと")
+	assert.Equal(t, "Package lib はサンプルのライブラリです。", root.Synopsis)
+	assert.Equal(t, []string{"example.com/lib/codec", "example.com/lib/internal/pool"}, root.Imports, "モジュール内のインポートのみが残ること")
+
+	var api []string
+	for _, info := range root.API {
+		api = append(api, info.Definition)
+	}
+	assert.Equal(t, []string{"type Client struct", "func (*Client) Do()"}, api, "公開されている宣言のみが含まれること")
+}
+
+func TestBuildModuleTreeOptions(t *testing.T) {
+	source, err := NewModuleSourceFromZip("example.com/lib", "v1.2.0", newTestModuleZip(t, "example.com/lib", "v1.2.0", sampleModuleFiles))
+	require.NoError(t, err)
+
+	tree, err := BuildModuleTree(source, ModuleTreeOptions{IncludeInternal: true}, false)
+	require.NoError(t, err)
+	assert.Len(t, tree.Packages, 4, "internal パッケージを含められること")
+
+	tree, err = BuildModuleTree(source, ModuleTreeOptions{Root: "example.com/lib/codec"}, false)
+	require.NoError(t, err)
+	require.Len(t, tree.Packages, 2, "Root 以下のパッケージのみが対象になること")
+	assert.Equal(t, "example.com/lib/codec", tree.Packages[0].ImportPath)
+
+	_, err = BuildModuleTree(source, ModuleTreeOptions{Root: "example.com/lib/missing"}, false)
+	assert.Error(t, err, "パッケージがない場合はエラーになること")
+}
+
+func TestModuleTreeGraph(t *testing.T) {
+	source, err := NewModuleSourceFromZip("example.com/lib", "v1.2.0", newTestModuleZip(t, "example.com/lib", "v1.2.0", sampleModuleFiles))
+	require.NoError(t, err)
+	tree, err := BuildModuleTree(source, ModuleTreeOptions{}, false)
+	require.NoError(t, err)
+
+	mermaid, err := tree.Graph().Render(GraphFormatMermaid)
+	require.NoError(t, err)
+	assert.Equal(t, `graph TD
+    n0["lib"]
+    n1["codec"]
+    n2["codec/json"]
+    n0 --> n1
+    n2 --> n1
+`, mermaid, "除外したパッケージへの辺は出力されないこと")
+
+	dot, err := tree.Graph().Render(GraphFormatDOT)
+	require.NoError(t, err)
+	assert.Contains(t, dot, `"example.com/lib/codec/json" -> "example.com/lib/codec";`)
+
+	_, err = tree.Graph().Render("svg")
+	assert.Error(t, err)
+}
+
+func TestFormatModuleTree(t *testing.T) {
+	source, err := NewModuleSourceFromZip("example.com/lib", "v1.2.0", newTestModuleZip(t, "example.com/lib", "v1.2.0", sampleModuleFiles))
+	require.NoError(t, err)
+	tree, err := BuildModuleTree(source, ModuleTreeOptions{}, false)
+	require.NoError(t, err)
+
+	content, err := FormatModuleTree(tree, "")
+	require.NoError(t, err)
+
+	assert.Contains(t, content, "- example.com/lib: Package lib はサンプルのライブラリです。\n  - example.com/lib/codec: Package codec はエンコーダーを提供します。\n    - example.com/lib/codec/json\n", "パッケージ構成が階層的に表示されること")
+	assert.Contains(t, content, "### example.com/lib/codec/json")
+	assert.Contains(t, content, "- <a id=\"api-example-com-lib-codec-json-Encode\"></a>`func Encode()`: Encode はエンコードします")
+	assert.NotContains(t, content, "パッケージ依存グラフ", "グラフ形式が空の場合はグラフを出力しないこと")
+}
+
+func TestDownloadModuleUsesCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	data := newTestModuleZip(t, "example.com/lib", "v1.2.0", sampleModuleFiles)
+	var hits atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/example.com/lib/@v/v1.2.0.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		hits.Add(1)
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(proxy.Close)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.proxyURL = proxy.URL
+
+	for i := 0; i < 2; i++ {
+		source, err := f.DownloadModule("example.com/lib", "v1.2.0", true)
+		require.NoError(t, err)
+
+		content, err := source.ReadFile("codec/codec.go")
+		require.NoError(t, err)
+		assert.Contains(t, content, "package codec")
+	}
+	assert.Equal(t, int32(1), hits.Load(), "2回目はキャッシュから読み込むこと")
+
+	_, err = f.DownloadModule("example.com/lib", "v1.2.0", false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), hits.Load(), "キャッシュを使用しない場合は取得し直すこと")
+}
+
+func TestDownloadModuleConcurrent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	data := newTestModuleZip(t, "example.com/lib", "v1.2.0", sampleModuleFiles)
+	var hits atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		// 他の取得が同時に始まるように応答を遅らせる
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(proxy.Close)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.proxyURL = proxy.URL
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			source, err := f.DownloadModule("example.com/lib", "v1.2.0", true)
+			if err == nil {
+				_, err = source.ReadFile("codec/codec.go")
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), hits.Load(), "同じモジュールの同時の取得は1回にまとめること")
+
+	entries, err := os.ReadDir(f.cache.GetCacheDir("example.com/lib", "v1.2.0"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "一時ファイルを残さないこと")
+	assert.Equal(t, moduleZipFileName, entries[0].Name())
+	cached, err := os.ReadFile(filepath.Join(f.cache.GetCacheDir("example.com/lib", "v1.2.0"), moduleZipFileName))
+	require.NoError(t, err)
+	assert.Equal(t, data, cached)
+}
+
+func TestPackageAPIUseCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	data := newTestModuleZip(t, "example.com/lib", "v1.2.0", sampleModuleFiles)
+	var hits atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/lib/@v/list":
+			_, _ = w.Write([]byte("v1.2.0\n"))
+		case "/example.com/lib/@v/v1.2.0.zip":
+			hits.Add(1)
+			_, _ = w.Write(data)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(proxy.Close)
+
+	newFetcher := func() *Fetcher {
+		f, err := NewFetcher(false)
+		require.NoError(t, err)
+		f.proxyURL = proxy.URL
+		return f
+	}
+
+	// キャッシュを使用する場合は、別の Fetcher でもキャッシュした zip を使用する
+	_, err := newFetcher().GetPackageChunks("example.com/lib/codec", "v1.2.0", false, true)
+	require.NoError(t, err)
+	_, err = newFetcher().GetModuleTree("example.com/lib", "v1.2.0", ModuleTreeOptions{UseCache: true})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), hits.Load())
+
+	// キャッシュを使用しない場合は取得し直し、同じ Fetcher の以降の取得では取得し直した zip を使用する
+	f := newFetcher()
+	_, err = f.GetPackageChunks("example.com/lib/codec", "v1.2.0", false, false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), hits.Load(), "--no-cache の場合はモジュールを取得し直すこと")
+	_, err = f.GetModuleTree("example.com/lib", "v1.2.0", ModuleTreeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), hits.Load(), "同じ Fetcher で取得し直したモジュールは再度取得しないこと")
+
+	_, err = newFetcher().GetModuleTree("example.com/lib", "v1.2.0", ModuleTreeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), hits.Load())
+}
+
+func TestReadPackageSymbolBuildConstraints(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// 同じ関数が OS ごとのファイルに定義されている
+	files := map[string]string{
+		"go.mod":        "module example.com/osdep\n",
+		"doc.go":        "// Package osdep は OS ごとの実装を持ちます\npackage osdep\n",
+		"osdep_test.go": "package osdep\n\n// Name はテスト用の宣言です\nfunc Name() string { return \"test\" }\n",
+	}
+	for _, goos := range []string{"linux", "windows", "darwin"} {
+		files["osdep_"+goos+".go"] = "package osdep\n\n// Name は OS の名前を返します\nfunc Name() string { return \"" + goos + "\" }\n"
+	}
+	data := newTestModuleZip(t, "example.com/osdep", "v1.0.0", files)
+
+	proxyURL := newVersionsProxy(t, map[string]string{"example.com/osdep": "v1.0.0\n"}, map[string]string{
+		"example.com/osdep/@v/v1.0.0.zip": string(data),
+	})
+	// pkg.go.dev と GitHub の API には接続できないようにし、モジュールの zip だけから読み込むことを確かめる
+	f, err := NewFetcher(false, WithBaseURLs(BaseURLs{Proxy: proxyURL, PkgGoDev: "http://127.0.0.1:0", GitHubAPI: "http://127.0.0.1:0"}))
+	require.NoError(t, err)
+
+	decl, err := f.ReadPackageSymbol("example.com/osdep", "latest", "Name")
+	require.NoError(t, err)
+	if runtime.GOOS == "linux" || runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		assert.Equal(t, "osdep_"+runtime.GOOS+".go", decl.Filename, "現在の環境のビルド制約に一致するファイルから探すこと")
+	}
+	assert.NotContains(t, decl.Source, `"test"`, "テストファイルは対象外であること")
+}
@@ -0,0 +1,128 @@
+// Package options は Fetcher と Scraper の生成時に HTTP クライアントや接続先、キャッシュを差し替える関数オプションを提供します
+package internal
+
+import "net/http"
+
+// Option は NewFetcher と NewScraper に渡す関数オプションです
+type Option func(*options)
+
+// options は関数オプションで設定される値です
+type options struct {
+	httpClient *http.Client
+	baseURLs   BaseURLs
+	cache      *Cache
+	tokens     map[string]string
+}
+
+// BaseURLs は外部サービスの URL の基点です
+// 空のフィールドは既定の URL を使用します
+type BaseURLs struct {
+	// pkg.go.dev（既定: DefaultPkgGoDevURL）
+	PkgGoDev string
+	// モジュールプロキシ（既定: DefaultProxyURL）
+	Proxy string
+	// go-import のディスカバリー（既定: https://<インポートパス>）
+	GoGet string
+	// GitHub の API（既定: DefaultGitHubAPIURL）
+	GitHubAPI string
+	// GitLab の API（既定: DefaultGitLabAPIURL）
+	GitLabAPI string
+	// 標準ライブラリのソースコードを取得する Go のリポジトリ（既定: DefaultStdlibSourceURL）
+	StdlibSource string
+	// Go のリポジトリのミラー（既定: DefaultStdlibMirrorURL）
+	StdlibMirror string
+}
+
+// WithHTTPClient は外部サービスへのリクエストに使用する HTTP クライアントを指定します
+// Fetcher はクライアントの Transport、Jar、Timeout を使用し、リダイレクトは追跡しません
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithBaseURLs は外部サービスの URL の基点を指定します
+// 空でないフィールドのみを上書きするため、複数回指定した場合は後のものが優先されます
+func WithBaseURLs(urls BaseURLs) Option {
+	return func(o *options) {
+		override := func(dst *string, src string) {
+			if src != "" {
+				*dst = src
+			}
+		}
+		override(&o.baseURLs.PkgGoDev, urls.PkgGoDev)
+		override(&o.baseURLs.Proxy, urls.Proxy)
+		override(&o.baseURLs.GoGet, urls.GoGet)
+		override(&o.baseURLs.GitHubAPI, urls.GitHubAPI)
+		override(&o.baseURLs.GitLabAPI, urls.GitLabAPI)
+		override(&o.baseURLs.StdlibSource, urls.StdlibSource)
+		override(&o.baseURLs.StdlibMirror, urls.StdlibMirror)
+	}
+}
+
+// WithCache は Fetcher が使用するキャッシュを指定します
+func WithCache(c *Cache) Option {
+	return func(o *options) {
+		o.cache = c
+	}
+}
+
+// WithTokens はホストごとの認証トークンを指定します
+// tokens のキーは API の URL のホスト（例: api.github.com）で、一致するホストへのリクエストに Authorization ヘッダーを付与します
+func WithTokens(tokens map[string]string) Option {
+	return func(o *options) {
+		if o.tokens == nil {
+			o.tokens = map[string]string{}
+		}
+		for host, token := range tokens {
+			o.tokens[host] = token
+		}
+	}
+}
+
+// transport は base に認証トークンを付与する RoundTripper を重ねて返します
+func (o options) transport(base http.RoundTripper) http.RoundTripper {
+	if len(o.tokens) == 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &authTransport{next: base, tokens: o.tokens}
+}
+
+// authTransport はホストに対応するトークンを Authorization ヘッダーに付与する RoundTripper です
+type authTransport struct {
+	next   http.RoundTripper
+	tokens map[string]string
+}
+
+// RoundTrip はトークンを付与してリクエストを送信します（Authorization ヘッダーが既にある場合は変更しません）
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, ok := t.tokens[req.URL.Host]
+	if !ok || req.Header.Get("Authorization") != "" {
+		return t.next.RoundTrip(req)
+	}
+	// RoundTripper はリクエストを変更してはならないため、複製してから付与する
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.next.RoundTrip(req)
+}
+
+// newOptions は既定値に関数オプションを適用します
+func newOptions(opts []Option) options {
+	o := options{
+		baseURLs: BaseURLs{
+			PkgGoDev:     DefaultPkgGoDevURL,
+			Proxy:        DefaultProxyURL,
+			GitHubAPI:    DefaultGitHubAPIURL,
+			GitLabAPI:    DefaultGitLabAPIURL,
+			StdlibSource: DefaultStdlibSourceURL,
+			StdlibMirror: DefaultStdlibMirrorURL,
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -2,12 +2,31 @@
 package internal
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/printer"
 	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
 )
 
+// knownConstraints は他のパッケージで定義されたよく使われる型制約です（"パッケージ名.型名"）
+var knownConstraints = map[string]bool{
+	"cmp.Ordered":          true,
+	"constraints.Ordered":  true,
+	"constraints.Signed":   true,
+	"constraints.Unsigned": true,
+	"constraints.Integer":  true,
+	"constraints.Float":    true,
+	"constraints.Complex":  true,
+}
+
+// embeddedTypeNamePattern はインターフェースの要素のうち、埋め込んだ型の名前（"Name"、"pkg.Name"、"Name[T]"）に一致する正規表現です
+var embeddedTypeNamePattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)(?:\[.*\])?$`)
+
 // Parser はGoコードを解析する構造体です
 type Parser struct {
 	debug bool
@@ -48,6 +67,14 @@ func (p *Parser) ParseFile(filename string, src string) ([]TypeInfo, error) {
 		}
 	}
 
+	// 同じファイルの型制約を埋め込んだインターフェースも型制約とする
+	markEmbeddedConstraints(typeInfos)
+
+	// "Deprecated:" の段落がある宣言に非推奨の印を付ける
+	for i := range typeInfos {
+		typeInfos[i].Replacement, typeInfos[i].Deprecated = deprecationNote(typeInfos[i].Comment)
+	}
+
 	return typeInfos, nil
 }
 
@@ -70,26 +97,43 @@ func (p *Parser) processGenDecl(decl *ast.GenDecl, fset *token.FileSet) []TypeIn
 				kind := "type"
 				definition := ""
 
-				// 型の種類を判定
-				switch ts.Type.(type) {
+				// 型の種類を判定（型集合を持つインターフェースは型制約としてのみ使用できる）
+				switch t := ts.Type.(type) {
 				case *ast.StructType:
 					kind = "struct"
 				case *ast.InterfaceType:
 					kind = "interface"
+					if isConstraintInterface(t) {
+						kind = "constraint"
+					}
 				case *ast.FuncType:
 					kind = "func"
 				}
 
-				// 型の定義を取得
-				definition = fmt.Sprintf("type %s %s", ts.Name.Name, kind)
+				// 型の定義を取得（型パラメータを含む）
+				typeParams := typeParamList(fset, ts.TypeParams)
+				name := ts.Name.Name + formatTypeParams(typeParams)
+				definition = fmt.Sprintf("type %s %s", name, kind)
 
 				// 型情報を追加
-				typeInfos = append(typeInfos, TypeInfo{
+				info := TypeInfo{
 					Name:       ts.Name.Name,
 					Kind:       kind,
 					Definition: definition,
-					Comment:    comment,
-				})
+					Comment:    specComment(comment, ts.Doc),
+					TypeParams: typeParams,
+				}
+				switch t := ts.Type.(type) {
+				case *ast.InterfaceType:
+					info.Methods = interfaceMethods(fset, t)
+					if kind == "constraint" {
+						// 型制約は型集合の要素を定義として示す
+						info.Definition = fmt.Sprintf("type %s interface{ %s }", name, strings.Join(info.Methods, "; "))
+					}
+				case *ast.FuncType:
+					info.Signature = "type " + name + " " + formatNode(fset, t)
+				}
+				typeInfos = append(typeInfos, info)
 			}
 		}
 	case token.CONST:
@@ -102,7 +146,7 @@ func (p *Parser) processGenDecl(decl *ast.GenDecl, fset *token.FileSet) []TypeIn
 						Name:       name.Name,
 						Kind:       "const",
 						Definition: fmt.Sprintf("const %s", name.Name),
-						Comment:    comment,
+						Comment:    specComment(comment, vs.Doc),
 					})
 				}
 			}
@@ -117,7 +161,7 @@ func (p *Parser) processGenDecl(decl *ast.GenDecl, fset *token.FileSet) []TypeIn
 						Name:       name.Name,
 						Kind:       "var",
 						Definition: fmt.Sprintf("var %s", name.Name),
-						Comment:    comment,
+						Comment:    specComment(comment, vs.Doc),
 					})
 				}
 			}
@@ -146,26 +190,21 @@ func (p *Parser) processFuncDecl(decl *ast.FuncDecl, fset *token.FileSet) *TypeI
 	// 関数/メソッド名
 	name := decl.Name.Name
 	kind := "func"
-	definition := fmt.Sprintf("func %s()", name)
+	typeParams := typeParamList(fset, decl.Type.TypeParams)
+	definition := fmt.Sprintf("func %s%s()", name, formatTypeParams(typeParams))
 
 	// メソッドの場合はレシーバーを追加
+	receiver := ""
 	if isMethod {
 		kind = "method"
-		recv := ""
+		// レシーバーの型を取得（ジェネリック型の場合は型名のみ）
+		receiver = receiverTypeName(decl)
+		recv := receiver
 		if len(decl.Recv.List) > 0 {
-			// レシーバーの型を取得
-			recvType := ""
-			switch rt := decl.Recv.List[0].Type.(type) {
-			case *ast.StarExpr:
+			if _, ok := decl.Recv.List[0].Type.(*ast.StarExpr); ok {
 				// ポインタレシーバー (*Type)
-				if ident, ok := rt.X.(*ast.Ident); ok {
-					recvType = "*" + ident.Name
-				}
-			case *ast.Ident:
-				// 値レシーバー (Type)
-				recvType = rt.Name
+				recv = "*" + receiver
 			}
-			recv = recvType
 		}
 		definition = fmt.Sprintf("func (%s) %s()", recv, name)
 	}
@@ -175,7 +214,225 @@ func (p *Parser) processFuncDecl(decl *ast.FuncDecl, fset *token.FileSet) *TypeI
 		Kind:       kind,
 		Definition: definition,
 		Comment:    comment,
+		Receiver:   receiver,
+		Signature:  formatNode(fset, &ast.FuncDecl{Recv: decl.Recv, Name: decl.Name, Type: decl.Type}),
+		Results:    resultTypeNames(decl.Type),
+		TypeParams: typeParams,
+	}
+}
+
+// typeParamList は型パラメータのリストを名前ごとの型パラメータに展開します
+func typeParamList(fset *token.FileSet, list *ast.FieldList) []TypeParam {
+	if list == nil {
+		return nil
+	}
+	var params []TypeParam
+	for _, field := range list.List {
+		constraint := formatNode(fset, field.Type)
+		for _, name := range field.Names {
+			params = append(params, TypeParam{Name: name.Name, Constraint: constraint})
+		}
 	}
+	return params
+}
+
+// formatTypeParams は型パラメータを "[K comparable, V any]" の形式で返します（型パラメータがない場合は空文字列）
+// 同じ制約が続く型パラメータは "[T, U any]" のようにまとめます
+func formatTypeParams(params []TypeParam) string {
+	if len(params) == 0 {
+		return ""
+	}
+	var groups []string
+	for i, param := range params {
+		if i+1 < len(params) && params[i+1].Constraint == param.Constraint {
+			groups = append(groups, param.Name)
+			continue
+		}
+		groups = append(groups, param.Name+" "+param.Constraint)
+	}
+	return "[" + strings.Join(groups, ", ") + "]"
+}
+
+// isConstraintInterface は型集合を持ち、型制約としてのみ使用できるインターフェースかを判定します
+// 共用体（A | B）、近似要素（~T）、インターフェースでない事前宣言型、comparable、
+// ポインタやスライスなどの複合型（interface{ *T; Set(string) } や interface{ []byte }）、
+// よく使われる他のパッケージの型制約（constraints.Ordered など）を埋め込んだものが該当します
+// 同じパッケージの型制約を埋め込んだものは markEmbeddedConstraints で判定します
+func isConstraintInterface(iface *ast.InterfaceType) bool {
+	for _, field := range iface.Methods.List {
+		if len(field.Names) > 0 {
+			continue
+		}
+		switch t := field.Type.(type) {
+		case *ast.BinaryExpr:
+			if t.Op == token.OR {
+				return true
+			}
+		case *ast.UnaryExpr:
+			if t.Op == token.TILDE {
+				return true
+			}
+		case *ast.Ident:
+			if obj := types.Universe.Lookup(t.Name); obj != nil {
+				if _, ok := obj.Type().Underlying().(*types.Interface); !ok || t.Name == "comparable" {
+					return true
+				}
+			}
+		case *ast.StarExpr, *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.StructType:
+			// インターフェースに埋め込める型はインターフェースのみのため、それ以外の型は型集合の項になる
+			return true
+		case *ast.SelectorExpr:
+			if pkg, ok := t.X.(*ast.Ident); ok && knownConstraints[pkg.Name+"."+t.Sel.Name] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// markEmbeddedConstraints は同じパッケージの型制約を埋め込んだインターフェースを型制約に変更します
+// 型制約を埋め込んだインターフェースも型制約になるため、変更がなくなるまで繰り返します
+func markEmbeddedConstraints(infos []TypeInfo) {
+	for changed := true; changed; {
+		changed = false
+		constraints := map[string]bool{}
+		for _, info := range infos {
+			if info.Kind == "constraint" {
+				constraints[info.Name] = true
+			}
+		}
+
+		for i := range infos {
+			if infos[i].Kind != "interface" || !embedsConstraint(infos[i].Methods, constraints) {
+				continue
+			}
+			infos[i].Kind = "constraint"
+			infos[i].Definition = fmt.Sprintf("type %s%s interface{ %s }", infos[i].Name, formatTypeParams(infos[i].TypeParams), strings.Join(infos[i].Methods, "; "))
+			changed = true
+		}
+	}
+}
+
+// embedsConstraint はインターフェースの要素に constraints のいずれかの型を埋め込んだものがあるかを判定します
+func embedsConstraint(elements []string, constraints map[string]bool) bool {
+	for _, element := range elements {
+		if m := embeddedTypeNamePattern.FindStringSubmatch(element); m != nil && constraints[m[1]] {
+			return true
+		}
+	}
+	return false
+}
+
+// specComment はグループ化された宣言の要素にドキュメントコメントがある場合はそれを、なければ宣言全体のコメントを返します
+func specComment(declComment string, doc *ast.CommentGroup) string {
+	if doc != nil {
+		return doc.Text()
+	}
+	return declComment
+}
+
+// deprecationNote はドキュメントコメントの "Deprecated:" で始まる段落を探し、その本文を1行にまとめて返します
+// 段落がない場合は false を返します
+func deprecationNote(comment string) (string, bool) {
+	for _, paragraph := range strings.Split(comment, "\n\n") {
+		if note, ok := strings.CutPrefix(strings.TrimSpace(paragraph), "Deprecated:"); ok {
+			return strings.Join(strings.Fields(note), " "), true
+		}
+	}
+	return "", false
+}
+
+// withoutDeprecation はドキュメントコメントから "Deprecated:" で始まる段落を除きます
+func withoutDeprecation(comment string) string {
+	var paragraphs []string
+	for _, paragraph := range strings.Split(comment, "\n\n") {
+		if !strings.HasPrefix(strings.TrimSpace(paragraph), "Deprecated:") {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// FilterDeprecated は非推奨の宣言を除いた公開APIを返します
+func FilterDeprecated(api []TypeInfo) []TypeInfo {
+	var filtered []TypeInfo
+	for _, info := range api {
+		if !info.Deprecated {
+			filtered = append(filtered, info)
+		}
+	}
+	return filtered
+}
+
+// interfaceMethods はインターフェースのメソッドのシグネチャと埋め込まれた型を返します
+func interfaceMethods(fset *token.FileSet, iface *ast.InterfaceType) []string {
+	var methods []string
+	for _, field := range iface.Methods.List {
+		if len(field.Names) == 0 {
+			// 埋め込まれたインターフェースや型制約
+			methods = append(methods, formatNode(fset, field.Type))
+			continue
+		}
+		signature := strings.TrimPrefix(formatNode(fset, field.Type), "func")
+		for _, name := range field.Names {
+			methods = append(methods, name.Name+signature)
+		}
+	}
+	return methods
+}
+
+// resultTypeNames は関数の戻り値の型名を返します（ポインタやスライス、型引数は取り除きます）
+func resultTypeNames(ft *ast.FuncType) []string {
+	if ft.Results == nil {
+		return nil
+	}
+
+	var names []string
+	for _, field := range ft.Results.List {
+		name := baseTypeName(field.Type)
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// baseTypeName は型の式から型名を取り出します（例: *Client → Client, []http.Handler → http.Handler）
+func baseTypeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ArrayType:
+			expr = e.Elt
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		case *ast.SelectorExpr:
+			if pkg, ok := e.X.(*ast.Ident); ok {
+				return pkg.Name + "." + e.Sel.Name
+			}
+			return e.Sel.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// formatNode は構文木のノードを Go のソースコードとして整形します
+func formatNode(fset *token.FileSet, node any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
 }
 
 // ExtractTypeInfo はGoコードから型情報を抽出します
@@ -187,3 +444,135 @@ func (p *Parser) ExtractTypeInfo(src string) []TypeInfo {
 	}
 	return typeInfos
 }
+
+// Declaration はソースコード内の宣言の位置と内容を表す構造体です
+type Declaration struct {
+	// ファイル名
+	Filename string `json:"filename"`
+	// 宣言の行範囲（ドキュメントコメントを含む）
+	Lines LineRange `json:"lines"`
+	// ドキュメントコメントを含む宣言のソースコード
+	Source string `json:"source"`
+}
+
+// FindDeclaration はソースコードから指定したシンボルの宣言を探します
+// シンボルは "Name" または "Type.Method" の形式で指定し、見つからない場合は nil を返します
+func (p *Parser) FindDeclaration(filename string, src string, symbol string) (*Declaration, error) {
+	// ファイルセットを作成
+	fset := token.NewFileSet()
+
+	// ソースコードを解析
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("ファイルの解析に失敗しました: %w", err)
+	}
+
+	recvName, name := "", symbol
+	if dot := strings.Index(symbol, "."); dot != -1 {
+		recvName, name = symbol[:dot], symbol[dot+1:]
+	}
+
+	// 宣言を探して、ドキュメントコメントを含む範囲を決める
+	var start, end token.Pos
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Name.Name == name && receiverTypeName(d) == recvName {
+				start, end = d.Pos(), d.End()
+				if d.Doc != nil {
+					start = d.Doc.Pos()
+				}
+			}
+		case *ast.GenDecl:
+			if recvName != "" {
+				continue
+			}
+			if spec := findSpec(d, name); spec != nil {
+				// 単独の宣言は宣言全体、グループ内の宣言はその要素のみを対象とする
+				if d.Lparen.IsValid() {
+					start, end = spec.Pos(), spec.End()
+					if doc := specDoc(spec); doc != nil {
+						start = doc.Pos()
+					}
+				} else {
+					start, end = d.Pos(), d.End()
+					if d.Doc != nil {
+						start = d.Doc.Pos()
+					}
+				}
+			}
+		}
+		if start.IsValid() {
+			break
+		}
+	}
+
+	if !start.IsValid() {
+		return nil, nil
+	}
+
+	startPos := fset.Position(start)
+	endPos := fset.Position(end)
+
+	// 行頭から切り出す
+	lineStart := startPos.Offset - (startPos.Column - 1)
+
+	return &Declaration{
+		Filename: filename,
+		Lines:    LineRange{Start: startPos.Line, End: endPos.Line},
+		Source:   src[lineStart:endPos.Offset],
+	}, nil
+}
+
+// receiverTypeName はメソッドのレシーバーの型名を返します（関数の場合は空文字列）
+func receiverTypeName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return ""
+	}
+
+	expr := decl.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	// ジェネリック型のレシーバー（T[K] や T[K, V]）は型名のみを取り出す
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		expr = e.X
+	case *ast.IndexListExpr:
+		expr = e.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// findSpec は一般的な宣言から指定した名前の型、変数、定数の宣言を探します
+func findSpec(decl *ast.GenDecl, name string) ast.Spec {
+	for _, spec := range decl.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			if s.Name.Name == name {
+				return s
+			}
+		case *ast.ValueSpec:
+			for _, ident := range s.Names {
+				if ident.Name == name {
+					return s
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// specDoc はグループ内の宣言要素のドキュメントコメントを返します
+func specDoc(spec ast.Spec) *ast.CommentGroup {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Doc
+	case *ast.ValueSpec:
+		return s.Doc
+	}
+	return nil
+}
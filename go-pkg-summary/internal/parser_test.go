@@ -0,0 +1,326 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const declarationSource = `package sample
+
+// Logger はログを出力します
+type Logger struct {
+	name string
+}
+
+// Info は情報ログを出力します
+func (l *Logger) Info(msg string) {}
+
+// Cache はジェネリックなキャッシュです
+type Cache[K comparable, V any] struct{}
+
+// Get は値を取得します
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	return zero, false
+}
+
+const (
+	// LevelDebug はデバッグレベルです
+	LevelDebug = iota
+	// LevelInfo は情報レベルです
+	LevelInfo
+)
+
+// New は新しいLoggerを作成します
+func New() *Logger { return &Logger{} }
+`
+
+func TestFindDeclaration(t *testing.T) {
+	p := NewParser(false)
+
+	tests := []struct {
+		symbol string
+		lines  LineRange
+		source string
+	}{
+		{
+			symbol: "Logger",
+			lines:  LineRange{Start: 3, End: 6},
+			source: "// Logger はログを出力します\ntype Logger struct {\n\tname string\n}",
+		},
+		{
+			symbol: "Logger.Info",
+			lines:  LineRange{Start: 8, End: 9},
+			source: "// Info は情報ログを出力します\nfunc (l *Logger) Info(msg string) {}",
+		},
+		{
+			symbol: "Cache.Get",
+			lines:  LineRange{Start: 14, End: 18},
+			source: "// Get は値を取得します\nfunc (c *Cache[K, V]) Get(key K) (V, bool) {\n\tvar zero V\n\treturn zero, false\n}",
+		},
+		{
+			symbol: "LevelInfo",
+			lines:  LineRange{Start: 23, End: 24},
+			source: "\t// LevelInfo は情報レベルです\n\tLevelInfo",
+		},
+		{
+			symbol: "New",
+			lines:  LineRange{Start: 27, End: 28},
+			source: "// New は新しいLoggerを作成します\nfunc New() *Logger { return &Logger{} }",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			decl, err := p.FindDeclaration("sample.go", declarationSource, tt.symbol)
+			require.NoError(t, err)
+			require.NotNil(t, decl)
+			assert.Equal(t, "sample.go", decl.Filename)
+			assert.Equal(t, tt.lines, decl.Lines)
+			assert.Equal(t, tt.source, decl.Source)
+		})
+	}
+}
+
+func TestFindDeclarationNotFound(t *testing.T) {
+	p := NewParser(false)
+
+	decl, err := p.FindDeclaration("sample.go", declarationSource, "Missing")
+	require.NoError(t, err)
+	assert.Nil(t, decl)
+
+	decl, err = p.FindDeclaration("sample.go", declarationSource, "Logger.Missing")
+	require.NoError(t, err)
+	assert.Nil(t, decl, "存在しないメソッドは見つからないこと")
+
+	decl, err = p.FindDeclaration("sample.go", declarationSource, "Cache.Info")
+	require.NoError(t, err)
+	assert.Nil(t, decl, "別の型のメソッドは一致しないこと")
+}
+
+const deprecatedSource = `package sample
+
+// Dial は接続します
+//
+// Deprecated: DialContext を使用してください。
+// Dial はタイムアウトを指定できません。
+func Dial(addr string) error { return nil }
+
+// DialContext はコンテキストを指定して接続します
+func DialContext(addr string) error { return nil }
+
+// Deprecated: 使用しないでください。
+type Legacy struct{}
+
+const (
+	// ModeA はモード A です
+	ModeA = iota
+	// ModeB はモード B です
+	//
+	// Deprecated: ModeA と同じです。
+	ModeB
+)
+`
+
+func TestParseFileDeprecated(t *testing.T) {
+	p := NewParser(false)
+
+	infos, err := p.ParseFile("sample.go", deprecatedSource)
+	require.NoError(t, err)
+
+	deprecated := map[string]string{}
+	for _, info := range infos {
+		if info.Deprecated {
+			deprecated[info.Name] = info.Replacement
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"Dial":   "DialContext を使用してください。 Dial はタイムアウトを指定できません。",
+		"Legacy": "使用しないでください。",
+		"ModeB":  "ModeA と同じです。",
+	}, deprecated)
+
+	var names []string
+	for _, info := range FilterDeprecated(infos) {
+		names = append(names, info.Name)
+	}
+	assert.Equal(t, []string{"DialContext", "ModeA"}, names)
+}
+
+func TestDeprecationNote(t *testing.T) {
+	note, ok := deprecationNote("Foo は古い API です\n\nDeprecated: Bar を使用してください。\n")
+	assert.True(t, ok)
+	assert.Equal(t, "Bar を使用してください。", note)
+
+	_, ok = deprecationNote("Foo は Deprecated: で始まる段落を持ちません\n")
+	assert.False(t, ok, "段落の先頭以外の Deprecated: は対象外であること")
+
+	assert.Equal(t, "Foo は古い API です", withoutDeprecation("Foo は古い API です\n\nDeprecated: Bar を使用してください。\n"))
+}
+
+const genericSource = `package sample
+
+// Number は数値型の型制約です
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// Key はマップのキーに使える型の型制約です
+type Key interface {
+	comparable
+	String() string
+}
+
+// Stringer は文字列に変換できる型です
+type Stringer interface {
+	String() string
+}
+
+// Set は要素の集合です
+type Set[T comparable] struct{}
+
+// Add は要素を追加します
+func (s *Set[T]) Add(v T) {}
+
+// Reducer は値を集約する関数型です
+type Reducer[T, A any] func(acc A, v T) A
+
+// Map はスライスの各要素を変換します
+func Map[T, U any](s []T, f func(T) U) []U { return nil }
+
+// Sum は数値の合計を返します
+func Sum[K comparable, V Number](m map[K]V) V { return 0 }
+`
+
+func TestParseFileGenerics(t *testing.T) {
+	p := NewParser(false)
+
+	infos, err := p.ParseFile("sample.go", genericSource)
+	require.NoError(t, err)
+
+	api := map[string]TypeInfo{}
+	for _, info := range infos {
+		api[apiSymbol(info)] = info
+	}
+
+	assert.Equal(t, "constraint", api["Number"].Kind)
+	assert.Equal(t, "type Number interface{ ~int | ~int64 | ~float64 }", api["Number"].Definition)
+	assert.Equal(t, "constraint", api["Key"].Kind, "comparable を埋め込んだインターフェースは型制約であること")
+	assert.Equal(t, "interface", api["Stringer"].Kind)
+
+	assert.Equal(t, "struct", api["Set"].Kind)
+	assert.Equal(t, "type Set[T comparable] struct", api["Set"].Definition)
+	assert.Equal(t, []TypeParam{{Name: "T", Constraint: "comparable"}}, api["Set"].TypeParams)
+	assert.Equal(t, "func (*Set) Add()", api["Set.Add"].Definition)
+	assert.Empty(t, api["Set.Add"].TypeParams, "メソッドはレシーバーの型パラメータを持たないこと")
+
+	assert.Equal(t, "type Reducer[T, A any] func(acc A, v T) A", api["Reducer"].Signature)
+	assert.True(t, isTypeDecl(api["Reducer"]))
+
+	assert.Equal(t, "func Map[T, U any]()", api["Map"].Definition)
+	assert.Equal(t, []TypeParam{{Name: "T", Constraint: "any"}, {Name: "U", Constraint: "any"}}, api["Map"].TypeParams)
+	assert.Equal(t, "func Sum[K comparable, V Number]()", api["Sum"].Definition)
+	assert.Equal(t, "func Sum[K comparable, V Number](m map[K]V) V", api["Sum"].Signature)
+}
+
+func TestParseFileConstraintKinds(t *testing.T) {
+	src := `package sample
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Setter はポインタのメソッドを要求する型制約です
+type Setter[T any] interface {
+	*T
+	Set(string)
+}
+
+// Bytes はバイト列の型制約です
+type Bytes interface{ []byte }
+
+// Mapping はマップの型制約です
+type Mapping[K comparable, V any] interface{ map[K]V }
+
+// Receiver はチャネルの型制約です
+type Receiver[T any] interface{ chan T }
+
+// Callback は関数の型制約です
+type Callback interface{ func() }
+
+// Point は構造体の型制約です
+type Point interface{ struct{ X, Y int } }
+
+// Ordered は他のパッケージの型制約を埋め込んだ型制約です
+type Ordered interface{ constraints.Ordered }
+
+type signed interface{ ~int | ~int64 }
+
+// Signed は同じパッケージの非公開の型制約を埋め込んだ型制約です
+type Signed interface{ signed }
+
+// Number は型制約を埋め込んだ型制約を、さらに埋め込んだ型制約です
+type Number interface {
+	Signed
+	String() string
+}
+
+// Named は型制約を埋め込んでいないインターフェースです
+type Named interface {
+	fmt.Stringer
+	Name() string
+}
+`
+	p := NewParser(false)
+	infos, err := p.ParseFile("sample.go", src)
+	require.NoError(t, err)
+
+	kinds := map[string]string{}
+	definitions := map[string]string{}
+	for _, info := range infos {
+		kinds[info.Name] = info.Kind
+		definitions[info.Name] = info.Definition
+	}
+
+	tests := []struct {
+		name string
+		kind string
+	}{
+		{name: "Setter", kind: "constraint"},
+		{name: "Bytes", kind: "constraint"},
+		{name: "Mapping", kind: "constraint"},
+		{name: "Receiver", kind: "constraint"},
+		{name: "Callback", kind: "constraint"},
+		{name: "Point", kind: "constraint"},
+		{name: "Ordered", kind: "constraint"},
+		{name: "Signed", kind: "constraint"},
+		{name: "Number", kind: "constraint"},
+		{name: "Named", kind: "interface"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.kind, kinds[tt.name])
+		})
+	}
+	assert.Equal(t, "type Setter[T any] interface{ *T; Set(string) }", definitions["Setter"])
+	assert.Equal(t, "type Number interface{ Signed; String() string }", definitions["Number"], "埋め込みから判定した型制約も型集合の要素を定義とすること")
+}
+
+func TestMarkEmbeddedConstraints(t *testing.T) {
+	// 別のファイルで定義された型制約を埋め込んだインターフェース
+	infos := []TypeInfo{
+		{Name: "Float", Kind: "interface", Methods: []string{"Real[T]"}, TypeParams: []TypeParam{{Name: "T", Constraint: "any"}}},
+		{Name: "Real", Kind: "constraint", Methods: []string{"~float32 | ~float64"}},
+		{Name: "Reader", Kind: "interface", Methods: []string{"Read(p []byte) (n int, err error)"}},
+	}
+
+	markEmbeddedConstraints(infos)
+
+	assert.Equal(t, "constraint", infos[0].Kind, "型引数付きで埋め込んだ型制約も判定すること")
+	assert.Equal(t, "type Float[T any] interface{ Real[T] }", infos[0].Definition)
+	assert.Equal(t, "interface", infos[2].Kind)
+}
@@ -0,0 +1,426 @@
+// Package privatemod は GOPRIVATE に一致する非公開モジュールを、pkg.go.dev や公開のモジュールプロキシを使わずに git で直接取得する機能を提供します
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// privateModuleDirName はキャッシュに保存する非公開モジュールのソースコードのディレクトリ名です
+	privateModuleDirName = "git"
+)
+
+// goEnvSetting は go コマンドの設定値を返します
+// 環境変数を優先し、設定されていない場合は go env -w で書き込まれる GOENV ファイルから読み込みます
+func goEnvSetting(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	envFile := os.Getenv("GOENV")
+	if envFile == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		envFile = filepath.Join(configDir, "go", "env")
+	}
+	if envFile == "off" {
+		return ""
+	}
+
+	data, err := os.ReadFile(envFile)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok && name == key {
+			return value
+		}
+	}
+	return ""
+}
+
+// privateModulePatterns は GOPRIVATE と GONOSUMDB のパターンをまとめて返します
+func privateModulePatterns() string {
+	var patterns []string
+	for _, key := range []string{"GOPRIVATE", "GONOSUMDB"} {
+		if value := goEnvSetting(key); value != "" {
+			patterns = append(patterns, value)
+		}
+	}
+	return strings.Join(patterns, ",")
+}
+
+// IsPrivateModule はインポートパスが GOPRIVATE または GONOSUMDB のパターンに一致するかを判定します
+// 一致する場合は pkg.go.dev と公開のモジュールプロキシを使用せず、git で直接取得します
+func (f *Fetcher) IsPrivateModule(importPath string) bool {
+	return f.privatePatterns != "" && module.MatchPrefixPatterns(f.privatePatterns, importPath)
+}
+
+// privateRepoRoot は非公開モジュールのリポジトリを解決します
+// "git.example.com/team/repo.git/pkg" のように ".git" を含むパスはその位置までをリポジトリとし、
+// それ以外は go-import のディスカバリー（.netrc の認証情報を使用）で解決します
+func (f *Fetcher) privateRepoRoot(importPath string) (*RepoRoot, error) {
+	if i := strings.Index(importPath, ".git"); i != -1 {
+		rest := importPath[i+len(".git"):]
+		if rest == "" || strings.HasPrefix(rest, "/") {
+			prefix := importPath[:i+len(".git")]
+			return &RepoRoot{ImportPrefix: prefix, VCS: "git", Root: "https://" + prefix, RepoURL: "https://" + strings.TrimSuffix(prefix, ".git")}, nil
+		}
+	}
+	return f.DiscoverRepoRoot(importPath)
+}
+
+// resolvePrivateModulePath は非公開モジュールのパスと、git のタグから求めたバージョン一覧を返します
+// モジュールがリポジトリのサブディレクトリにある場合は、"サブディレクトリ/v1.2.3" の形式のタグを対象とします
+func (f *Fetcher) resolvePrivateModulePath(importPath string) (string, []string, error) {
+	root, err := f.privateRepoRoot(importPath)
+	if err != nil {
+		return "", nil, err
+	}
+	if root.VCS != "git" {
+		return "", nil, fmt.Errorf("%s の VCS（%s）には対応していません", root.ImportPrefix, root.VCS)
+	}
+
+	out, err := f.runGit("", "ls-remote", "--tags", "--refs", root.Root)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tagPrefix := ""
+	if root.SubDir != "" {
+		tagPrefix = root.SubDir + "/"
+	}
+	var versions []string
+	for _, line := range strings.Split(out, "\n") {
+		_, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		version, ok := strings.CutPrefix(strings.TrimPrefix(ref, "refs/tags/"), tagPrefix)
+		if ok && semver.IsValid(version) && semver.Build(version) == "" {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return semver.Compare(versions[i], versions[j]) < 0
+	})
+	return root.ImportPrefix, versions, nil
+}
+
+// listPrivateVersions は非公開モジュールのバージョン一覧を git のタグから取得します
+// 公開日時と撤回の情報は取得しません
+func (f *Fetcher) listPrivateVersions(importPath string) (*ModuleVersions, error) {
+	modulePath, rawVersions, err := f.resolvePrivateModulePath(importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ModuleVersions{ModulePath: modulePath, Source: VersionSourceGit}
+	for i := len(rawVersions) - 1; i >= 0; i-- {
+		result.Versions = append(result.Versions, ModuleVersion{
+			Version:    rawVersions[i],
+			PreRelease: semver.Prerelease(rawVersions[i]) != "",
+		})
+	}
+	return result, nil
+}
+
+// resolvePrivateModuleVersion は非公開モジュールのパスと具体的なバージョンを返します
+// "latest" でタグがない場合は、デフォルトブランチの先頭のコミットを取得して疑似バージョンを求めます
+func (f *Fetcher) resolvePrivateModuleVersion(importPath string, version string) (string, string, error) {
+	modulePath, versions, err := f.resolvePrivateModulePath(importPath)
+	if err != nil {
+		return "", "", err
+	}
+	if version != "" && version != "latest" {
+		return modulePath, version, nil
+	}
+
+	if latest := LatestVersion(versions); latest != "" {
+		return modulePath, latest, nil
+	}
+	return f.checkoutPrivateHead(modulePath)
+}
+
+// checkoutPrivateHead はデフォルトブランチの先頭のコミットをキャッシュに取得し、その疑似バージョンを返します
+func (f *Fetcher) checkoutPrivateHead(modulePath string) (string, string, error) {
+	root, err := f.privateRepoRoot(modulePath)
+	if err != nil {
+		return "", "", err
+	}
+
+	tmpDir, err := f.fetchGitRef(root.Root, "HEAD", f.cache.GetCacheDir(modulePath, ""))
+	if err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	out, err := f.runGit(tmpDir, "log", "-1", "--format=%H %ct")
+	if err != nil {
+		return "", "", err
+	}
+	hash, unix, _ := strings.Cut(strings.TrimSpace(out), " ")
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || len(hash) < 12 {
+		return "", "", fmt.Errorf("コミット情報の取得に失敗しました: %s", out)
+	}
+
+	major := "v0"
+	if _, pathMajor, ok := module.SplitPathVersion(modulePath); ok && pathMajor != "" {
+		major = module.PathMajorPrefix(pathMajor)
+	}
+	version := module.PseudoVersion(major, "", time.Unix(seconds, 0), hash[:12])
+
+	dest := filepath.Join(f.cache.GetCacheDir(modulePath, version), privateModuleDirName)
+	if _, err := os.Stat(dest); err != nil {
+		if err := finishGitCheckout(tmpDir, dest); err != nil {
+			return "", "", err
+		}
+	}
+	return modulePath, version, nil
+}
+
+// downloadPrivateModule は非公開モジュールの特定バージョンを git で取得し、キャッシュに保存します
+// タグのバージョンはタグを、疑似バージョンはコミットを取得します
+// useCache が false の場合はキャッシュがあっても取得し直し、キャッシュを置き換えます
+func (f *Fetcher) downloadPrivateModule(modulePath string, version string, useCache bool) (*ModuleSource, error) {
+	root, err := f.privateRepoRoot(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	dest := filepath.Join(f.cache.GetCacheDir(modulePath, version), privateModuleDirName)
+	if _, err := os.Stat(dest); err != nil || !useCache {
+		ref := "refs/tags/" + path.Join(root.SubDir, version)
+		if module.IsPseudoVersion(version) {
+			rev, err := module.PseudoVersionRev(version)
+			if err != nil {
+				return nil, err
+			}
+			ref = f.expandGitRev(root.Root, rev)
+		}
+
+		tmpDir, err := f.fetchGitRef(root.Root, ref, filepath.Dir(dest))
+		if err != nil {
+			return nil, fmt.Errorf("モジュール %s@%s の取得に失敗しました: %w", modulePath, version, err)
+		}
+		defer os.RemoveAll(tmpDir)
+		if err := os.RemoveAll(dest); err != nil {
+			return nil, err
+		}
+		if err := finishGitCheckout(tmpDir, dest); err != nil {
+			return nil, err
+		}
+	} else if f.debug {
+		fmt.Printf("キャッシュからモジュールを取得しました: %s@%s\n", modulePath, version)
+	}
+
+	return &ModuleSource{
+		ModulePath: modulePath,
+		Version:    version,
+		FS:         os.DirFS(filepath.Join(dest, filepath.FromSlash(root.SubDir))),
+	}, nil
+}
+
+// expandGitRev は疑似バージョンの短いコミットハッシュを、リモートのブランチやタグの先頭と一致する場合に完全なハッシュにします
+// 一致しない場合は短いハッシュのまま返し、fetchGitRef で全ての履歴から探します
+func (f *Fetcher) expandGitRev(repoURL string, rev string) string {
+	out, err := f.runGit("", "ls-remote", repoURL)
+	if err != nil {
+		return rev
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if hash, _, ok := strings.Cut(line, "\t"); ok && strings.HasPrefix(hash, rev) {
+			return hash
+		}
+	}
+	return rev
+}
+
+// fetchGitRef は workDir に作成した一時ディレクトリに ref のコミットをチェックアウトし、そのパスを返します
+// ref を直接取得できない場合（短いコミットハッシュなど）は、全てのブランチとタグを取得してから探します
+func (f *Fetcher) fetchGitRef(repoURL string, ref string, workDir string) (string, error) {
+	if err := f.cache.EnsureDir(workDir); err != nil {
+		return "", err
+	}
+	tmpDir, err := os.MkdirTemp(workDir, ".git-")
+	if err != nil {
+		return "", err
+	}
+
+	checkout := func() error {
+		if _, err := f.runGit(tmpDir, "init", "-q"); err != nil {
+			return err
+		}
+		if _, err := f.runGit(tmpDir, "fetch", "-q", "--depth", "1", repoURL, ref); err == nil {
+			_, err = f.runGit(tmpDir, "-c", "advice.detachedHead=false", "checkout", "-q", "FETCH_HEAD")
+			return err
+		}
+		if _, err := f.runGit(tmpDir, "fetch", "-q", "--tags", repoURL, "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+			return err
+		}
+		_, err := f.runGit(tmpDir, "-c", "advice.detachedHead=false", "checkout", "-q", ref)
+		return err
+	}
+	if err := checkout(); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", err
+	}
+	return tmpDir, nil
+}
+
+// finishGitCheckout は一時ディレクトリから .git を取り除き、キャッシュのディレクトリに移動します
+func finishGitCheckout(tmpDir string, dest string) error {
+	if err := os.RemoveAll(filepath.Join(tmpDir, ".git")); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmpDir, dest)
+}
+
+// runGit は git コマンドを実行し、標準出力を返します
+// 認証は利用者の credential helper、.netrc、SSH の設定に任せ、入力を求めるプロンプトは無効にします
+func (f *Fetcher) runGit(dir string, args ...string) (string, error) {
+	if f.debug {
+		fmt.Printf("git %s\n", strings.Join(args, " "))
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if os.Getenv("GIT_SSH") == "" && os.Getenv("GIT_SSH_COMMAND") == "" {
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND=ssh -o ControlMaster=no -o BatchMode=yes")
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s に失敗しました: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// getPrivatePackageInfo は非公開モジュールのパッケージの情報をソースコードから生成します
+func (f *Fetcher) getPrivatePackageInfo(importPath string, version string) (*Package, error) {
+	source, dir, err := f.packageSource(importPath, version, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ctxt := moduleBuildContext(source.FS)
+	bp, err := ctxt.ImportDir("/"+dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("パッケージ %s の読み込みに失敗しました: %w", importPath, err)
+	}
+
+	pkg := &Package{
+		Name:       bp.Name,
+		ImportPath: importPath,
+		Version:    source.Version,
+		Synopsis:   bp.Doc,
+	}
+	if root, err := f.privateRepoRoot(importPath); err == nil {
+		pkg.RepoURL = root.RepoURL
+	}
+	if licenses, err := DetectLicenses(source); err == nil {
+		pkg.License = licenses.String()
+	}
+	return pkg, nil
+}
+
+// netrcEntry は .netrc の1つの machine（または default）の設定です
+type netrcEntry struct {
+	machine  string
+	login    string
+	password string
+}
+
+// netrcCredentials は .netrc（環境変数 NETRC で変更可能）からホストのログイン名とパスワードを返します
+// ホストに一致する machine がない場合は default の設定を使用します
+func netrcCredentials(host string) (string, string, bool) {
+	netrcPath := os.Getenv("NETRC")
+	if netrcPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", false
+		}
+		netrcPath = filepath.Join(home, ".netrc")
+	}
+
+	data, err := os.ReadFile(netrcPath)
+	if err != nil {
+		return "", "", false
+	}
+
+	var entries []netrcEntry
+	tokens := strings.Fields(string(data))
+	for i := 0; i < len(tokens); i++ {
+		next := func() string {
+			if i+1 < len(tokens) {
+				i++
+				return tokens[i]
+			}
+			return ""
+		}
+		switch tokens[i] {
+		case "machine":
+			entries = append(entries, netrcEntry{machine: next()})
+		case "default":
+			entries = append(entries, netrcEntry{})
+		case "login", "password":
+			if len(entries) == 0 {
+				continue
+			}
+			if tokens[i] == "login" {
+				entries[len(entries)-1].login = next()
+			} else {
+				entries[len(entries)-1].password = next()
+			}
+		case "macdef":
+			// マクロの定義以降は認証情報として扱わない
+			i = len(tokens)
+		}
+	}
+
+	var fallback *netrcEntry
+	for i, entry := range entries {
+		if entry.machine == host {
+			return entry.login, entry.password, true
+		}
+		if entry.machine == "" && fallback == nil {
+			fallback = &entries[i]
+		}
+	}
+	if fallback != nil {
+		return fallback.login, fallback.password, true
+	}
+	return "", "", false
+}
+
+// setNetrcAuth は HTTPS のリクエスト先のホストの認証情報が .netrc にある場合に Basic 認証を設定します
+func setNetrcAuth(req *http.Request) {
+	if req.URL.Scheme != "https" {
+		return
+	}
+	if login, password, ok := netrcCredentials(req.URL.Hostname()); ok {
+		req.SetBasicAuth(login, password)
+	}
+}
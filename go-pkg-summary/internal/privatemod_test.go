@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+)
+
+// newTestGitRepo は files をコミットしたローカルの git リポジトリを作成し、file:// の URL を返します
+// tags を指定した場合は、コミットごとに1つずつタグを付けます（空文字列の場合はタグを付けません）
+func newTestGitRepo(t *testing.T, commits []map[string]string, tags []string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "init.defaultBranch=main"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	git("init", "-q")
+	for i, files := range commits {
+		for name, content := range files {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		}
+		git("add", "-A")
+		git("commit", "-q", "-m", fmt.Sprintf("commit %d", i))
+		if i < len(tags) && tags[i] != "" {
+			git("tag", tags[i])
+		}
+	}
+	return "file://" + dir
+}
+
+// newPrivateFetcher は GOPRIVATE を設定し、pkg.go.dev と公開のモジュールプロキシへのアクセスをエラーにした Fetcher を作成します
+// go-import タグは corp.example.com/lib を repoURL のリポジトリとして返します
+func newPrivateFetcher(t *testing.T, repoURL string) *Fetcher {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GOPRIVATE", "corp.example.com")
+
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("非公開モジュールで公開のサービスにアクセスしました: %s", r.URL)
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(public.Close)
+
+	discovery := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<html><head><meta name="go-import" content="corp.example.com/lib git %s"></head></html>`, repoURL)
+	}))
+	t.Cleanup(discovery.Close)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.proxyURL = public.URL
+	f.scraper = NewScraper(false)
+	f.scraper.baseURL = public.URL
+	f.goGetBaseURL = discovery.URL
+	return f
+}
+
+func TestIsPrivateModule(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GOPRIVATE", "*.corp.example.com,git.example.org/team")
+	t.Setenv("GONOSUMDB", "")
+
+	// go env -w で書き込まれた設定も読み込む
+	envFile := filepath.Join(t.TempDir(), "env")
+	require.NoError(t, os.WriteFile(envFile, []byte("GONOSUMDB=internal.example.net\n"), 0644))
+	t.Setenv("GOENV", envFile)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+
+	for importPath, want := range map[string]bool{
+		"git.corp.example.com/platform/auth": true,
+		"git.example.org/team/lib":           true,
+		"git.example.org/other/lib":          false,
+		"internal.example.net/tools":         true,
+		"github.com/stretchr/testify":        false,
+	} {
+		assert.Equal(t, want, f.IsPrivateModule(importPath), importPath)
+	}
+}
+
+func TestPrivateModuleSummary(t *testing.T) {
+	license, err := os.ReadFile(filepath.Join("testdata", "licenses", "MIT.txt"))
+	require.NoError(t, err)
+
+	repoURL := newTestGitRepo(t, []map[string]string{
+		{
+			"go.mod":       "module corp.example.com/lib\n\ngo 1.22\n",
+			"LICENSE":      string(license),
+			"README.md":    "# lib\n\n社内向けのライブラリです。\n",
+			"auth/auth.go": "// Package auth は社内の認証を提供します。\npackage auth\n\n// Token はトークンを返します\nfunc Token() string { return \"v1\" }\n",
+		},
+		{
+			"auth/auth.go": "// Package auth は社内の認証を提供します。\npackage auth\n\n// Token はトークンを返します\nfunc Token() string { return \"v2\" }\n\n// Refresh はトークンを更新します\nfunc Refresh() {}\n",
+		},
+	}, []string{"v1.0.0", "v1.1.0"})
+	f := newPrivateFetcher(t, repoURL)
+
+	content, err := f.GetPackage("corp.example.com/lib/auth", "latest", GetPackageOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, content, "# auth\n\nインポートパス: corp.example.com/lib/auth\nバージョン: v1.1.0\n概要: Package auth は社内の認証を提供します。\n")
+	assert.Contains(t, content, "ライセンス: MIT（LICENSE）\n")
+	assert.Contains(t, content, "- auth/auth.go\n")
+	assert.Contains(t, content, "module corp.example.com/lib")
+	assert.Contains(t, content, "社内向けのライブラリです。")
+	assert.NotContains(t, content, "ドキュメントURL", "pkg.go.dev のページはないため表示しないこと")
+
+	versions, err := f.ListVersions("corp.example.com/lib/auth")
+	require.NoError(t, err)
+	assert.Equal(t, VersionSourceGit, versions.Source)
+	assert.Equal(t, "corp.example.com/lib", versions.ModulePath)
+	assert.Equal(t, []ModuleVersion{{Version: "v1.1.0"}, {Version: "v1.0.0"}}, versions.Versions)
+
+	decl, err := f.ReadPackageSymbol("corp.example.com/lib/auth", "v1.0.0", "Token")
+	require.NoError(t, err)
+	assert.Equal(t, "auth/auth.go", decl.Filename)
+	assert.Contains(t, decl.Source, `return "v1"`, "指定したタグのソースコードを取得すること")
+
+	_, err = f.ReadPackageSymbol("corp.example.com/lib/auth", "v1.0.0", "Refresh")
+	assert.Error(t, err)
+}
+
+func TestPrivateModuleWithoutTags(t *testing.T) {
+	repoURL := newTestGitRepo(t, []map[string]string{
+		{"go.mod": "module corp.example.com/lib\n", "lib.go": "// Package lib はタグのないライブラリです。\npackage lib\n"},
+	}, nil)
+	f := newPrivateFetcher(t, repoURL)
+
+	modulePath, version, err := f.ResolveModuleVersion("corp.example.com/lib", "latest")
+	require.NoError(t, err)
+	assert.Equal(t, "corp.example.com/lib", modulePath)
+	assert.True(t, module.IsPseudoVersion(version), "タグがない場合は疑似バージョンになること: %s", version)
+
+	source, err := f.DownloadModule(modulePath, version, true)
+	require.NoError(t, err)
+	files, err := source.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go.mod", "lib.go"}, files, ".git は含まれないこと")
+
+	// キャッシュを削除しても疑似バージョンのコミットを取得できること
+	require.NoError(t, os.RemoveAll(f.cache.GetCacheDir(modulePath, version)))
+	source, err = f.DownloadModule(modulePath, version, true)
+	require.NoError(t, err)
+	_, err = source.ReadFile("lib.go")
+	assert.NoError(t, err)
+
+	// キャッシュを使用しない場合は取得し直したものでキャッシュを置き換えること
+	source, err = f.DownloadModule(modulePath, version, false)
+	require.NoError(t, err)
+	_, err = source.ReadFile("lib.go")
+	assert.NoError(t, err)
+}
+
+func TestNetrcCredentials(t *testing.T) {
+	netrc := filepath.Join(t.TempDir(), ".netrc")
+	require.NoError(t, os.WriteFile(netrc, []byte(`machine git.corp.example.com
+  login alice
+  password s3cret
+machine other.example.com login bob password hunter2
+default login anonymous password guest
+`), 0600))
+	t.Setenv("NETRC", netrc)
+
+	login, password, ok := netrcCredentials("git.corp.example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "alice", login)
+	assert.Equal(t, "s3cret", password)
+
+	login, _, ok = netrcCredentials("other.example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "bob", login)
+
+	login, _, ok = netrcCredentials("unknown.example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "anonymous", login, "一致する machine がない場合は default を使用すること")
+
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "missing"))
+	_, _, ok = netrcCredentials("git.corp.example.com")
+	assert.False(t, ok)
+}
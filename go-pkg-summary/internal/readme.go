@@ -0,0 +1,478 @@
+// Package readme は README の Markdown を解析し、サマリーに含めるために整形する機能を提供します
+package internal
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+	"golang.org/x/mod/module"
+)
+
+var (
+	// readmeLinkPattern はインラインのリンクと画像、参照形式のリンクと画像に一致します
+	// リンクテキストには1段階の角括弧（バッジ画像を含むリンクなど）を含められます
+	readmeLinkPattern = regexp.MustCompile(`(!?)\[((?:[^\[\]]|\[[^\[\]]*\])*)\](?:\(([^()\s]+)((?:\s+"[^"]*")?)\)|\[([^\[\]]*)\])`)
+	// readmeDefinitionPattern はリンク参照定義（[label]: url）に一致します
+	readmeDefinitionPattern = regexp.MustCompile(`(?m)^ {0,3}\[([^\]]+)\]:[ \t]*<?([^\s>]+)>?.*$`)
+	// readmeBlankLinesPattern は3行以上連続する空行に一致します
+	readmeBlankLinesPattern = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+)
+
+// badgeURLMarkers はバッジ画像の URL に含まれる文字列です
+var badgeURLMarkers = []string{
+	"badge", "shields.io", "travis-ci.", "coveralls.io", "codecov.io", "goreportcard.com",
+	"circleci.com", "ci.appveyor.com", "status.svg", "app.fossa.", "bestpractices.dev",
+}
+
+// ReadmeOptions は README の整形オプションを表す構造体です
+type ReadmeOptions struct {
+	// 相対リンクを解決する基準の URL（末尾はスラッシュ）。空の場合は相対リンクをそのまま残す
+	LinkBase string
+	// 相対パスの画像を解決する基準の URL（末尾はスラッシュ）
+	ImageBase string
+	// 指定した場合、見出しがいずれかを含むセクションのみを残す（大文字と小文字は区別しない）
+	Sections []string
+}
+
+// CodeExample は README から抽出した Go のコード例を表す構造体です
+type CodeExample struct {
+	// コード例の直前の見出し
+	Heading string `json:"heading,omitempty"`
+	// コード
+	Code string `json:"code"`
+}
+
+// Readme は整形した README を表す構造体です
+type Readme struct {
+	// 整形した Markdown
+	Content string `json:"content"`
+	// 本文から取り出した Go のコード例
+	Examples []CodeExample `json:"examples,omitempty"`
+}
+
+// readmeEdit は README の本文に対する置き換えを表します
+type readmeEdit struct {
+	start, end  int
+	replacement string
+}
+
+// readmeHeading は README の見出しを表します
+type readmeHeading struct {
+	level int
+	title string
+	start int
+}
+
+// ProcessReadme は README を解析し、バッジと HTML ブロックの削除、相対リンクの絶対 URL への書き換え、
+// セクションの絞り込み、Go のコード例の抽出を行います
+func ProcessReadme(content string, opts ReadmeOptions) *Readme {
+	source := []byte(content)
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+
+	var (
+		edits     []readmeEdit
+		protected [][2]int
+		headings  []readmeHeading
+		examples  []CodeExample
+		// コード例の開始位置（examples と同じ順）
+		exampleStarts []int
+	)
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Heading:
+			if node.Lines().Len() > 0 {
+				seg := node.Lines().At(0)
+				title := strings.TrimSpace(strings.TrimRight(string(seg.Value(source)), "# "))
+				headings = append(headings, readmeHeading{level: node.Level, title: title, start: lineStart(source, seg.Start)})
+			}
+		case *ast.FencedCodeBlock:
+			start, end, ok := fencedBlockRange(source, node)
+			if !ok {
+				return ast.WalkSkipChildren, nil
+			}
+			protected = append(protected, [2]int{start, end})
+			lang := strings.ToLower(string(node.Language(source)))
+			if (lang == "go" || lang == "golang") && node.Lines().Len() > 0 {
+				examples = append(examples, CodeExample{Code: string(node.Lines().Value(source))})
+				exampleStarts = append(exampleStarts, start)
+				edits = append(edits, readmeEdit{start: start, end: end})
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeBlock:
+			if node.Lines().Len() > 0 {
+				protected = append(protected, [2]int{node.Lines().At(0).Start, node.Lines().At(node.Lines().Len() - 1).Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock:
+			if node.Lines().Len() > 0 {
+				start := lineStart(source, node.Lines().At(0).Start)
+				end := node.Lines().At(node.Lines().Len() - 1).Stop
+				if node.HasClosure() {
+					end = node.ClosureLine.Stop
+				}
+				edits = append(edits, readmeEdit{start: start, end: end})
+				protected = append(protected, [2]int{start, end})
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			for i := 0; i < node.Segments.Len(); i++ {
+				seg := node.Segments.At(i)
+				edits = append(edits, readmeEdit{start: seg.Start, end: seg.Stop})
+				protected = append(protected, [2]int{seg.Start, seg.Stop})
+			}
+		case *ast.CodeSpan:
+			for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+				if t, ok := c.(*ast.Text); ok {
+					protected = append(protected, [2]int{t.Segment.Start, t.Segment.Stop})
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	// コード例にはその直前の見出しを付ける
+	for i, start := range exampleStarts {
+		for _, h := range headings {
+			if h.start < start {
+				examples[i].Heading = h.title
+			}
+		}
+	}
+
+	// リンク参照定義
+	definitions := map[string]string{}
+	var definitionLines []readmeEdit
+	for _, m := range readmeDefinitionPattern.FindAllSubmatchIndex(source, -1) {
+		if overlaps(protected, m[0], m[1]) {
+			continue
+		}
+		label := strings.ToLower(string(source[m[2]:m[3]]))
+		dest := string(source[m[4]:m[5]])
+		definitions[label] = dest
+		if isBadgeURL(dest) {
+			edits = append(edits, readmeEdit{start: m[0], end: m[1]})
+			continue
+		}
+
+		base := opts.LinkBase
+		if isImagePath(dest) {
+			base = opts.ImageBase
+		}
+		resolved := resolveReadmeURL(base, dest)
+		if resolved != dest {
+			edits = append(edits, readmeEdit{start: m[4], end: m[5], replacement: resolved})
+		}
+		line := string(source[m[0]:m[4]]) + resolved + string(source[m[5]:m[1]])
+		definitionLines = append(definitionLines, readmeEdit{start: m[0], end: m[1], replacement: strings.TrimSpace(line)})
+	}
+
+	// インラインのリンクと画像
+	for _, m := range readmeLinkPattern.FindAllSubmatchIndex(source, -1) {
+		if overlaps(protected, m[0], m[1]) {
+			continue
+		}
+		original := string(source[m[0]:m[1]])
+		replacement := rewriteReadmeLink(original, definitions, opts)
+		if replacement != original {
+			edits = append(edits, readmeEdit{start: m[0], end: m[1], replacement: replacement})
+		}
+	}
+
+	// 指定したセクション以外を削除
+	removed := sectionEdits(source, headings, opts.Sections)
+	edits = append(edits, removed...)
+
+	result := applyReadmeEdits(source, edits)
+
+	// 削除したセクションにあるリンク参照定義は、残したセクションから参照できるよう末尾に移す
+	var moved []string
+	for _, def := range definitionLines {
+		for _, r := range removed {
+			if r.start <= def.start && def.end <= r.end {
+				moved = append(moved, def.replacement)
+				break
+			}
+		}
+	}
+	if len(moved) > 0 {
+		result = strings.TrimSpace(result) + "\n\n" + strings.Join(moved, "\n")
+	}
+
+	result = readmeBlankLinesPattern.ReplaceAllString(result, "\n\n")
+	return &Readme{Content: strings.TrimSpace(result), Examples: examples}
+}
+
+// rewriteReadmeLink はリンクまたは画像の Markdown を書き換えます
+// バッジ画像と、バッジ画像のみを含むリンクは削除します
+func rewriteReadmeLink(link string, definitions map[string]string, opts ReadmeOptions) string {
+	m := readmeLinkPattern.FindStringSubmatchIndex(link)
+	if m == nil {
+		return link
+	}
+	isImage := m[3] > m[2]
+	label := link[m[4]:m[5]]
+
+	dest, inline := "", m[6] >= 0
+	if inline {
+		dest = link[m[6]:m[7]]
+	} else {
+		ref := link[m[10]:m[11]]
+		if ref == "" {
+			ref = label
+		}
+		dest = definitions[strings.ToLower(ref)]
+	}
+
+	if isImage {
+		if isBadgeURL(dest) {
+			return ""
+		}
+	} else {
+		// リンクテキストの画像を先に処理し、バッジのみのリンクは削除する
+		inner := strings.TrimSpace(label)
+		if im := readmeLinkPattern.FindStringIndex(inner); im != nil && im[0] == 0 && im[1] == len(inner) && strings.HasPrefix(inner, "!") {
+			rewritten := rewriteReadmeLink(inner, definitions, opts)
+			if rewritten == "" {
+				return ""
+			}
+			link = link[:m[4]] + rewritten + link[m[5]:]
+			m = readmeLinkPattern.FindStringSubmatchIndex(link)
+		}
+	}
+
+	if !inline {
+		return link
+	}
+	base := opts.LinkBase
+	if isImage {
+		base = opts.ImageBase
+	}
+	resolved := resolveReadmeURL(base, link[m[6]:m[7]])
+	return link[:m[6]] + resolved + link[m[7]:]
+}
+
+// sectionEdits は指定したセクション以外を削除する置き換えを返します
+// 見出しが1つも一致しない場合は何も削除しません
+func sectionEdits(source []byte, headings []readmeHeading, sections []string) []readmeEdit {
+	if len(sections) == 0 {
+		return nil
+	}
+
+	// 残すセクションの範囲（見出しから、同じかより上位の次の見出しまで）
+	var kept [][2]int
+	for i, h := range headings {
+		if !matchesSection(h.title, sections) {
+			continue
+		}
+		end := len(source)
+		for _, next := range headings[i+1:] {
+			if next.level <= h.level {
+				end = next.start
+				break
+			}
+		}
+		kept = append(kept, [2]int{h.start, end})
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	var edits []readmeEdit
+	cursor := 0
+	for _, r := range kept {
+		if r[0] > cursor {
+			edits = append(edits, readmeEdit{start: cursor, end: r[0]})
+		}
+		if r[1] > cursor {
+			cursor = r[1]
+		}
+	}
+	if cursor < len(source) {
+		edits = append(edits, readmeEdit{start: cursor, end: len(source)})
+	}
+	return edits
+}
+
+// matchesSection は見出しが指定したセクション名のいずれかを含むかを判定します
+func matchesSection(title string, sections []string) bool {
+	lower := strings.ToLower(title)
+	for _, section := range sections {
+		if section = strings.ToLower(strings.TrimSpace(section)); section != "" && strings.Contains(lower, section) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyReadmeEdits は置き換えを開始位置の順に適用します
+// 先に適用した置き換えと重なるもの（削除したセクション内のリンクなど）は無視します
+func applyReadmeEdits(source []byte, edits []readmeEdit) string {
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+
+	var output strings.Builder
+	cursor := 0
+	for _, e := range edits {
+		if e.start < cursor {
+			continue
+		}
+		output.Write(source[cursor:e.start])
+		output.WriteString(e.replacement)
+		cursor = e.end
+	}
+	output.Write(source[cursor:])
+	return output.String()
+}
+
+// fencedBlockRange はフェンスの行を含むコードブロックの範囲を返します
+// 情報文字列も本文もないコードブロックは位置を特定できないため ok は false になります
+func fencedBlockRange(source []byte, node *ast.FencedCodeBlock) (start int, end int, ok bool) {
+	switch {
+	case node.Info != nil:
+		start = lineStart(source, node.Info.Segment.Start)
+	case node.Lines().Len() > 0:
+		start = lineStart(source, lineStart(source, node.Lines().At(0).Start)-1)
+	default:
+		return 0, 0, false
+	}
+
+	end = start
+	if node.Lines().Len() > 0 {
+		end = node.Lines().At(node.Lines().Len() - 1).Stop
+	} else if i := strings.IndexByte(string(source[start:]), '\n'); i >= 0 {
+		end = start + i + 1
+	}
+	// 閉じるフェンスの行
+	if i := strings.IndexByte(string(source[end:]), '\n'); i >= 0 {
+		end += i + 1
+	} else {
+		end = len(source)
+	}
+	return start, end, true
+}
+
+// lineStart は位置を含む行の先頭の位置を返します
+func lineStart(source []byte, pos int) int {
+	if pos <= 0 {
+		return 0
+	}
+	if pos > len(source) {
+		pos = len(source)
+	}
+	for pos > 0 && source[pos-1] != '\n' {
+		pos--
+	}
+	return pos
+}
+
+// overlaps は範囲が保護された範囲のいずれかと重なるかを判定します
+func overlaps(ranges [][2]int, start int, end int) bool {
+	for _, r := range ranges {
+		if start < r[1] && r[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// isBadgeURL は URL がバッジ画像のものかを判定します
+func isBadgeURL(u string) bool {
+	lower := strings.ToLower(u)
+	for _, marker := range badgeURLMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isImagePath はパスが画像ファイルのものかを判定します
+func isImagePath(p string) bool {
+	switch strings.ToLower(path.Ext(strings.SplitN(p, "?", 2)[0])) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp":
+		return true
+	}
+	return false
+}
+
+// resolveReadmeURL は README からの相対パスを基準の URL で絶対 URL に変換します
+// アンカー、スキームを持つ URL、基準の URL がない場合はそのまま返します
+func resolveReadmeURL(base string, dest string) string {
+	if base == "" || dest == "" || strings.HasPrefix(dest, "#") || strings.HasPrefix(dest, "//") {
+		return dest
+	}
+	if i := strings.IndexAny(dest, ":/?#"); i >= 0 && dest[i] == ':' {
+		return dest
+	}
+
+	p, suffix := dest, ""
+	if i := strings.IndexAny(dest, "?#"); i >= 0 {
+		p, suffix = dest[:i], dest[i:]
+	}
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	return base + p + suffix
+}
+
+// ReadmeBaseURLs はリポジトリの URL とバージョンから、README の相対リンクと画像を解決する基準の URL を返します
+// タグ付きのバージョンはタグ、疑似バージョンはコミット、latest は HEAD を参照します
+func ReadmeBaseURLs(repoURL string, version string) (string, string) {
+	repoURL = strings.TrimSuffix(repoURL, "/")
+	if repoURL == "" {
+		return "", ""
+	}
+
+	ref := version
+	switch {
+	case version == "" || version == "latest":
+		ref = "HEAD"
+	case module.IsPseudoVersion(version):
+		if rev, err := module.PseudoVersionRev(version); err == nil {
+			ref = rev
+		}
+	}
+
+	switch {
+	case strings.Contains(repoURL, "github.com/"):
+		repoPath := strings.SplitN(repoURL, "github.com/", 2)[1]
+		return fmt.Sprintf("%s/blob/%s/", repoURL, ref), fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/", repoPath, ref)
+	case strings.Contains(repoURL, "gitlab.com/"):
+		return fmt.Sprintf("%s/-/blob/%s/", repoURL, ref), fmt.Sprintf("%s/-/raw/%s/", repoURL, ref)
+	default:
+		return repoURL + "/", repoURL + "/"
+	}
+}
+
+// FormatUsageExamples はコード例を Markdown の Usage examples セクションに整形します
+func FormatUsageExamples(examples []CodeExample) string {
+	if len(examples) == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	output.WriteString("## Usage examples\n\n")
+	for _, example := range examples {
+		if example.Heading != "" {
+			output.WriteString(fmt.Sprintf("### %s\n\n", example.Heading))
+		}
+		output.WriteString("```go\n")
+		output.WriteString(strings.TrimRight(example.Code, "\n"))
+		output.WriteString("\n```\n\n")
+	}
+	return output.String()
+}
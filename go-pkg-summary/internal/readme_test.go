@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// processReadmeFixture は testdata/readme/input.md を github.com/example/mylib@v1.2.0 の README として整形します
+func processReadmeFixture(t *testing.T, sections []string) *Readme {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "readme", "input.md"))
+	require.NoError(t, err)
+
+	linkBase, imageBase := ReadmeBaseURLs("https://github.com/example/mylib", "v1.2.0")
+	return ProcessReadme(string(data), ReadmeOptions{LinkBase: linkBase, ImageBase: imageBase, Sections: sections})
+}
+
+func TestProcessReadme(t *testing.T) {
+	readme := processReadmeFixture(t, nil)
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "readme", "expected.md"))
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(string(expected)), readme.Content, "バッジと HTML が削除され、相対リンクが絶対 URL に書き換えられること")
+
+	assert.Equal(t, []CodeExample{
+		{Heading: "Usage", Code: "client := mylib.New()\n"},
+		{Heading: "Advanced", Code: "client.Do(ctx)\n"},
+	}, readme.Examples, "Go のコードブロックが直前の見出しとともに抽出されること")
+}
+
+func TestProcessReadmeSections(t *testing.T) {
+	readme := processReadmeFixture(t, []string{"usage", "Install"})
+
+	assert.Equal(t, `## Installation
+
+`+"```sh\ngo get github.com/example/mylib\n```"+`
+
+## Usage
+
+Create a client:
+
+Keep `+"`[not a link](foo.md)`"+` as code. More in the [API docs][api].
+
+### Advanced
+
+[api]: https://github.com/example/mylib/blob/v1.2.0/docs/api.md`, readme.Content, "指定したセクションと、その下位の見出しのみが残ること")
+	assert.Len(t, readme.Examples, 2, "コード例は全てのセクションから抽出されること")
+
+	readme = processReadmeFixture(t, []string{"FAQ"})
+	assert.True(t, strings.HasPrefix(readme.Content, "# mylib"), "一致する見出しがない場合は全体を残すこと")
+}
+
+func TestProcessReadmeWithoutBase(t *testing.T) {
+	readme := ProcessReadme("See [docs](docs/README.md) ![shield](https://img.shields.io/badge/go-1.22-blue)\n", ReadmeOptions{})
+	assert.Equal(t, "See [docs](docs/README.md)", readme.Content, "基準の URL がない場合は相対リンクをそのまま残すこと")
+	assert.Empty(t, readme.Examples)
+}
+
+func TestResolveReadmeURL(t *testing.T) {
+	base := "https://github.com/example/mylib/blob/v1.2.0/"
+	for dest, want := range map[string]string{
+		"docs/guide.md":          base + "docs/guide.md",
+		"./docs/guide.md#setup":  base + "docs/guide.md#setup",
+		"/CHANGELOG.md":          base + "CHANGELOG.md",
+		"../outside.md":          base + "outside.md",
+		"docs/?tab=readme":       base + "docs?tab=readme",
+		"#usage":                 "#usage",
+		"https://example.com/a":  "https://example.com/a",
+		"mailto:dev@example.com": "mailto:dev@example.com",
+		"//cdn.example.com/x.js": "//cdn.example.com/x.js",
+	} {
+		assert.Equal(t, want, resolveReadmeURL(base, dest), dest)
+	}
+}
+
+func TestReadmeBaseURLs(t *testing.T) {
+	tests := []struct {
+		repoURL   string
+		version   string
+		linkBase  string
+		imageBase string
+	}{
+		{
+			repoURL:   "https://github.com/example/mylib",
+			version:   "v1.2.0",
+			linkBase:  "https://github.com/example/mylib/blob/v1.2.0/",
+			imageBase: "https://raw.githubusercontent.com/example/mylib/v1.2.0/",
+		},
+		{
+			repoURL:   "https://github.com/example/mylib/",
+			version:   "v0.0.0-20240101120000-abcdef123456",
+			linkBase:  "https://github.com/example/mylib/blob/abcdef123456/",
+			imageBase: "https://raw.githubusercontent.com/example/mylib/abcdef123456/",
+		},
+		{
+			repoURL:   "https://gitlab.com/example/mylib",
+			version:   "latest",
+			linkBase:  "https://gitlab.com/example/mylib/-/blob/HEAD/",
+			imageBase: "https://gitlab.com/example/mylib/-/raw/HEAD/",
+		},
+		{repoURL: "", version: "v1.0.0"},
+	}
+	for _, tt := range tests {
+		linkBase, imageBase := ReadmeBaseURLs(tt.repoURL, tt.version)
+		assert.Equal(t, tt.linkBase, linkBase, tt.repoURL)
+		assert.Equal(t, tt.imageBase, imageBase, tt.repoURL)
+	}
+}
+
+func TestFormatUsageExamples(t *testing.T) {
+	assert.Empty(t, FormatUsageExamples(nil))
+	assert.Equal(t, "## Usage examples\n\n### Usage\n\n```go\nclient := mylib.New()\n```\n\n```go\nfmt.Println()\n```\n\n", FormatUsageExamples([]CodeExample{
+		{Heading: "Usage", Code: "client := mylib.New()\n"},
+		{Code: "fmt.Println()"},
+	}))
+}
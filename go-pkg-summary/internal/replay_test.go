@@ -0,0 +1,249 @@
+package internal
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// record を指定すると、実際のサービスにアクセスして testdata/replay のフィクスチャを記録し直します
+// 例: go test ./internal -run TestReplay -record
+var record = flag.Bool("record", false, "実際のサービスにアクセスし、testdata/replay のフィクスチャを記録し直す")
+
+// replayInteraction は記録した1件のリクエストとレスポンスです
+type replayInteraction struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+	// レスポンスの Content-Type
+	ContentType string `json:"contentType,omitempty"`
+	// レスポンスのボディを保存したファイル（フィクスチャのディレクトリからの相対パス）
+	BodyFile string `json:"bodyFile,omitempty"`
+}
+
+// replayTransport は testdata/replay/<name> に記録したレスポンスを返す RoundTripper です
+// -record を指定した場合は実際のサービスにアクセスし、レスポンスを記録します
+// 同じリクエストは何度でも同じレスポンスを返し、記録されていないリクエストはテストを失敗させます
+type replayTransport struct {
+	t    *testing.T
+	dir  string
+	next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []replayInteraction
+}
+
+// newReplayClient はフィクスチャ name を再生する HTTP クライアントを作成します
+func newReplayClient(t *testing.T, name string) *http.Client {
+	t.Helper()
+
+	transport := &replayTransport{t: t, dir: filepath.Join("testdata", "replay", name)}
+	if *record {
+		transport.next = http.DefaultTransport
+		require.NoError(t, os.RemoveAll(transport.dir))
+		require.NoError(t, os.MkdirAll(transport.dir, 0755))
+		t.Cleanup(transport.save)
+	} else {
+		data, err := os.ReadFile(filepath.Join(transport.dir, "interactions.json"))
+		require.NoError(t, err, "フィクスチャがありません。-record を指定して記録してください")
+		require.NoError(t, json.Unmarshal(data, &transport.interactions))
+	}
+	return &http.Client{Transport: transport}
+}
+
+// RoundTrip は記録したレスポンスを返すか、-record の場合は実際のサービスにアクセスして記録します
+func (rt *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	for _, interaction := range rt.interactions {
+		if interaction.Method == req.Method && interaction.URL == req.URL.String() {
+			return rt.response(req, interaction)
+		}
+	}
+
+	if rt.next == nil {
+		rt.t.Errorf("記録されていないリクエストです: %s %s", req.Method, req.URL)
+		return nil, fmt.Errorf("記録されていないリクエストです: %s %s", req.Method, req.URL)
+	}
+
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := replayInteraction{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if len(body) > 0 {
+		interaction.BodyFile = fmt.Sprintf("%03d%s", len(rt.interactions)+1, bodyExtension(interaction.ContentType))
+		if err := os.WriteFile(filepath.Join(rt.dir, interaction.BodyFile), body, 0644); err != nil {
+			return nil, err
+		}
+	}
+	rt.interactions = append(rt.interactions, interaction)
+	return rt.response(req, interaction)
+}
+
+// response は記録したレスポンスから http.Response を作成します
+func (rt *replayTransport) response(req *http.Request, interaction replayInteraction) (*http.Response, error) {
+	var body []byte
+	if interaction.BodyFile != "" {
+		var err error
+		body, err = os.ReadFile(filepath.Join(rt.dir, interaction.BodyFile))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	header := http.Header{}
+	if interaction.ContentType != "" {
+		header.Set("Content-Type", interaction.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(string(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// save は記録したリクエストの一覧を interactions.json に保存します
+func (rt *replayTransport) save() {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	data, err := json.MarshalIndent(rt.interactions, "", "  ")
+	if err != nil {
+		rt.t.Errorf("フィクスチャのエンコードに失敗しました: %v", err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(rt.dir, "interactions.json"), append(data, '\n'), 0644); err != nil {
+		rt.t.Errorf("フィクスチャの保存に失敗しました: %v", err)
+	}
+}
+
+// bodyExtension はボディを保存するファイルの拡張子を Content-Type から決めます
+func bodyExtension(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "text/html":
+		return ".html"
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return ".json"
+	case mediaType == "application/zip":
+		return ".zip"
+	}
+	return ".txt"
+}
+
+// newReplayFetcher はフィクスチャ name を再生する Fetcher を作成します
+func newReplayFetcher(t *testing.T, name string) *Fetcher {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GOPRIVATE", "")
+	t.Setenv("GONOSUMDB", "")
+	t.Setenv("GOENV", "off")
+
+	f, err := NewFetcher(false, WithHTTPClient(newReplayClient(t, name)), WithCache(NewCacheDir(t.TempDir())))
+	require.NoError(t, err)
+	return f
+}
+
+func TestReplaySearchPackage(t *testing.T) {
+	f := newReplayFetcher(t, "search-zap")
+
+	results, err := f.SearchPackage("zap", 3)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.Equal(t, "go.uber.org/zap", results[0].ImportPath)
+}
+
+func TestReplayPackagePipeline(t *testing.T) {
+	f := newReplayFetcher(t, "testify-assert")
+	const importPath = "github.com/stretchr/testify/assert"
+
+	content, err := f.GetPackage(importPath, "latest", GetPackageOptions{UseCache: true})
+	require.NoError(t, err)
+	assert.Contains(t, content, "# assert\n\nインポートパス: github.com/stretchr/testify/assert\nバージョン: v1.10.0\n")
+	assert.Contains(t, content, "リポジトリURL: https://github.com/stretchr/testify\n")
+	assert.Contains(t, content, "ライセンス: MIT（LICENSE）\n")
+	assert.Contains(t, content, "- assert/assertions.go\n")
+	assert.Contains(t, content, "### go.mod\n\n```go\nmodule github.com/stretchr/testify\n")
+	assert.Contains(t, content, "### README.md\n")
+
+	// 解決したバージョンで保存したキャッシュから取得する
+	cached, err := f.GetPackage(importPath, "v1.10.0", GetPackageOptions{UseCache: true})
+	require.NoError(t, err)
+	assert.Equal(t, content, cached)
+
+	// ls と read を並行して実行しても同じ結果になること
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			files, err := f.ListPackageFiles(importPath, "v1.10.0")
+			if assert.NoError(t, err) {
+				assert.Contains(t, files, "assert/doc.go")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			source, err := f.ReadPackageFile(importPath, "v1.10.0", "assert/doc.go")
+			if assert.NoError(t, err) {
+				assert.Contains(t, source, "package assert")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewFetcherOptions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	client := &http.Client{Transport: http.DefaultTransport}
+	cache := NewCacheDir(t.TempDir())
+	f, err := NewFetcher(false,
+		WithHTTPClient(client),
+		WithCache(cache),
+		WithBaseURLs(BaseURLs{PkgGoDev: "http://pkg.test", Proxy: "http://proxy.test"}),
+		WithBaseURLs(BaseURLs{GitHubAPI: "http://github.test"}),
+	)
+	require.NoError(t, err)
+
+	assert.Same(t, cache, f.cache)
+	assert.Same(t, client, f.scraper.client)
+	assert.Equal(t, http.DefaultTransport, f.client.Transport)
+	assert.NotNil(t, f.client.CheckRedirect, "Fetcher はリダイレクトを追跡しないこと")
+	assert.Nil(t, client.CheckRedirect, "指定したクライアントは変更しないこと")
+	assert.Equal(t, "http://pkg.test", f.scraper.baseURL)
+	assert.Equal(t, "http://proxy.test", f.proxyURL)
+	assert.Equal(t, "http://github.test", f.githubAPIURL)
+	assert.Equal(t, DefaultGitLabAPIURL, f.gitlabAPIURL, "指定していない URL は既定値のままであること")
+	assert.Equal(t, DefaultStdlibSourceURL, f.stdlibSourceURL)
+}
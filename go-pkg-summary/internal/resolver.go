@@ -0,0 +1,234 @@
+// Package resolver は短いパッケージ名を完全なインポートパスに解決する機能を提供します
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+const (
+	// resolverCandidateLimit は解決時に取得する検索候補の件数です
+	resolverCandidateLimit = 10
+	// resolverDominanceRatio は1位の候補を曖昧さなく選ぶために必要な、2位とのインポート数の倍率です
+	resolverDominanceRatio = 10
+	// resolverDisplayLimit は候補一覧に表示する最大件数です
+	resolverDisplayLimit = 5
+)
+
+// AmbiguousPackageError は候補が複数あり自動で解決できないことを表すエラーです
+type AmbiguousPackageError struct {
+	// 検索した名前
+	Query string
+	// ランキング順の候補
+	Candidates []Package
+}
+
+// Error はエラーメッセージと候補の一覧を返します
+func (e *AmbiguousPackageError) Error() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("パッケージ '%s' の候補が複数あります。完全なインポートパスを指定してください:\n", e.Query))
+	for i, c := range e.Candidates {
+		if i >= resolverDisplayLimit {
+			break
+		}
+		b.WriteString(fmt.Sprintf("  - %s（インポート数: %d）\n", c.ImportPath, c.ImportedBy))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Resolver は短いパッケージ名を完全なインポートパスに解決する構造体です
+type Resolver struct {
+	fetcher *Fetcher
+	aliases *AliasStore
+	// 設定ファイルの aliases（エイリアスファイルより優先する）
+	configAliases map[string]string
+	in            io.Reader
+	out           io.Writer
+	interactive   bool
+}
+
+// NewResolver は新しいResolverインスタンスを作成します
+// interactive が true の場合、候補が拮抗しているときに in/out を使って選択肢を提示します
+func NewResolver(fetcher *Fetcher, aliases *AliasStore, in io.Reader, out io.Writer, interactive bool) *Resolver {
+	return &Resolver{
+		fetcher:     fetcher,
+		aliases:     aliases,
+		in:          in,
+		out:         out,
+		interactive: interactive,
+	}
+}
+
+// WithConfigAliases は設定ファイルの aliases を設定します
+// 設定ファイルのエイリアスは検索モードによらず、エイリアスファイルより優先して使用します
+func (r *Resolver) WithConfigAliases(aliases map[string]string) *Resolver {
+	r.configAliases = aliases
+	return r
+}
+
+// Resolve は短い名前をインポートパスに解決します
+// 設定ファイルとエイリアスファイルに登録済みの名前は検索せずに返し、検索で解決した結果はエイリアスとして保存します
+func (r *Resolver) Resolve(query string, mode string) (string, error) {
+	if importPath, ok := r.configAliases[query]; ok {
+		return importPath, nil
+	}
+
+	// パッケージ名での解決はエイリアスを優先する
+	if mode == SearchModePackage && r.aliases != nil {
+		if importPath, ok := r.aliases.Get(query); ok {
+			return importPath, nil
+		}
+	}
+
+	candidates, err := r.search(query, mode)
+	if err != nil {
+		return "", fmt.Errorf("パッケージの検索に失敗しました: %w", err)
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("パッケージ '%s' が見つかりませんでした", query)
+	}
+
+	ranked := RankCandidates(query, candidates)
+
+	var chosen Package
+	switch {
+	case !IsAmbiguous(query, ranked):
+		chosen = ranked[0]
+	case r.interactive:
+		chosen, err = r.pick(query, ranked)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", &AmbiguousPackageError{Query: query, Candidates: ranked}
+	}
+
+	if mode == SearchModePackage && r.aliases != nil {
+		if err := r.aliases.Set(query, chosen.ImportPath); err != nil && r.fetcher.debug {
+			fmt.Printf("エイリアスの保存に失敗しました: %v\n", err)
+		}
+	}
+
+	return chosen.ImportPath, nil
+}
+
+// ResolveIfShort はスラッシュを含まない短い名前のみを解決し、完全なインポートパスと標準ライブラリのパッケージはそのまま返します
+// Resolver が nil の場合は解決せずにそのまま返します
+func (r *Resolver) ResolveIfShort(importPath string, mode string) (string, error) {
+	if r == nil || strings.Contains(importPath, "/") || r.fetcher.IsStdlibPackage(importPath) {
+		return importPath, nil
+	}
+	return r.Resolve(importPath, mode)
+}
+
+// search は検索モードに応じて候補のパッケージを検索します
+// シンボル検索の場合は、見つかったシンボルを含むパッケージを候補とします
+func (r *Resolver) search(query string, mode string) ([]Package, error) {
+	switch mode {
+	case SearchModePackage:
+		return r.fetcher.SearchPackage(query, resolverCandidateLimit)
+	case SearchModeSymbol:
+		symbols, err := r.fetcher.SearchSymbol(query, resolverCandidateLimit)
+		if err != nil {
+			return nil, err
+		}
+		var results []Package
+		seen := map[string]bool{}
+		for _, symbol := range symbols {
+			if seen[symbol.PackagePath] {
+				continue
+			}
+			seen[symbol.PackagePath] = true
+			results = append(results, Package{
+				Name:       path.Base(symbol.PackagePath),
+				ImportPath: symbol.PackagePath,
+				Synopsis:   symbol.Synopsis,
+			})
+		}
+		return results, nil
+	default:
+		return nil, fmt.Errorf("不明な検索モードです: %s（package または symbol を指定してください）", mode)
+	}
+}
+
+// pick は候補の一覧を表示し、ユーザーが選んだ候補を返します
+func (r *Resolver) pick(query string, ranked []Package) (Package, error) {
+	shown := ranked
+	if len(shown) > resolverDisplayLimit {
+		shown = shown[:resolverDisplayLimit]
+	}
+
+	fmt.Fprintf(r.out, "パッケージ '%s' の候補が複数あります:\n", query)
+	for i, c := range shown {
+		fmt.Fprintf(r.out, "  %d) %s（インポート数: %d）\n", i+1, c.ImportPath, c.ImportedBy)
+		if c.Synopsis != "" {
+			fmt.Fprintf(r.out, "     %s\n", c.Synopsis)
+		}
+	}
+	fmt.Fprintf(r.out, "番号を選択してください [1-%d] (既定: 1): ", len(shown))
+
+	line, err := bufio.NewReader(r.in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return Package{}, fmt.Errorf("入力の読み取りに失敗しました: %w", err)
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return shown[0], nil
+	}
+
+	n, err := strconv.Atoi(line)
+	if err != nil || n < 1 || n > len(shown) {
+		return Package{}, fmt.Errorf("無効な選択です: %s", line)
+	}
+	return shown[n-1], nil
+}
+
+// RankCandidates は候補を、名前の完全一致を優先し、次にインポート数の多い順に並べ替えます
+func RankCandidates(query string, candidates []Package) []Package {
+	ranked := make([]Package, len(candidates))
+	copy(ranked, candidates)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		iExact := isExactNameMatch(query, ranked[i])
+		jExact := isExactNameMatch(query, ranked[j])
+		if iExact != jExact {
+			return iExact
+		}
+		return ranked[i].ImportedBy > ranked[j].ImportedBy
+	})
+
+	return ranked
+}
+
+// IsAmbiguous はランキング済みの候補の上位が拮抗しているかを判定します
+// 名前が完全一致する候補が複数あり、1位のインポート数が2位を十分に上回っていない場合に拮抗とみなします
+func IsAmbiguous(query string, ranked []Package) bool {
+	if len(ranked) < 2 {
+		return false
+	}
+
+	first, second := ranked[0], ranked[1]
+	if !isExactNameMatch(query, first) || !isExactNameMatch(query, second) {
+		return false
+	}
+
+	return first.ImportedBy < second.ImportedBy*resolverDominanceRatio
+}
+
+// isExactNameMatch はインポートパスの末尾の要素が名前と一致するかを判定します
+// メジャーバージョンの接尾辞（/v2 や gopkg.in の .v3）は無視します
+func isExactNameMatch(query string, pkg Package) bool {
+	importPath := pkg.ImportPath
+	if prefix, _, ok := module.SplitPathVersion(importPath); ok {
+		importPath = prefix
+	}
+	return strings.EqualFold(path.Base(importPath), query)
+}
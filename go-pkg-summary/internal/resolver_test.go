@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankCandidates(t *testing.T) {
+	candidates := []Package{
+		{ImportPath: "github.com/blendle/zapdriver", ImportedBy: 412},
+		{ImportPath: "go.uber.org/zap/zapcore", ImportedBy: 9876},
+		{ImportPath: "go.uber.org/zap", ImportedBy: 30412},
+		{ImportPath: "github.com/example/zap/v2", ImportedBy: 12},
+	}
+
+	ranked := RankCandidates("zap", candidates)
+
+	var paths []string
+	for _, c := range ranked {
+		paths = append(paths, c.ImportPath)
+	}
+	assert.Equal(t, []string{
+		"go.uber.org/zap",
+		"github.com/example/zap/v2",
+		"go.uber.org/zap/zapcore",
+		"github.com/blendle/zapdriver",
+	}, paths, "名前の完全一致が優先され、同順位はインポート数の多い順になること")
+	assert.Equal(t, "github.com/blendle/zapdriver", candidates[0].ImportPath, "元のスライスは変更されないこと")
+}
+
+func TestIsAmbiguous(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		ranked   []Package
+		expected bool
+	}{
+		{
+			name:     "候補が1件",
+			query:    "zap",
+			ranked:   []Package{{ImportPath: "go.uber.org/zap", ImportedBy: 100}},
+			expected: false,
+		},
+		{
+			name:  "完全一致が1件だけ",
+			query: "zap",
+			ranked: []Package{
+				{ImportPath: "go.uber.org/zap", ImportedBy: 100},
+				{ImportPath: "go.uber.org/zap/zapcore", ImportedBy: 90},
+			},
+			expected: false,
+		},
+		{
+			name:  "完全一致が複数でも1位が圧倒的",
+			query: "zap",
+			ranked: []Package{
+				{ImportPath: "go.uber.org/zap", ImportedBy: 30412},
+				{ImportPath: "github.com/example/zap/v2", ImportedBy: 12},
+			},
+			expected: false,
+		},
+		{
+			name:  "完全一致が複数でインポート数が拮抗",
+			query: "yaml",
+			ranked: []Package{
+				{ImportPath: "gopkg.in/yaml.v3", ImportedBy: 50000},
+				{ImportPath: "sigs.k8s.io/yaml", ImportedBy: 20000},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsAmbiguous(tt.query, tt.ranked), "拮抗の判定が期待値と一致すること")
+		})
+	}
+}
+
+func TestAmbiguousPackageError(t *testing.T) {
+	err := &AmbiguousPackageError{
+		Query: "yaml",
+		Candidates: []Package{
+			{ImportPath: "gopkg.in/yaml.v3", ImportedBy: 50000},
+			{ImportPath: "sigs.k8s.io/yaml", ImportedBy: 20000},
+		},
+	}
+
+	message := err.Error()
+	assert.Contains(t, message, "gopkg.in/yaml.v3", "エラーメッセージに候補が含まれること")
+	assert.Contains(t, message, "sigs.k8s.io/yaml", "エラーメッセージに全ての候補が含まれること")
+}
+
+func TestResolverPick(t *testing.T) {
+	ranked := []Package{
+		{ImportPath: "gopkg.in/yaml.v3", ImportedBy: 50000},
+		{ImportPath: "sigs.k8s.io/yaml", ImportedBy: 20000},
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		expected  string
+		expectErr bool
+	}{
+		{name: "番号で選択", input: "2\n", expected: "sigs.k8s.io/yaml"},
+		{name: "空入力は1番目", input: "\n", expected: "gopkg.in/yaml.v3"},
+		{name: "範囲外の番号", input: "3\n", expectErr: true},
+		{name: "数値以外", input: "abc\n", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			r := NewResolver(nil, nil, strings.NewReader(tt.input), &out, true)
+
+			chosen, err := r.pick("yaml", ranked)
+			if tt.expectErr {
+				assert.Error(t, err, "無効な入力ではエラーになること")
+				return
+			}
+			require.NoError(t, err, "有効な入力では選択に成功すること")
+			assert.Equal(t, tt.expected, chosen.ImportPath, "選択した候補が返ること")
+			assert.Contains(t, out.String(), "1) gopkg.in/yaml.v3", "候補の一覧が表示されること")
+		})
+	}
+}
+
+func TestAliasStore(t *testing.T) {
+	store := NewAliasStoreAt(filepath.Join(t.TempDir(), "nested", AliasFileName))
+
+	_, ok := store.Get("zap")
+	assert.False(t, ok, "ファイルが存在しない場合は未登録として扱うこと")
+
+	require.NoError(t, store.Set("zap", "go.uber.org/zap"), "エイリアスの保存に成功すること")
+	require.NoError(t, store.Set("yaml", "gopkg.in/yaml.v3"), "2件目のエイリアスの保存に成功すること")
+
+	importPath, ok := store.Get("zap")
+	assert.True(t, ok, "保存したエイリアスが見つかること")
+	assert.Equal(t, "go.uber.org/zap", importPath, "保存したインポートパスが返ること")
+}
+
+func TestResolverConfigAliases(t *testing.T) {
+	store := NewAliasStoreAt(filepath.Join(t.TempDir(), AliasFileName))
+	require.NoError(t, store.Set("zap", "go.uber.org/zap"))
+	require.NoError(t, store.Set("yaml", "gopkg.in/yaml.v3"))
+
+	r := NewResolver(nil, store, nil, nil, false).WithConfigAliases(map[string]string{"zap": "example.com/fork/zap"})
+
+	importPath, err := r.Resolve("zap", SearchModePackage)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/fork/zap", importPath, "設定ファイルのエイリアスをエイリアスファイルより優先すること")
+
+	importPath, err = r.Resolve("yaml", SearchModePackage)
+	require.NoError(t, err)
+	assert.Equal(t, "gopkg.in/yaml.v3", importPath, "設定ファイルにない名前はエイリアスファイルから解決すること")
+
+	importPath, err = r.Resolve("zap", SearchModeSymbol)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/fork/zap", importPath, "シンボル検索でも設定ファイルのエイリアスを使用すること")
+}
+
+func TestAliasStoreConcurrentSet(t *testing.T) {
+	store := NewAliasStoreAt(filepath.Join(t.TempDir(), AliasFileName))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("pkg%d", i)
+			assert.NoError(t, store.Set(name, "example.com/"+name))
+			_, ok := store.Get(name)
+			assert.True(t, ok, "保存した直後のエイリアスが読めること")
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("pkg%d", i)
+		importPath, ok := store.Get(name)
+		assert.True(t, ok, "同時に保存したエイリアスが失われないこと: %s", name)
+		assert.Equal(t, "example.com/"+name, importPath)
+	}
+}
@@ -6,146 +6,460 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// DefaultPkgGoDevURL は pkg.go.dev のベースURLです
+	DefaultPkgGoDevURL = "https://pkg.go.dev"
+
+	// SearchModePackage はパッケージ検索を表します
+	SearchModePackage = "package"
+	// SearchModeSymbol はシンボル検索を表します
+	SearchModeSymbol = "symbol"
+
+	// searchPageSize は検索結果1ページあたりの最大件数です（pkg.go.dev の上限）
+	searchPageSize = 100
+	// maxSearchPages は取得する検索結果ページ数の上限です
+	maxSearchPages = 10
 )
 
 // Scraper はpkg.go.devからパッケージ情報を取得するスクレイパーです
 type Scraper struct {
-	client *http.Client
-	debug  bool
+	client  *http.Client
+	baseURL string
+	debug   bool
 }
 
 // NewScraper は新しいスクレイパーインスタンスを作成します
-func NewScraper(debug bool) *Scraper {
-	return &Scraper{
-		client: &http.Client{
+// opts で HTTP クライアントと pkg.go.dev の URL を差し替えられます
+func NewScraper(debug bool, opts ...Option) *Scraper {
+	o := newOptions(opts)
+
+	client := o.httpClient
+	if client == nil {
+		client = &http.Client{
 			Timeout: 10 * time.Second,
-		},
-		debug: debug,
+		}
+	}
+	if len(o.tokens) > 0 {
+		withTokens := *client
+		withTokens.Transport = o.transport(client.Transport)
+		client = &withTokens
+	}
+
+	return &Scraper{
+		client:  client,
+		baseURL: o.baseURLs.PkgGoDev,
+		debug:   debug,
 	}
 }
 
 // SearchPackage はpkg.go.devでパッケージを検索します
+// limit が1ページの件数を超える場合は page パラメータでページを送りながら取得します
 func (s *Scraper) SearchPackage(query string, limit int) ([]Package, error) {
-	// 検索 URL を構築
-	baseURL := "https://pkg.go.dev/search"
-	params := url.Values{}
-	params.Add("q", query)
+	var results []Package
 
-	searchURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
+	err := s.searchPages(query, SearchModePackage, limit, func(sel *goquery.Selection) {
+		results = append(results, s.parseSearchSnippet(sel))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
 
 	if s.debug {
-		fmt.Printf("検索 URL: %s\n", searchURL)
+		fmt.Printf("検索結果: %d 件\n", len(results))
 	}
 
-	// HTTP リクエストを作成
-	req, err := http.NewRequest("GET", searchURL, nil)
+	return results, nil
+}
+
+// SearchSymbol はpkg.go.devのシンボル検索で関数や型などのシンボルを検索します
+func (s *Scraper) SearchSymbol(query string, limit int) ([]Symbol, error) {
+	var results []Symbol
+
+	err := s.searchPages(query, SearchModeSymbol, limit, func(sel *goquery.Selection) {
+		results = append(results, s.parseSymbolSnippet(sel))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+		return nil, err
 	}
 
-	// User-Agent ヘッダーを設定
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.114 Safari/537.36")
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
 
 	if s.debug {
-		fmt.Println("リクエストヘッダー:")
-		for key, values := range req.Header {
-			fmt.Printf("  %s: %s\n", key, strings.Join(values, ", "))
+		fmt.Printf("シンボル検索結果: %d 件\n", len(results))
+	}
+
+	return results, nil
+}
+
+// searchPages は検索結果のページを順に取得し、各 .SearchSnippet を handle に渡します
+// limit 件に達するか、新しい結果がないページに達した時点で終了します
+// pkg.go.dev は同じモジュールのパッケージを1件にまとめて表示するため、1ページ分に満たないページの後にも結果が続くことがあります
+func (s *Scraper) searchPages(query string, mode string, limit int, handle func(sel *goquery.Selection)) error {
+	pageSize := searchPageSize
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
+
+	count := 0
+	seen := map[string]bool{}
+	for page := 1; page <= maxSearchPages; page++ {
+		// 検索 URL を構築
+		params := url.Values{}
+		params.Add("q", query)
+		if mode == SearchModeSymbol {
+			params.Add("m", "symbol")
+		}
+		params.Add("limit", strconv.Itoa(pageSize))
+		if page > 1 {
+			params.Add("page", strconv.Itoa(page))
+		}
+
+		searchURL := fmt.Sprintf("%s/search?%s", s.baseURL, params.Encode())
+
+		if s.debug {
+			fmt.Printf("検索 URL: %s\n", searchURL)
+		}
+
+		doc, err := s.fetchDocument(searchURL)
+		if err != nil {
+			return err
+		}
+
+		// 前のページと重複する結果は除外する（page パラメータが無視された場合の対策）
+		added := 0
+		doc.Find(".SearchSnippet").Each(func(i int, sel *goquery.Selection) {
+			key, _ := sel.Find(".SearchSnippet-headerContainer a").First().Attr("href")
+			if key != "" && seen[key] {
+				return
+			}
+			seen[key] = true
+			handle(sel)
+			added++
+		})
+		count += added
+
+		// 結果がなくなるか、必要な件数に達したら終了
+		if added == 0 || limit <= 0 || count >= limit {
+			break
 		}
 	}
 
-	// リクエストを実行
-	resp, err := s.client.Do(req)
+	return nil
+}
+
+// parseSearchSnippet は検索結果の1件（.SearchSnippet）からパッケージ情報を抽出します
+func (s *Scraper) parseSearchSnippet(sel *goquery.Selection) Package {
+	// インポートパス（括弧内のテキストを抽出）
+	headerText := sel.Find(".SearchSnippet-header-path").Text()
+	if headerText == "" {
+		headerText = sel.Find(".SearchSnippet-headerContainer").Text()
+	}
+	importPath := ""
+	if start := strings.Index(headerText, "("); start != -1 {
+		if end := strings.Index(headerText[start:], ")"); end != -1 {
+			importPath = strings.TrimSpace(headerText[start+1 : start+end])
+		}
+	}
+
+	// パッケージ名（インポートパスの最後の部分）
+	name := ""
+	if importPath != "" {
+		name = path.Base(importPath)
+	}
+
+	// 概要
+	synopsis := strings.TrimSpace(sel.Find(".SearchSnippet-synopsis").Text())
+
+	// インポート数、最新バージョン、公開日、ライセンス
+	info := sel.Find(".SearchSnippet-infoLabel")
+	importedBy := parseCount(info.Find(`a[href$="?tab=importedby"]`).Text())
+	version := versionPattern.FindString(info.Text())
+	published := parsePublishedDate(info.Find(`[data-test-id="snippet-published"]`).Text())
+	license := strings.Join(strings.Fields(info.Find(`[data-test-id="snippet-license"]`).Text()), " ")
+
+	return Package{
+		Name:       name,
+		ImportPath: importPath,
+		Version:    version,
+		Synopsis:   synopsis,
+		DocURL:     fmt.Sprintf("%s/%s", s.baseURL, importPath),
+		License:    license,
+		ImportedBy: importedBy,
+		Published:  published,
+	}
+}
+
+// parseSymbolSnippet はシンボル検索の結果の1件からシンボル情報を抽出します
+func (s *Scraper) parseSymbolSnippet(sel *goquery.Selection) Symbol {
+	header := sel.Find(".SearchSnippet-headerContainer")
+
+	// パッケージパス（括弧内のテキストを抽出）
+	packagePath := ""
+	pathText := header.Find(".SearchSnippet-header-path").Text()
+	if start := strings.Index(pathText, "("); start != -1 {
+		if end := strings.Index(pathText[start:], ")"); end != -1 {
+			packagePath = strings.TrimSpace(pathText[start+1 : start+end])
+		}
+	}
+
+	// シンボル名（リンクのフラグメント、なければ "pkg.Name" 形式のタイトル）
+	name := ""
+	href, _ := header.Find("a").First().Attr("href")
+	if i := strings.Index(href, "#"); i != -1 {
+		name = href[i+1:]
+	}
+	if name == "" {
+		title := strings.TrimSpace(header.Find("a").First().Contents().First().Text())
+		if i := strings.Index(title, "."); i != -1 {
+			name = title[i+1:]
+		} else {
+			name = title
+		}
+	}
+
+	return Symbol{
+		Name:        name,
+		Kind:        strings.TrimSpace(sel.Find(".SearchSnippet-symbolKind").Text()),
+		Signature:   strings.TrimSpace(sel.Find(".SearchSnippet-symbolCode").Text()),
+		Synopsis:    strings.TrimSpace(sel.Find(".SearchSnippet-synopsis").Text()),
+		PackagePath: packagePath,
+		DocURL:      fmt.Sprintf("%s/%s#%s", s.baseURL, packagePath, name),
+	}
+}
+
+// GetPackageInfo はパッケージの詳細情報を取得します
+func (s *Scraper) GetPackageInfo(importPath string, version string) (*Package, error) {
+	// パッケージURLを構築
+	var pkgURL string
+	if version != "" && version != "latest" {
+		pkgURL = fmt.Sprintf("%s/%s@%s", s.baseURL, importPath, version)
+	} else {
+		pkgURL = fmt.Sprintf("%s/%s", s.baseURL, importPath)
+	}
+
+	doc, err := s.fetchDocument(pkgURL)
 	if err != nil {
-		return nil, fmt.Errorf("API リクエストに失敗しました: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+
+	pkg := parsePackagePage(doc, importPath)
+	pkg.DocURL = pkgURL
 
 	if s.debug {
-		fmt.Println("レスポンスヘッダー:")
-		for key, values := range resp.Header {
-			fmt.Printf("  %s: %s\n", key, strings.Join(values, ", "))
+		fmt.Printf("パッケージ情報: %+v\n", pkg)
+	}
+
+	return pkg, nil
+}
+
+// parsePackagePage はパッケージページのHTMLからパッケージ情報を抽出します
+// 構造化された要素（data-test-id や UnitMeta）を優先し、見つからない場合は旧来のセレクタにフォールバックします
+func parsePackagePage(doc *goquery.Document, importPath string) *Package {
+	pkg := &Package{
+		ImportPath: importPath,
+	}
+
+	// パッケージ名
+	pkg.Name = firstText(doc,
+		`[data-test-id="UnitHeader-title"]`,
+		"h1.UnitHeader-titleHeading",
+		"h1.go-Main-title",
+		"h1",
+	)
+	if pkg.Name == "" {
+		pkg.Name = path.Base(importPath)
+	}
+
+	// バージョン
+	versionText := firstText(doc,
+		`[data-test-id="UnitHeader-version"]`,
+		".go-Main-headerDetails",
+	)
+	pkg.Version = versionPattern.FindString(versionText)
+
+	// 公開日
+	publishedText := firstText(doc,
+		`[data-test-id="UnitHeader-commitTime"]`,
+		".UnitHeader-commitTime",
+	)
+	pkg.Published = parsePublishedDate(publishedText)
+
+	// ライセンス（複数ある場合はカンマ区切り）
+	var licenses []string
+	doc.Find(`[data-test-id="UnitHeader-licenses"] a, [data-test-id="UnitHeader-license"]`).Each(func(i int, sel *goquery.Selection) {
+		if name := strings.TrimSpace(sel.Text()); name != "" && !slices.Contains(licenses, name) {
+			licenses = append(licenses, name)
+		}
+	})
+	pkg.License = strings.Join(licenses, ", ")
+
+	// インポートされている数
+	pkg.ImportedBy = parseCount(firstText(doc,
+		`[data-test-id="UnitHeader-importedby"]`,
+		`a[href$="?tab=importedby"]`,
+	))
+
+	// 概要
+	pkg.Synopsis = firstText(doc,
+		".Documentation-overview p",
+		"div.Documentation-content > p",
+	)
+	if pkg.Synopsis == "" {
+		if content, exists := doc.Find(`meta[name="Description"], meta[name="description"]`).First().Attr("content"); exists {
+			pkg.Synopsis = strings.TrimSpace(content)
 		}
 	}
+	// 改行を削除
+	pkg.Synopsis = strings.Join(strings.Fields(pkg.Synopsis), " ")
 
-	// レスポンスをチェック
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API リクエストに失敗しました: %s - %s", resp.Status, string(body))
+	// リポジトリURL
+	if href, exists := doc.Find(".UnitMeta-repo a").First().Attr("href"); exists {
+		pkg.RepoURL = strings.TrimSpace(href)
+	}
+	if pkg.RepoURL == "" {
+		pkg.RepoURL = repoURLFromImportPath(importPath)
 	}
 
-	// HTML をパース
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	return pkg
+}
+
+// versionPattern はテキスト中のセマンティックバージョンに一致する正規表現です
+var versionPattern = regexp.MustCompile(`v\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?`)
+
+// firstText はセレクタを順に試し、最初に見つかった空でないテキストを返します
+func firstText(doc *goquery.Document, selectors ...string) string {
+	for _, selector := range selectors {
+		if text := strings.TrimSpace(doc.Find(selector).First().Text()); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// parsePublishedDate は "Published: Nov 12, 2024" 形式のテキストから日付を取得します
+func parsePublishedDate(text string) time.Time {
+	text = strings.TrimSpace(text)
+	if i := strings.Index(text, ":"); i != -1 {
+		text = strings.TrimSpace(text[i+1:])
+	}
+	t, err := time.Parse("Jan 2, 2006", text)
 	if err != nil {
-		return nil, fmt.Errorf("HTML のパースに失敗しました: %w", err)
+		return time.Time{}
 	}
+	return t
+}
 
-	// 検索結果を抽出
-	var results []Package
+// parseCount は "Imported by: 161,153" 形式のテキストから数値を取得します
+func parseCount(text string) int {
+	digits := strings.Builder{}
+	for _, c := range text {
+		if c >= '0' && c <= '9' {
+			digits.WriteRune(c)
+		}
+	}
+	n, err := strconv.Atoi(digits.String())
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// repoURLFromImportPath はホスティングサービスのインポートパスからリポジトリURLを推測します
+func repoURLFromImportPath(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	switch parts[0] {
+	case "github.com", "gitlab.com", "bitbucket.org":
+		return fmt.Sprintf("https://%s/%s/%s", parts[0], parts[1], parts[2])
+	}
+	return ""
+}
+
+// GetVersions は pkg.go.dev のバージョンタブからバージョン一覧と、パッケージを含むモジュールのパスを取得します
+// モジュールパスは各バージョンのリンク（/<module>@<version>/<package>）から求め、リンクから求められない場合は importPath を返します
+func (s *Scraper) GetVersions(importPath string) (string, []ModuleVersion, error) {
+	versionsURL := fmt.Sprintf("%s/%s?tab=versions", s.baseURL, importPath)
 
-	// 検索結果の各アイテムを処理
-	doc.Find(".SearchSnippet").Each(func(i int, s *goquery.Selection) {
-		// 最大件数に達したら処理を終了
-		if limit > 0 && i >= limit {
+	doc, err := s.fetchDocument(versionsURL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	modulePath := ""
+	var versions []ModuleVersion
+
+	// 各バージョンの行を処理
+	doc.Find(".Version-tag").Each(func(i int, sel *goquery.Selection) {
+		link := sel.Find("a.js-versionLink")
+		version := strings.TrimSpace(link.Text())
+		if version == "" {
 			return
 		}
 
-		// インポートパス（括弧内のテキストを抽出）
-		headerText := s.Find(".SearchSnippet-headerContainer").Text()
-		importPath := ""
-		if start := strings.Index(headerText, "("); start != -1 {
-			if end := strings.Index(headerText[start:], ")"); end != -1 {
-				importPath = strings.TrimSpace(headerText[start+1 : start+end])
+		if modulePath == "" {
+			href, _ := link.Attr("href")
+			if linkedPath, _, ok := strings.Cut(strings.TrimPrefix(href, "/"), "@"); ok {
+				modulePath = linkedPath
 			}
 		}
 
-		// パッケージ名（インポートパスの最後の部分）
-		name := ""
-		if importPath != "" {
-			parts := strings.Split(importPath, "/")
-			name = parts[len(parts)-1]
+		mv := ModuleVersion{
+			Version:    version,
+			Pseudo:     module.IsPseudoVersion(version),
+			PreRelease: semver.Prerelease(version) != "" && !module.IsPseudoVersion(version),
 		}
 
-		// 概要
-		synopsis := strings.TrimSpace(s.Find(".SearchSnippet-synopsis").Text())
-
-		// 結果に追加
-		pkg := Package{
-			Name:       name,
-			ImportPath: importPath,
-			Synopsis:   synopsis,
-			DocURL:     fmt.Sprintf("https://pkg.go.dev/%s", importPath),
+		// 直後の詳細要素から公開日と撤回情報を取得
+		details := sel.Next()
+		if details.HasClass("Version-details") {
+			commitTime := strings.TrimSpace(details.Find(".Version-commitTime").Text())
+			if t, err := time.Parse("Jan 2, 2006", commitTime); err == nil {
+				mv.Time = t
+			}
+			mv.Retracted = strings.Contains(strings.ToLower(details.Text()), "retracted")
 		}
 
-		results = append(results, pkg)
+		versions = append(versions, mv)
 	})
 
-	if s.debug {
-		fmt.Printf("検索結果: %d 件\n", len(results))
+	if len(versions) == 0 {
+		return "", nil, fmt.Errorf("バージョン情報が見つかりません: %s", importPath)
+	}
+	if modulePath == "" {
+		modulePath = importPath
 	}
 
-	return results, nil
+	return modulePath, versions, nil
 }
 
-// GetPackageInfo はパッケージの詳細情報を取得します
-func (s *Scraper) GetPackageInfo(importPath string, version string) (*Package, error) {
-	// パッケージURLを構築
-	var pkgURL string
-	if version != "" && version != "latest" {
-		pkgURL = fmt.Sprintf("https://pkg.go.dev/%s@%s", importPath, version)
-	} else {
-		pkgURL = fmt.Sprintf("https://pkg.go.dev/%s", importPath)
-	}
-
+// fetchDocument は指定URLのHTMLを取得してパースします
+func (s *Scraper) fetchDocument(pageURL string) (*goquery.Document, error) {
 	if s.debug {
-		fmt.Printf("パッケージ URL: %s\n", pkgURL)
+		fmt.Printf("ページ URL: %s\n", pageURL)
 	}
 
 	// HTTP リクエストを作成
-	req, err := http.NewRequest("GET", pkgURL, nil)
+	req, err := http.NewRequest("GET", pageURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("リクエストの作成に失敗しました: %w", err)
 	}
@@ -186,57 +500,5 @@ func (s *Scraper) GetPackageInfo(importPath string, version string) (*Package, e
 		return nil, fmt.Errorf("HTML のパースに失敗しました: %w", err)
 	}
 
-	// パッケージ情報を抽出
-	pkg := &Package{
-		ImportPath: importPath,
-		DocURL:     pkgURL,
-	}
-
-	// パッケージ名
-	pkg.Name = strings.TrimSpace(doc.Find("h1.go-Main-title").Text())
-	if pkg.Name == "" {
-		// 新しいセレクタを試す
-		pkg.Name = strings.TrimSpace(doc.Find("h1").First().Text())
-	}
-
-	// バージョン
-	versionText := doc.Find(".go-Main-headerDetails").Text()
-	if strings.Contains(versionText, "v") {
-		vParts := strings.Split(versionText, "v")
-		if len(vParts) > 1 {
-			// 改行や空白を削除
-			version := strings.TrimSpace(vParts[1])
-			// 最初の単語だけを取得（余分なテキストを除去）
-			if spaceIndex := strings.Index(version, " "); spaceIndex > 0 {
-				version = version[:spaceIndex]
-			}
-			if newlineIndex := strings.Index(version, "\n"); newlineIndex > 0 {
-				version = version[:newlineIndex]
-			}
-			pkg.Version = version
-		}
-	}
-
-	// 概要
-	pkg.Synopsis = strings.TrimSpace(doc.Find(".Documentation-overview").Text())
-	if pkg.Synopsis == "" {
-		// 新しいセレクタを試す
-		pkg.Synopsis = strings.TrimSpace(doc.Find("div.Documentation-content > p").First().Text())
-	}
-	// 改行を削除
-	pkg.Synopsis = strings.ReplaceAll(pkg.Synopsis, "\n", " ")
-
-	// リポジトリURL
-	doc.Find("a").Each(func(i int, s *goquery.Selection) {
-		href, exists := s.Attr("href")
-		if exists && (strings.Contains(href, "github.com") || strings.Contains(href, "gitlab.com")) {
-			pkg.RepoURL = href
-		}
-	})
-
-	if s.debug {
-		fmt.Printf("パッケージ情報: %+v\n", pkg)
-	}
-
-	return pkg, nil
+	return doc, nil
 }
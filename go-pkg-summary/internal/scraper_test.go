@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFixtureScraper は testdata/pkggodev の保存済みHTMLを返すサーバーに接続したスクレイパーを作成します
+func newFixtureScraper(t *testing.T, pages map[string]string) *Scraper {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// パスと、ページを区別するクエリパラメータからフィクスチャを選ぶ
+		key := r.URL.Path
+		query := url.Values{}
+		for _, name := range []string{"tab", "m", "page"} {
+			if value := r.URL.Query().Get(name); value != "" {
+				query.Set(name, value)
+			}
+		}
+		if len(query) > 0 {
+			key += "?" + query.Encode()
+		}
+
+		fixture, ok := pages[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", "pkggodev", fixture))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	s := NewScraper(false)
+	s.baseURL = server.URL
+	return s
+}
+
+func TestScraperGetPackageInfo(t *testing.T) {
+	s := newFixtureScraper(t, map[string]string{
+		"/github.com/stretchr/testify/assert": "testify-assert.html",
+		"/go.uber.org/zap":                    "legacy-layout.html",
+	})
+
+	tests := []struct {
+		name       string
+		importPath string
+		expected   Package
+	}{
+		{
+			name:       "構造化データを持つ現行レイアウト",
+			importPath: "github.com/stretchr/testify/assert",
+			expected: Package{
+				Name:       "assert",
+				ImportPath: "github.com/stretchr/testify/assert",
+				Version:    "v1.10.0",
+				Synopsis:   "Package assert provides a set of comprehensive testing tools for use with the normal Go testing system.",
+				RepoURL:    "https://github.com/stretchr/testify",
+				License:    "MIT",
+				ImportedBy: 161153,
+				Published:  time.Date(2024, time.November, 12, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "旧レイアウトではフォールバックのセレクタを使う",
+			importPath: "go.uber.org/zap",
+			expected: Package{
+				Name:       "zap",
+				ImportPath: "go.uber.org/zap",
+				Version:    "v1.27.0",
+				Synopsis:   "Package zap provides fast, structured, leveled logging.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, err := s.GetPackageInfo(tt.importPath, "latest")
+			require.NoError(t, err, "パッケージ情報の取得に成功すること")
+
+			tt.expected.DocURL = s.baseURL + "/" + tt.importPath
+			assert.Equal(t, tt.expected, *pkg, "保存済みHTMLから期待したパッケージ情報が抽出されること")
+		})
+	}
+}
+
+func TestScraperGetPackageInfoIgnoresReadmeLinks(t *testing.T) {
+	s := newFixtureScraper(t, map[string]string{
+		"/go.uber.org/zap": "legacy-layout.html",
+	})
+
+	pkg, err := s.GetPackageInfo("go.uber.org/zap", "latest")
+	require.NoError(t, err, "パッケージ情報の取得に成功すること")
+	assert.Empty(t, pkg.RepoURL, "README内のGitHubリンクをリポジトリURLとして扱わないこと")
+}
+
+func TestScraperGetPackageInfoNotFound(t *testing.T) {
+	s := newFixtureScraper(t, map[string]string{})
+
+	_, err := s.GetPackageInfo("example.com/missing", "latest")
+	assert.Error(t, err, "存在しないパッケージではエラーになること")
+}
+
+func TestScraperGetVersions(t *testing.T) {
+	s := newFixtureScraper(t, map[string]string{
+		"/github.com/stretchr/testify/assert?tab=versions": "testify-assert-versions.html",
+	})
+
+	modulePath, versions, err := s.GetVersions("github.com/stretchr/testify/assert")
+	require.NoError(t, err, "バージョン一覧の取得に成功すること")
+	assert.Equal(t, "github.com/stretchr/testify", modulePath, "バージョンのリンクからモジュールパスが求められること")
+
+	expected := []ModuleVersion{
+		{Version: "v1.10.0", Time: time.Date(2024, time.November, 12, 0, 0, 0, 0, time.UTC)},
+		{Version: "v1.9.0", Time: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{Version: "v1.8.3", Time: time.Date(2023, time.May, 10, 0, 0, 0, 0, time.UTC), Retracted: true},
+		{Version: "v1.8.0-rc.1", Time: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC), PreRelease: true},
+	}
+	assert.Equal(t, expected, versions, "バージョンタブから公開日と撤回状態が抽出されること")
+}
+
+func TestScraperSearchPackage(t *testing.T) {
+	s := newFixtureScraper(t, map[string]string{
+		"/search": "search-zap.html",
+	})
+
+	results, err := s.SearchPackage("zap", 2)
+	require.NoError(t, err, "検索に成功すること")
+	require.Len(t, results, 2, "limit の件数だけ結果が返ること")
+
+	expected := Package{
+		Name:       "zap",
+		ImportPath: "go.uber.org/zap",
+		Version:    "v1.27.0",
+		Synopsis:   "Package zap provides fast, structured, leveled logging.",
+		DocURL:     s.baseURL + "/go.uber.org/zap",
+		License:    "MIT",
+		ImportedBy: 30412,
+		Published:  time.Date(2024, time.February, 20, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, expected, results[0], "検索結果からインポート数、バージョン、公開日、ライセンスが抽出されること")
+	assert.Equal(t, "github.com/blendle/zapdriver", results[1].ImportPath, "検索結果の順序が保たれること")
+	assert.Equal(t, 412, results[1].ImportedBy, "2件目のインポート数が抽出されること")
+}
+
+func TestScraperSearchPackagePagination(t *testing.T) {
+	s := newFixtureScraper(t, map[string]string{
+		"/search":        "search-zap.html",
+		"/search?page=2": "search-zap-page2.html",
+	})
+
+	results, err := s.SearchPackage("zap", 4)
+	require.NoError(t, err, "検索に成功すること")
+
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, r.ImportPath)
+	}
+	assert.Equal(t, []string{
+		"go.uber.org/zap",
+		"github.com/blendle/zapdriver",
+		"go.uber.org/zap/zapcore",
+		"moul.io/zapgorm2",
+	}, paths, "1ページ目で足りない分が2ページ目から取得されること")
+}
+
+func TestScraperSearchSymbol(t *testing.T) {
+	s := newFixtureScraper(t, map[string]string{
+		"/search?m=symbol":        "search-symbol-newlogger.html",
+		"/search?m=symbol&page=2": "search-empty.html",
+	})
+
+	results, err := s.SearchSymbol("NewLogger", 10)
+	require.NoError(t, err, "シンボル検索に成功すること")
+	require.Len(t, results, 2, "全てのシンボルが返ること")
+
+	expected := Symbol{
+		Name:        "NewProduction",
+		Kind:        "Function",
+		Signature:   "func NewProduction(options ...Option) (*Logger, error)",
+		Synopsis:    "NewProduction builds a sensible production Logger that writes InfoLevel and above logs to standard error as JSON.",
+		PackagePath: "go.uber.org/zap",
+		DocURL:      s.baseURL + "/go.uber.org/zap#NewProduction",
+	}
+	assert.Equal(t, expected, results[0], "シンボル名、種類、シグネチャ、パッケージパスが抽出されること")
+	assert.Equal(t, "Logger.Sugar", results[1].Name, "メソッドは Type.Method 形式になること")
+}
+
+func TestRepoURLFromImportPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		importPath string
+		expected   string
+	}{
+		{name: "GitHubのサブパッケージ", importPath: "github.com/stretchr/testify/assert", expected: "https://github.com/stretchr/testify"},
+		{name: "GitLabのリポジトリ", importPath: "gitlab.com/group/project", expected: "https://gitlab.com/group/project"},
+		{name: "バニティインポートパス", importPath: "go.uber.org/zap", expected: ""},
+		{name: "短すぎるパス", importPath: "github.com/user", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, repoURLFromImportPath(tt.importPath), "インポートパスからリポジトリURLが推測されること")
+		})
+	}
+}
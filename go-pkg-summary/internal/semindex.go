@@ -0,0 +1,341 @@
+// Package semindex はパッケージの公開APIを宣言ごとのチャンクに分け、DuckDB のベクトルストアに索引付けして
+// 自然言語のクエリで検索する機能を提供します
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// IndexFileName はホームディレクトリのキャッシュディレクトリに作成する既定の索引のファイル名です
+	IndexFileName = "index.duckdb"
+
+	// apiChunksTable は宣言ごとのチャンクとベクトルを格納するテーブルです
+	apiChunksTable = "api_chunks"
+	// apiChunksIndex はチャンクのベクトルの HNSW インデックスです
+	apiChunksIndex = "api_chunks_hnsw"
+	// apiIndexMetaTable は索引の作成に使用した埋め込みモデルを記録するテーブルです
+	apiIndexMetaTable = "api_index_meta"
+)
+
+// APIChunk は索引付けする公開APIの宣言1件です
+type APIChunk struct {
+	// チャンクのID（"インポートパス@バージョン#シンボル"）
+	ID string `json:"id"`
+	// インポートパス
+	ImportPath string `json:"importPath"`
+	// バージョン
+	Version string `json:"version"`
+	// シンボル名（メソッドの場合は "Type.Method"）
+	Symbol string `json:"symbol"`
+	// 宣言の種類（TypeInfo.Kind）
+	Kind string `json:"kind"`
+	// 宣言（関数とメソッドはシグネチャ）
+	Definition string `json:"definition"`
+	// ドキュメントコメントの最初の行
+	Summary string `json:"summary,omitempty"`
+	// 非推奨かどうか
+	Deprecated bool `json:"deprecated,omitempty"`
+	// pkg.go.dev のドキュメントの URL
+	URL string `json:"url"`
+	// 埋め込みに使用するテキスト
+	Text string `json:"-"`
+}
+
+// SemanticSearchResult は意味検索の結果の1件です
+type SemanticSearchResult struct {
+	APIChunk
+	// クエリとのコサイン距離（小さいほど近い）
+	Distance float64 `json:"distance"`
+}
+
+// ChunkPackageAPI はパッケージの公開APIを宣言ごとのチャンクに分けます
+// 埋め込みに使用するテキストは、インポートパス、シンボル名、宣言、ドキュメントコメントをまとめたものです
+func ChunkPackageAPI(importPath string, version string, api []TypeInfo) []APIChunk {
+	chunks := make([]APIChunk, 0, len(api))
+	for _, info := range api {
+		symbol := apiSymbol(info)
+		definition := info.Signature
+		if definition == "" {
+			definition = info.Definition
+		}
+		chunks = append(chunks, APIChunk{
+			ID:         importPath + "@" + version + "#" + symbol,
+			ImportPath: importPath,
+			Version:    version,
+			Symbol:     symbol,
+			Kind:       info.Kind,
+			Definition: definition,
+			Summary:    firstLine(withoutDeprecation(info.Comment)),
+			Deprecated: info.Deprecated,
+			URL:        DefaultPkgGoDevURL + "/" + importPath + "#" + symbol,
+			Text:       strings.TrimSpace(importPath + " " + symbol + "\n" + definition + "\n" + info.Comment),
+		})
+	}
+	return chunks
+}
+
+// GetPackageChunks はパッケージのソースコードを解析し、公開APIを宣言ごとのチャンクに分けて返します
+// hideDeprecated が true の場合は非推奨の宣言を除き、useCache が false の場合はキャッシュしたモジュールの zip を使用しません
+func (f *Fetcher) GetPackageChunks(importPath string, version string, hideDeprecated bool, useCache bool) ([]APIChunk, error) {
+	api, _, source, err := f.packageAPI(importPath, version, useCache, nil)
+	if err != nil {
+		return nil, err
+	}
+	if hideDeprecated {
+		api = FilterDeprecated(api)
+	}
+	return ChunkPackageAPI(importPath, source.Version, api), nil
+}
+
+// DefaultIndexPath は既定の索引のパス（~/.gopkgsummary/index.duckdb）を返します
+func DefaultIndexPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+	}
+	return filepath.Join(homeDir, CacheDirName, IndexFileName), nil
+}
+
+// VectorIndex は DuckDB の VSS 拡張機能（HNSW インデックス）を使用した公開APIのベクトルストアです
+// duckdb コマンドを実行して操作します
+type VectorIndex struct {
+	// データベースファイルのパス
+	dbPath string
+	// SQL を実行し、JSON 形式の結果を返す関数（テストで置き換えます）
+	run func(sql string) ([]byte, error)
+}
+
+// NewVectorIndex は dbPath のデータベースを使用する VectorIndex を作成します
+func NewVectorIndex(dbPath string) *VectorIndex {
+	ix := &VectorIndex{dbPath: dbPath}
+	ix.run = ix.runDuckDB
+	return ix
+}
+
+// indexMeta は索引の作成に使用した埋め込みモデルです
+type indexMeta struct {
+	Embedder   string `json:"embedder"`
+	Dimensions int    `json:"dimensions"`
+}
+
+// Index はチャンクを埋め込みモデルでベクトルに変換し、索引に追加します
+// 同じインポートパスの既存のチャンクは置き換えます。初めて索引付けする場合はテーブルと HNSW インデックスを作成します
+// 索引を作成したときと異なる埋め込みモデルや次元数は使用できません
+func (ix *VectorIndex) Index(embedder Embedder, chunks []APIChunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	meta, err := ix.meta()
+	if err != nil {
+		return err
+	}
+	if meta != nil && (meta.Embedder != embedder.Name() || meta.Dimensions != embedder.Dimensions()) {
+		return fmt.Errorf("索引は埋め込みモデル %s（%d 次元）で作成されています（指定: %s、%d 次元）",
+			meta.Embedder, meta.Dimensions, embedder.Name(), embedder.Dimensions())
+	}
+
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+	vectors, err := embedder.Embed(texts)
+	if err != nil {
+		return fmt.Errorf("埋め込みの作成に失敗しました: %w", err)
+	}
+	if len(vectors) != len(chunks) {
+		return fmt.Errorf("埋め込みの数がチャンクの数と一致しません: %d 件（チャンク: %d 件）", len(vectors), len(chunks))
+	}
+
+	var sql strings.Builder
+	sql.WriteString(vssSetupSQL)
+	if meta == nil {
+		sql.WriteString(createIndexSQL(embedder))
+	}
+	sql.WriteString(insertChunksSQL(chunks, vectors, embedder.Dimensions()))
+	if _, err := ix.run(sql.String()); err != nil {
+		return fmt.Errorf("索引の更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Search はクエリに近い宣言を、索引の作成に使用した埋め込みモデルで検索します
+func (ix *VectorIndex) Search(query string, limit int) ([]SemanticSearchResult, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	meta, err := ix.meta()
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("索引 %s にパッケージがありません。index コマンドで索引付けしてください", ix.dbPath)
+	}
+	embedder, err := NewEmbedder(meta.Embedder, meta.Dimensions)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := embedder.Embed([]string{query})
+	if err != nil {
+		return nil, fmt.Errorf("クエリの埋め込みの作成に失敗しました: %w", err)
+	}
+
+	output, err := ix.run(vssSetupSQL + searchSQL(vectors[0], limit))
+	if err != nil {
+		return nil, fmt.Errorf("索引の検索に失敗しました: %w", err)
+	}
+	var results []SemanticSearchResult
+	if err := decodeDuckDBJSON(output, &results); err != nil {
+		return nil, fmt.Errorf("検索結果の解析に失敗しました: %w", err)
+	}
+	return results, nil
+}
+
+// meta は索引の作成に使用した埋め込みモデルを返します（索引がまだない場合は nil）
+func (ix *VectorIndex) meta() (*indexMeta, error) {
+	output, err := ix.run(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (embedder VARCHAR, dimensions INTEGER);\nSELECT embedder, dimensions FROM %s LIMIT 1;\n",
+		apiIndexMetaTable, apiIndexMetaTable))
+	if err != nil {
+		return nil, fmt.Errorf("索引 %s を開けません: %w", ix.dbPath, err)
+	}
+	var metas []indexMeta
+	if err := decodeDuckDBJSON(output, &metas); err != nil {
+		return nil, fmt.Errorf("索引の情報の解析に失敗しました: %w", err)
+	}
+	if len(metas) == 0 {
+		return nil, nil
+	}
+	return &metas[0], nil
+}
+
+// runDuckDB は duckdb コマンドで SQL を実行し、JSON 形式の結果を返します
+// SQL は長くなるため標準入力から渡し、エラーが発生した時点で中止します
+func (ix *VectorIndex) runDuckDB(sql string) ([]byte, error) {
+	if _, err := exec.LookPath("duckdb"); err != nil {
+		return nil, fmt.Errorf("DuckDB コマンドが見つかりません。インストールしてください: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(ix.dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("索引のディレクトリの作成に失敗しました: %w", err)
+	}
+
+	cmd := exec.Command("duckdb", "-bail", "-json", ix.dbPath)
+	cmd.Stdin = strings.NewReader(sql)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// vssSetupSQL は VSS 拡張機能を読み込み、データベースファイルへの HNSW インデックスの保存を有効にします
+const vssSetupSQL = "INSTALL vss;\nLOAD vss;\nSET hnsw_enable_experimental_persistence = true;\n"
+
+// createIndexSQL は埋め込みモデルを記録し、チャンクのテーブルとコサイン距離の HNSW インデックスを作成する SQL を返します
+func createIndexSQL(embedder Embedder) string {
+	return fmt.Sprintf("INSERT INTO %s VALUES (%s, %d);\n", apiIndexMetaTable, sqlString(embedder.Name()), embedder.Dimensions()) +
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id VARCHAR, import_path VARCHAR, version VARCHAR, symbol VARCHAR, kind VARCHAR, "+
+			"definition VARCHAR, summary VARCHAR, deprecated BOOLEAN, url VARCHAR, vec FLOAT[%d]);\n", apiChunksTable, embedder.Dimensions()) +
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING HNSW (vec) WITH (metric = 'cosine');\n", apiChunksIndex, apiChunksTable)
+}
+
+// insertChunksSQL はチャンクのインポートパスの既存のチャンクを削除し、チャンクを追加する SQL を1つのトランザクションで返します
+func insertChunksSQL(chunks []APIChunk, vectors [][]float64, dimensions int) string {
+	var sql strings.Builder
+	sql.WriteString("BEGIN TRANSACTION;\n")
+
+	seen := map[string]bool{}
+	for _, chunk := range chunks {
+		if !seen[chunk.ImportPath] {
+			seen[chunk.ImportPath] = true
+			fmt.Fprintf(&sql, "DELETE FROM %s WHERE import_path = %s;\n", apiChunksTable, sqlString(chunk.ImportPath))
+		}
+	}
+
+	for i, chunk := range chunks {
+		fmt.Fprintf(&sql, "INSERT INTO %s VALUES (%s, %s, %s, %s, %s, %s, %s, %t, %s, %s);\n", apiChunksTable,
+			sqlString(chunk.ID), sqlString(chunk.ImportPath), sqlString(chunk.Version), sqlString(chunk.Symbol), sqlString(chunk.Kind),
+			sqlString(chunk.Definition), sqlString(chunk.Summary), chunk.Deprecated, sqlString(chunk.URL), sqlVector(vectors[i], dimensions))
+	}
+
+	sql.WriteString("COMMIT;\n")
+	return sql.String()
+}
+
+// searchSQL はベクトルとのコサイン距離が近い順にチャンクを返す SQL を返します
+// ORDER BY と LIMIT の組み合わせにより HNSW インデックスが使用されます
+func searchSQL(vector []float64, limit int) string {
+	return fmt.Sprintf("SELECT id, import_path AS \"importPath\", version, symbol, kind, definition, summary, deprecated, url, "+
+		"array_cosine_distance(vec, %s) AS distance FROM %s ORDER BY distance LIMIT %d;\n",
+		sqlVector(vector, len(vector)), apiChunksTable, limit)
+}
+
+// sqlString は文字列を SQL の文字列リテラルにします
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// sqlVector はベクトルを固定長の FLOAT 配列のリテラルにします
+func sqlVector(vector []float64, dimensions int) string {
+	values := make([]string, len(vector))
+	for i, v := range vector {
+		values[i] = strconv.FormatFloat(v, 'g', -1, 32)
+	}
+	return fmt.Sprintf("[%s]::FLOAT[%d]", strings.Join(values, ", "), dimensions)
+}
+
+// decodeDuckDBJSON は duckdb -json の出力を v に復号します
+// 結果を返す文ごとに JSON の配列が出力されるため、最後の配列を使用します。結果がない場合は v を変更しません
+func decodeDuckDBJSON(output []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(output))
+	var last json.RawMessage
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		last = raw
+	}
+	if last == nil {
+		return nil
+	}
+	return json.Unmarshal(last, v)
+}
+
+// FormatSemanticSearchResults は意味検索の結果を Markdown のリストに整形します
+func FormatSemanticSearchResults(query string, results []SemanticSearchResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# 「%s」の検索結果\n\n", query)
+	if len(results) == 0 {
+		sb.WriteString("該当する宣言が見つかりませんでした\n")
+		return sb.String()
+	}
+	for i, result := range results {
+		fmt.Fprintf(&sb, "%d. [%s.%s](%s)（%s、距離: %.3f）%s\n", i+1, result.ImportPath, result.Symbol, result.URL,
+			result.Kind, result.Distance, deprecatedMark(result.Deprecated))
+		fmt.Fprintf(&sb, "   `%s`\n", strings.ReplaceAll(result.Definition, "\n", " "))
+		if result.Summary != "" {
+			fmt.Fprintf(&sb, "   %s\n", result.Summary)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,218 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDuckDB は実行された SQL を記録し、SELECT に対して用意した JSON を返す duckdb の代わりです
+type fakeDuckDB struct {
+	// 実行された SQL
+	statements []string
+	// 索引の作成に使用した埋め込みモデル（api_index_meta の SELECT の結果）
+	meta string
+	// 検索の結果（api_chunks の SELECT の結果）
+	results string
+}
+
+func (d *fakeDuckDB) run(sql string) ([]byte, error) {
+	d.statements = append(d.statements, sql)
+	switch {
+	case strings.Contains(sql, "FROM "+apiIndexMetaTable):
+		return []byte(d.meta), nil
+	case strings.Contains(sql, "FROM "+apiChunksTable):
+		return []byte(d.results), nil
+	}
+	return nil, nil
+}
+
+func TestChunkPackageAPI(t *testing.T) {
+	p := NewParser(false)
+	api, err := p.ParseFile("sample.go", deprecatedSource)
+	require.NoError(t, err)
+
+	chunks := ChunkPackageAPI("example.com/net", "v1.2.0", api)
+	require.NotEmpty(t, chunks)
+
+	byID := map[string]APIChunk{}
+	for _, chunk := range chunks {
+		byID[chunk.ID] = chunk
+	}
+	dial, ok := byID["example.com/net@v1.2.0#Dial"]
+	require.True(t, ok)
+	assert.Equal(t, "Dial", dial.Symbol)
+	assert.Equal(t, "func", dial.Kind)
+	assert.True(t, dial.Deprecated)
+	assert.Equal(t, "https://pkg.go.dev/example.com/net#Dial", dial.URL)
+	assert.True(t, strings.HasPrefix(dial.Definition, "func Dial("), "関数はシグネチャを宣言とすること")
+	assert.NotContains(t, dial.Summary, "Deprecated:")
+	assert.Contains(t, dial.Text, "example.com/net Dial\n")
+}
+
+func TestVectorIndexIndex(t *testing.T) {
+	db := &fakeDuckDB{}
+	ix := NewVectorIndex("index.duckdb")
+	ix.run = db.run
+
+	chunks := []APIChunk{
+		{ID: "example.com/yaml@v1.0.0#Unmarshal", ImportPath: "example.com/yaml", Version: "v1.0.0", Symbol: "Unmarshal", Kind: "func",
+			Definition: "func Unmarshal(in []byte, out any) error", Summary: "Unmarshal は YAML を 'out' に復号します", Text: "Unmarshal"},
+		{ID: "example.com/yaml@v1.0.0#Marshal", ImportPath: "example.com/yaml", Version: "v1.0.0", Symbol: "Marshal", Kind: "func",
+			Definition: "func Marshal(in any) ([]byte, error)", Text: "Marshal"},
+	}
+	require.NoError(t, ix.Index(NewHashEmbedder(4), chunks))
+
+	require.Len(t, db.statements, 2)
+	sql := db.statements[1]
+	assert.Contains(t, sql, "LOAD vss;")
+	assert.Contains(t, sql, "INSERT INTO api_index_meta VALUES ('hash', 4);")
+	assert.Contains(t, sql, "vec FLOAT[4]")
+	assert.Contains(t, sql, "CREATE INDEX IF NOT EXISTS api_chunks_hnsw ON api_chunks USING HNSW (vec) WITH (metric = 'cosine');")
+	assert.Equal(t, 1, strings.Count(sql, "DELETE FROM api_chunks WHERE import_path = 'example.com/yaml';"), "パッケージの既存のチャンクを1回だけ削除すること")
+	assert.Equal(t, 2, strings.Count(sql, "INSERT INTO api_chunks VALUES"))
+	assert.Contains(t, sql, "'Unmarshal は YAML を ''out'' に復号します'", "文字列の引用符をエスケープすること")
+	assert.Less(t, strings.Index(sql, "BEGIN TRANSACTION;"), strings.Index(sql, "DELETE FROM"))
+	assert.True(t, strings.HasSuffix(sql, "COMMIT;\n"))
+
+	// 2回目以降はテーブルを作成せず、異なる埋め込みモデルは使用できない
+	db.statements = nil
+	db.meta = `[{"embedder":"hash","dimensions":4}]`
+	require.NoError(t, ix.Index(NewHashEmbedder(4), chunks[:1]))
+	require.Len(t, db.statements, 2)
+	assert.NotContains(t, db.statements[1], "CREATE INDEX")
+
+	err := ix.Index(NewHashEmbedder(8), chunks)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hash（4 次元）")
+}
+
+func TestVectorIndexSearch(t *testing.T) {
+	db := &fakeDuckDB{}
+	ix := NewVectorIndex("index.duckdb")
+	ix.run = db.run
+
+	_, err := ix.Search("parse a YAML file into a struct", 5)
+	require.Error(t, err, "索引がない場合はエラーになること")
+
+	db.meta = `[{"embedder":"hash","dimensions":4}]`
+	db.results = `[{"id":"example.com/yaml@v1.0.0#Unmarshal","importPath":"example.com/yaml","version":"v1.0.0","symbol":"Unmarshal",` +
+		`"kind":"func","definition":"func Unmarshal(in []byte, out any) error","summary":"Unmarshal は YAML を復号します",` +
+		`"deprecated":false,"url":"https://pkg.go.dev/example.com/yaml#Unmarshal","distance":0.25}]`
+	results, err := ix.Search("parse a YAML file into a struct", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "example.com/yaml", results[0].ImportPath)
+	assert.Equal(t, "Unmarshal", results[0].Symbol)
+	assert.Equal(t, 0.25, results[0].Distance)
+
+	sql := db.statements[len(db.statements)-1]
+	assert.Contains(t, sql, "array_cosine_distance(vec, [")
+	assert.Contains(t, sql, "]::FLOAT[4]) AS distance FROM api_chunks ORDER BY distance LIMIT 5;")
+
+	content := FormatSemanticSearchResults("parse a YAML file into a struct", results)
+	assert.Equal(t, "# 「parse a YAML file into a struct」の検索結果\n\n"+
+		"1. [example.com/yaml.Unmarshal](https://pkg.go.dev/example.com/yaml#Unmarshal)（func、距離: 0.250）\n"+
+		"   `func Unmarshal(in []byte, out any) error`\n"+
+		"   Unmarshal は YAML を復号します\n", content)
+}
+
+func TestVectorIndexDuckDB(t *testing.T) {
+	if _, err := exec.LookPath("duckdb"); err != nil {
+		t.Skip("duckdb コマンドが見つからないため省略します")
+	}
+	// VSS 拡張機能を取得できない環境では省略する
+	if out, err := exec.Command("duckdb", ":memory:", "-c", "INSTALL vss; LOAD vss;").CombinedOutput(); err != nil {
+		t.Skipf("VSS 拡張機能を読み込めないため省略します: %s", out)
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	yamlZip := newTestModuleZip(t, "example.com/yaml", "v1.0.0", map[string]string{
+		"go.mod": "module example.com/yaml\n",
+		"yaml.go": `// Package yaml は YAML を扱います
+package yaml
+
+// Unmarshal decodes a YAML document into the value pointed to by out.
+func Unmarshal(in []byte, out any) error { return nil }
+
+// Marshal encodes a value as a YAML document.
+func Marshal(in any) ([]byte, error) { return nil, nil }
+`,
+	})
+	webZip := newTestModuleZip(t, "example.com/web", "v0.3.0", map[string]string{
+		"go.mod": "module example.com/web\n",
+		"web.go": `// Package web は HTTP サーバーを提供します
+package web
+
+// ListenAndServe starts an HTTP server listening on the address.
+func ListenAndServe(addr string) error { return nil }
+`,
+	})
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/yaml/@v/list":
+			_, _ = w.Write([]byte("v1.0.0\n"))
+		case "/example.com/yaml/@v/v1.0.0.zip":
+			_, _ = w.Write(yamlZip)
+		case "/example.com/web/@v/list":
+			_, _ = w.Write([]byte("v0.3.0\n"))
+		case "/example.com/web/@v/v0.3.0.zip":
+			_, _ = w.Write(webZip)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(proxy.Close)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.proxyURL = proxy.URL
+
+	// index と同様にパッケージごとに索引付けし、semantic-search と同様に検索する
+	ix := NewVectorIndex(filepath.Join(t.TempDir(), IndexFileName))
+	embedder, err := NewEmbedder(DefaultEmbedder, 0)
+	require.NoError(t, err)
+	for _, importPath := range []string{"example.com/yaml", "example.com/web"} {
+		chunks, err := f.GetPackageChunks(importPath, "latest", false, true)
+		require.NoError(t, err)
+		require.NoError(t, ix.Index(embedder, chunks))
+	}
+	// 同じパッケージを再び索引付けした場合はチャンクを置き換える
+	chunks, err := f.GetPackageChunks("example.com/yaml", "latest", false, true)
+	require.NoError(t, err)
+	require.NoError(t, ix.Index(embedder, chunks))
+
+	results, err := ix.Search("decode a YAML document", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 3, "再び索引付けしたパッケージのチャンクが重複しないこと")
+	assert.Equal(t, "example.com/yaml", results[0].ImportPath)
+	assert.Equal(t, "Unmarshal", results[0].Symbol)
+	assert.Equal(t, "func Unmarshal(in []byte, out any) error", results[0].Definition)
+	assert.LessOrEqual(t, results[0].Distance, results[1].Distance, "距離の近い順に返すこと")
+
+	results, err = ix.Search("start an HTTP server", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "ListenAndServe", results[0].Symbol)
+
+	// 索引を作成したときと異なる埋め込みモデルは使用できない
+	err = ix.Index(NewHashEmbedder(8), chunks)
+	assert.Error(t, err)
+}
+
+func TestDecodeDuckDBJSON(t *testing.T) {
+	var metas []indexMeta
+	require.NoError(t, decodeDuckDBJSON(nil, &metas))
+	assert.Empty(t, metas, "結果がない場合は変更しないこと")
+
+	require.NoError(t, decodeDuckDBJSON([]byte("[{\"embedder\":\"old\",\"dimensions\":2}]\n[{\"embedder\":\"hash\",\"dimensions\":4}]\n"), &metas))
+	assert.Equal(t, []indexMeta{{Embedder: "hash", Dimensions: 4}}, metas, "最後の文の結果を使用すること")
+
+	assert.Error(t, decodeDuckDBJSON([]byte("Error: Catalog Error"), &metas))
+}
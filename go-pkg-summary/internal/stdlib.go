@@ -0,0 +1,309 @@
+// Package stdlib は標準ライブラリのパッケージのソースコードを、ローカルの GOROOT または Go のリポジトリから取得する機能を提供します
+package internal
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"go/build"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// StdlibModulePath は標準ライブラリのモジュールパスです
+	StdlibModulePath = "std"
+	// StdlibLicense は標準ライブラリのライセンスです
+	StdlibLicense = "BSD-3-Clause"
+	// DefaultStdlibSourceURL は標準ライブラリのソースコードを取得する Go のリポジトリの URL です
+	DefaultStdlibSourceURL = "https://go.googlesource.com/go"
+	// DefaultStdlibMirrorURL は Go のリポジトリのミラー（golang/go）の tarball を取得する URL です
+	DefaultStdlibMirrorURL = "https://codeload.github.com/golang/go"
+
+	// stdlibSourceDirName はキャッシュに保存する標準ライブラリのソースコードのディレクトリ名です
+	stdlibSourceDirName = "src"
+)
+
+// stdlibVersionPattern は Go のリリースのバージョン（"v" または "go" を除いたもの）の形式です
+var stdlibVersionPattern = regexp.MustCompile(`^([0-9]+)\.([0-9]+)(\.[0-9]+)?((rc|beta)[0-9]+)?$`)
+
+// localGoEnv はローカルの Go の GOROOT とバージョンを返します
+// go コマンドが見つからない場合は、環境変数 GOROOT と GOROOT/VERSION ファイルを使用します
+func localGoEnv() (string, string) {
+	out, err := exec.Command("go", "env", "GOROOT", "GOVERSION").Output()
+	if err == nil {
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		if len(lines) == 2 && lines[0] != "" {
+			return lines[0], strings.TrimSpace(lines[1])
+		}
+	}
+
+	goroot := os.Getenv("GOROOT")
+	if goroot == "" {
+		return "", ""
+	}
+	data, err := os.ReadFile(filepath.Join(goroot, "VERSION"))
+	if err != nil {
+		return goroot, ""
+	}
+	version, _, _ := strings.Cut(string(data), "\n")
+	return goroot, strings.TrimSpace(version)
+}
+
+// localGo はローカルの Go の GOROOT とバージョンを返します
+// go コマンドの実行を避けるため、初めて必要になったときに取得します
+func (f *Fetcher) localGo() (string, string) {
+	f.goEnvOnce.Do(func() {
+		f.goroot, f.goVersion = f.goEnv()
+	})
+	return f.goroot, f.goVersion
+}
+
+// IsStdlibPackage はインポートパスが標準ライブラリのパッケージかどうかを判定します
+// 最初の要素にドットを含まないパスを標準ライブラリとみなします
+// "fmt" のように要素が1つの場合は、短いパッケージ名と区別するためローカルの GOROOT に存在するものに限ります
+func (f *Fetcher) IsStdlibPackage(importPath string) bool {
+	first, _, hasSlash := strings.Cut(importPath, "/")
+	if first == "" || strings.Contains(first, ".") {
+		return false
+	}
+	if hasSlash {
+		return true
+	}
+
+	goroot, _ := f.localGo()
+	if goroot == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(goroot, stdlibSourceDirName, importPath))
+	return err == nil && info.IsDir()
+}
+
+// ResolveStdlibVersion は標準ライブラリのバージョンを Go のリポジトリのタグ（例: go1.22.0）に変換します
+// "latest" または空の場合はローカルの Go のバージョンを返します
+// "1.22.0"、"v1.22.0"、"go1.22.0" のいずれの形式も受け付けます
+// Go 1.21 以降の最初のリリースのタグは go1.21.0 のようにパッチバージョンを含むため、"1.22" は go1.22.0 にします
+func (f *Fetcher) ResolveStdlibVersion(version string) (string, error) {
+	if version == "" || version == "latest" {
+		goroot, goVersion := f.localGo()
+		if goroot == "" || goVersion == "" {
+			return "", fmt.Errorf("ローカルの GOROOT が見つかりません。go1.22.0 のように標準ライブラリのバージョンを指定してください")
+		}
+		return goVersion, nil
+	}
+
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(version, "go"), "v")
+	m := stdlibVersionPattern.FindStringSubmatch(trimmed)
+	if m == nil {
+		return "", fmt.Errorf("標準ライブラリのバージョンの形式が正しくありません: %s（例: go1.22.0）", version)
+	}
+	if m[3] == "" && m[4] == "" {
+		major, _ := strconv.Atoi(m[1])
+		minor, _ := strconv.Atoi(m[2])
+		if major > 1 || major == 1 && minor >= 21 {
+			trimmed += ".0"
+		}
+	}
+	return "go" + trimmed, nil
+}
+
+// GetStdlibSource は標準ライブラリのパッケージのソースコードを取得します
+// バージョンがローカルの Go と一致する場合は GOROOT/src を、それ以外の場合は Go のリポジトリから
+// パッケージのディレクトリを取得してキャッシュに保存したものを使用します
+// 返す ModuleSource のファイルシステムは GOROOT/src を基点とします
+func (f *Fetcher) GetStdlibSource(importPath string, version string) (*ModuleSource, error) {
+	tag, err := f.ResolveStdlibVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if goroot, goVersion := f.localGo(); goroot != "" && tag == goVersion {
+		if f.debug {
+			fmt.Printf("ローカルの GOROOT から標準ライブラリを取得します: %s\n", goroot)
+		}
+		return &ModuleSource{
+			ModulePath: StdlibModulePath,
+			Version:    tag,
+			FS:         os.DirFS(filepath.Join(goroot, stdlibSourceDirName)),
+		}, nil
+	}
+
+	// パッケージごとにキャッシュし、GOROOT/src と同じ構成で展開する
+	root := filepath.Join(f.cache.GetCacheDir(importPath, tag), stdlibSourceDirName)
+	packageDir := filepath.Join(root, filepath.FromSlash(importPath))
+	if _, err := os.Stat(packageDir); err != nil {
+		if err := f.downloadStdlibPackage(importPath, tag, packageDir); err != nil {
+			return nil, err
+		}
+	} else if f.debug {
+		fmt.Printf("キャッシュから標準ライブラリを取得しました: %s@%s\n", importPath, tag)
+	}
+
+	return &ModuleSource{ModulePath: StdlibModulePath, Version: tag, FS: os.DirFS(root)}, nil
+}
+
+// downloadStdlibPackage は Go のリポジトリからパッケージのディレクトリを取得し、destDir に展開します
+// go.googlesource.com のディレクトリ単位のアーカイブを取得し、失敗した場合はミラーのリポジトリ全体の tarball から取り出します
+func (f *Fetcher) downloadStdlibPackage(importPath string, tag string, destDir string) error {
+	archiveURL := fmt.Sprintf("%s/+archive/refs/tags/%s/src/%s.tar.gz", f.stdlibSourceURL, tag, importPath)
+	archiveErr := f.extractStdlibArchive(archiveURL, destDir, func(name string) string {
+		return name
+	})
+	if archiveErr == nil {
+		return nil
+	}
+
+	if f.debug {
+		fmt.Printf("Go のリポジトリからの取得に失敗しました: %v\n", archiveErr)
+	}
+
+	// ミラーの tarball は "go-go1.22.0/src/net/http/..." の形式で格納されている
+	mirrorURL := fmt.Sprintf("%s/tar.gz/refs/tags/%s", f.stdlibMirrorURL, tag)
+	prefix := stdlibSourceDirName + "/" + importPath + "/"
+	mirrorErr := f.extractStdlibArchive(mirrorURL, destDir, func(name string) string {
+		_, rest, _ := strings.Cut(name, "/")
+		rel, ok := strings.CutPrefix(rest, prefix)
+		if !ok {
+			return ""
+		}
+		return rel
+	})
+	if mirrorErr != nil {
+		return fmt.Errorf("標準ライブラリ %s@%s の取得に失敗しました: %w", importPath, tag, errors.Join(archiveErr, mirrorErr))
+	}
+	return nil
+}
+
+// extractStdlibArchive は tar.gz を取得し、relPath が返すパスのファイルを destDir に展開します
+// relPath が空文字列を返すファイルは展開しません
+// 途中で失敗した場合に不完全なキャッシュが残らないよう、一時ディレクトリに展開してから移動します
+func (f *Fetcher) extractStdlibArchive(archiveURL string, destDir string, relPath func(name string) string) error {
+	if f.debug {
+		fmt.Printf("標準ライブラリの URL: %s\n", archiveURL)
+	}
+
+	resp, err := f.client.Get(archiveURL)
+	if err != nil {
+		return fmt.Errorf("リクエストに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("リクエストに失敗しました: %s - %s", resp.Status, archiveURL)
+	}
+
+	if err := f.cache.EnsureDir(filepath.Dir(destDir)); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(destDir), ".download-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("アーカイブの展開に失敗しました: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	extracted := 0
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("アーカイブの展開に失敗しました: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		rel := relPath(strings.TrimPrefix(header.Name, "./"))
+		if rel == "" || !fs.ValidPath(rel) {
+			continue
+		}
+
+		target := filepath.Join(tmpDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("アーカイブの展開に失敗しました: %w", err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return err
+		}
+		extracted++
+	}
+
+	if extracted == 0 {
+		return fmt.Errorf("アーカイブにパッケージのファイルが含まれていません: %s", archiveURL)
+	}
+	return os.Rename(tmpDir, destDir)
+}
+
+// stdlibPackage は標準ライブラリのパッケージのソースコードを取得し、go/build で読み込みます
+func (f *Fetcher) stdlibPackage(importPath string, version string) (*ModuleSource, *build.Package, error) {
+	source, err := f.GetStdlibSource(importPath, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctxt := moduleBuildContext(source.FS)
+	bp, err := ctxt.ImportDir("/"+importPath, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("パッケージ %s の読み込みに失敗しました: %w", importPath, err)
+	}
+	return source, bp, nil
+}
+
+// getStdlibPackageInfo は標準ライブラリのパッケージの情報をソースコードから生成します
+func (f *Fetcher) getStdlibPackageInfo(importPath string, version string) (*Package, error) {
+	source, bp, err := f.stdlibPackage(importPath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Package{
+		Name:       bp.Name,
+		ImportPath: importPath,
+		Version:    source.Version,
+		Synopsis:   bp.Doc,
+		DocURL:     fmt.Sprintf("https://pkg.go.dev/%s@%s", importPath, source.Version),
+		RepoURL:    DefaultStdlibSourceURL,
+		License:    StdlibLicense,
+	}, nil
+}
+
+// listStdlibFiles は標準ライブラリのパッケージのディレクトリ以下のファイルを GOROOT/src からのパスで返します
+func (f *Fetcher) listStdlibFiles(importPath string, version string) ([]string, error) {
+	source, err := f.GetStdlibSource(importPath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := fs.Sub(source.FS, importPath)
+	if err != nil {
+		return nil, fmt.Errorf("ファイル一覧の取得に失敗しました: %w", err)
+	}
+	files, err := (&ModuleSource{FS: sub}).Files()
+	if err != nil {
+		return nil, err
+	}
+	for i, file := range files {
+		files[i] = path.Join(importPath, file)
+	}
+	return files, nil
+}
@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stdlibListSource はテスト用の標準ライブラリのパッケージのソースコードです
+const stdlibListSource = `// Package list implements a doubly linked list.
+package list
+
+// List represents a doubly linked list.
+type List struct{}
+
+// New returns an initialized list.
+func New() *List { return &List{} }
+`
+
+// newTestTarGz は tar.gz のアーカイブを作成します
+func newTestTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// newStdlibFetcher はテスト用の GOROOT と Go のリポジトリを使う Fetcher を作成します
+func newStdlibFetcher(t *testing.T, handler http.Handler) *Fetcher {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	goroot := t.TempDir()
+	dir := filepath.Join(goroot, "src", "container", "list")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "list.go"), []byte(stdlibListSource), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "list_test.go"), []byte("package list\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(goroot, "src", "fmt"), 0755))
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	f, err := NewFetcher(false)
+	require.NoError(t, err)
+	f.goEnv = func() (string, string) { return goroot, "go1.23.4" }
+	f.stdlibSourceURL = server.URL + "/go"
+	f.stdlibMirrorURL = server.URL + "/mirror"
+	return f
+}
+
+func TestIsStdlibPackage(t *testing.T) {
+	f := newStdlibFetcher(t, http.NotFoundHandler())
+
+	for importPath, want := range map[string]bool{
+		"fmt":                   true,
+		"container/list":        true,
+		"net/http":              true,
+		"zap":                   false,
+		"go.uber.org/zap":       false,
+		"github.com/spf13/cobr": false,
+	} {
+		assert.Equal(t, want, f.IsStdlibPackage(importPath), importPath)
+	}
+}
+
+func TestResolveStdlibVersion(t *testing.T) {
+	f := newStdlibFetcher(t, http.NotFoundHandler())
+
+	for version, want := range map[string]string{
+		"latest":   "go1.23.4",
+		"":         "go1.23.4",
+		"go1.22.0": "go1.22.0",
+		"v1.22.0":  "go1.22.0",
+		"1.21rc2":  "go1.21rc2",
+		"go1.20":   "go1.20",
+		"1.22":     "go1.22.0",
+		"go1.21":   "go1.21.0",
+		"v1.23":    "go1.23.0",
+		"1.19":     "go1.19",
+		"1.22rc1":  "go1.22rc1",
+	} {
+		got, err := f.ResolveStdlibVersion(version)
+		require.NoError(t, err, version)
+		assert.Equal(t, want, got, version)
+	}
+
+	_, err := f.ResolveStdlibVersion("master")
+	assert.Error(t, err)
+
+	f = newStdlibFetcher(t, http.NotFoundHandler())
+	f.goEnv = func() (string, string) { return "", "" }
+	_, err = f.ResolveStdlibVersion("latest")
+	assert.Error(t, err, "ローカルの GOROOT がない場合はバージョンの指定が必要なこと")
+}
+
+func TestGetStdlibSourceFromGOROOT(t *testing.T) {
+	f := newStdlibFetcher(t, http.NotFoundHandler())
+
+	files, err := f.ListPackageFiles("container/list", "latest")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"container/list/list.go", "container/list/list_test.go"}, files)
+
+	pkg, err := f.getPackageInfo("container/list", "go1.23.4")
+	require.NoError(t, err)
+	assert.Equal(t, &Package{
+		Name:       "list",
+		ImportPath: "container/list",
+		Version:    "go1.23.4",
+		Synopsis:   "Package list implements a doubly linked list.",
+		DocURL:     "https://pkg.go.dev/container/list@go1.23.4",
+		RepoURL:    DefaultStdlibSourceURL,
+		License:    StdlibLicense,
+	}, pkg)
+
+	decl, err := f.ReadPackageSymbol("container/list", "latest", "New")
+	require.NoError(t, err)
+	assert.Equal(t, "container/list/list.go", decl.Filename)
+
+	card, err := f.GetPackageCard("container/list", "latest", CardOptions{})
+	require.NoError(t, err)
+	require.Len(t, card.Constructors, 1)
+	assert.Equal(t, "List", card.Constructors[0].Type)
+}
+
+func TestGetStdlibSourceFromRepository(t *testing.T) {
+	archive := newTestTarGz(t, map[string]string{"list.go": stdlibListSource, "list_test.go": "package list\n"})
+	var hits atomic.Int32
+	f := newStdlibFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/go/+archive/refs/tags/go1.22.0/src/container/list.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		hits.Add(1)
+		_, _ = w.Write(archive)
+	}))
+
+	for i := 0; i < 2; i++ {
+		content, err := f.GetPackage("container/list", "1.22.0", GetPackageOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, content, "# list\n\nインポートパス: container/list\nバージョン: go1.22.0\n")
+		assert.Contains(t, content, "ライセンス: BSD-3-Clause\n")
+		assert.Contains(t, content, "- container/list/list.go\n")
+	}
+	assert.Equal(t, int32(1), hits.Load(), "2回目はキャッシュから読み込むこと")
+}
+
+func TestGetStdlibSourceFromMirror(t *testing.T) {
+	archive := newTestTarGz(t, map[string]string{
+		"go-go1.22.0/README.md":                  "# The Go Programming Language\n",
+		"go-go1.22.0/src/container/list/list.go": stdlibListSource,
+		"go-go1.22.0/src/container/ring/ring.go": "package ring\n",
+	})
+	f := newStdlibFetcher(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mirror/tar.gz/refs/tags/go1.22.0" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(archive)
+	}))
+
+	// パッチバージョンを省略した 1.22 は go1.22.0 のタグから取得する
+	files, err := f.ListPackageFiles("container/list", "1.22")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"container/list/list.go"}, files, "パッケージのディレクトリのみを取り出すこと")
+
+	_, err = f.ListPackageFiles("container/heap", "go1.22.0")
+	assert.Error(t, err, "アーカイブにパッケージが含まれない場合はエラーになること")
+}
+
+func TestGetStdlibPackageVulnDB(t *testing.T) {
+	f := newStdlibFetcher(t, http.NotFoundHandler())
+	db, err := OpenVulnDB(vulnDBFixture)
+	require.NoError(t, err)
+
+	content, err := f.GetPackage("container/list", "latest", GetPackageOptions{VulnDB: db})
+	require.NoError(t, err)
+	assert.NotContains(t, content, "脆弱性の照合に失敗しました")
+	assert.Contains(t, content, "container/list@go1.23.4 に影響する既知の脆弱性が 1 件あります。")
+	assert.Contains(t, content, "### GO-2024-0005: Excessive memory use in container/list")
+	assert.NotContains(t, content, "GO-2024-0006", "他のパッケージの脆弱性は含めないこと")
+}
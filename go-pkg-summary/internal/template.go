@@ -0,0 +1,292 @@
+// Package template はパッケージのサマリーを text/template で出力する機能を提供します
+package internal
+
+import (
+	_ "embed"
+	"fmt"
+	"go/build"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/mod/modfile"
+)
+
+// defaultSummaryTemplate は既定のサマリーのテンプレートです
+//
+//go:embed templates/summary.md.tmpl
+var defaultSummaryTemplate string
+
+// SummaryData はサマリーのテンプレートに渡すデータです
+//
+// テンプレートでは次のフィールドとメソッドを使用できます
+//
+//	.Package   パッケージ情報（Name、ImportPath、Version、Synopsis、DocURL、RepoURL、License、Published、ImportedBy）
+//	.Files     ファイル一覧（--include と --exclude で絞り込んだもの）
+//	.GoMod     go.mod の内容と解析結果（Content、Module、Go、Requires）。go.mod がない場合は nil
+//	.Readme    整形した README（Content と、コード例の Examples）。README.md がない場合は nil
+//	.Types     公開APIを種類ごとにまとめたもの（TypeGroups）。使用した場合のみソースコードを解析します
+//	.Status    モジュールの非推奨と、バージョンの撤回の状態（ModuleStatus）。確認できない場合は nil
+type SummaryData struct {
+	// パッケージ情報（ライセンスはモジュールのライセンスファイルから検出したもの）
+	Package *Package
+	// ファイル一覧
+	Files []string
+	// go.mod の内容と解析結果
+	GoMod *GoModInfo
+	// 整形した README
+	Readme *Readme
+	// モジュールの非推奨と、バージョンの撤回の状態
+	Status *ModuleStatus
+
+	// Types を遅延して取得するための情報
+	types func() (*TypeGroups, error)
+}
+
+// GoModInfo は go.mod の内容と解析結果です
+type GoModInfo struct {
+	// go.mod の内容
+	Content string
+	// モジュールパス
+	Module string
+	// go ディレクティブのバージョン
+	Go string
+	// require ディレクティブ
+	Requires []GoModRequire
+}
+
+// GoModRequire は go.mod の require ディレクティブの1件です
+type GoModRequire struct {
+	// モジュールパス
+	Path string
+	// バージョン
+	Version string
+	// indirect コメントが付いているか
+	Indirect bool
+}
+
+// TypeGroups は公開APIを宣言の種類ごとにまとめたものです
+type TypeGroups struct {
+	// 定数
+	Consts []TypeInfo
+	// 変数
+	Vars []TypeInfo
+	// 関数（関数型の宣言は Types に含みます）
+	Funcs []TypeInfo
+	// 構造体
+	Structs []TypeInfo
+	// インターフェース
+	Interfaces []TypeInfo
+	// 型制約（型集合を持つインターフェース）
+	Constraints []TypeInfo
+	// その他の型（関数型や基本型に基づく型）
+	Types []TypeInfo
+	// メソッド
+	Methods []TypeInfo
+	// 全ての宣言（ソースコードの順）
+	All []TypeInfo
+}
+
+// Types は公開APIを宣言の種類ごとにまとめて返します
+// 初めて呼び出したときにパッケージのソースコードを解析します
+func (d *SummaryData) Types() (*TypeGroups, error) {
+	if d.types == nil {
+		return &TypeGroups{}, nil
+	}
+	return d.types()
+}
+
+// summaryFuncs はサマリーのテンプレートで使用できる関数です
+//
+//	codeFence LANG CODE  CODE をコードブロックで囲みます（CODE にバッククォートの並びがある場合はより長いフェンスを使用します）
+//	anchor TEXT          見出し TEXT へのリンクに使用するアンカー（GitHub と同じ形式）を返します
+//	truncate N TEXT      TEXT が N 文字を超える場合に切り詰め、末尾に "…" を付けます
+//	firstLine TEXT       TEXT の最初の行を返します
+//	join SEP LIST        LIST を SEP で連結します
+//	indent N TEXT        TEXT の各行を N 個の空白で字下げします
+//	usageExamples LIST   README のコード例を Usage examples セクションに整形します
+var summaryFuncs = template.FuncMap{
+	"codeFence": codeFence,
+	"anchor":    headingAnchor,
+	"truncate":  truncateText,
+	"firstLine": firstLine,
+	"join": func(sep string, items []string) string {
+		return strings.Join(items, sep)
+	},
+	"indent": func(n int, text string) string {
+		prefix := strings.Repeat(" ", n)
+		return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+	},
+	"usageExamples": FormatUsageExamples,
+}
+
+// DefaultSummaryTemplate は既定のサマリーのテンプレートを返します
+func DefaultSummaryTemplate() *template.Template {
+	return template.Must(ParseSummaryTemplate("summary.md.tmpl", defaultSummaryTemplate))
+}
+
+// ParseSummaryTemplate はサマリーのテンプレートを解析します
+func ParseSummaryTemplate(name string, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(summaryFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("テンプレートの解析に失敗しました: %w", err)
+	}
+	return tmpl, nil
+}
+
+// LoadSummaryTemplate はファイルからサマリーのテンプレートを読み込みます
+func LoadSummaryTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("テンプレートの読み込みに失敗しました: %w", err)
+	}
+	return ParseSummaryTemplate(path, string(data))
+}
+
+// RenderSummary はテンプレートにサマリーのデータを適用します
+func RenderSummary(tmpl *template.Template, data *SummaryData) (string, error) {
+	var output strings.Builder
+	if err := tmpl.Execute(&output, data); err != nil {
+		return "", fmt.Errorf("テンプレートの適用に失敗しました: %w", err)
+	}
+	return output.String(), nil
+}
+
+// parseGoModInfo は go.mod の内容を解析します（解析できない場合も内容は保持します）
+func parseGoModInfo(content string) *GoModInfo {
+	info := &GoModInfo{Content: content}
+	mf, err := modfile.ParseLax("go.mod", []byte(content), nil)
+	if err != nil {
+		return info
+	}
+	if mf.Module != nil {
+		info.Module = mf.Module.Mod.Path
+	}
+	if mf.Go != nil {
+		info.Go = mf.Go.Version
+	}
+	for _, r := range mf.Require {
+		info.Requires = append(info.Requires, GoModRequire{Path: r.Mod.Path, Version: r.Mod.Version, Indirect: r.Indirect})
+	}
+	return info
+}
+
+// groupTypes は公開APIを宣言の種類ごとにまとめます
+func groupTypes(api []TypeInfo) *TypeGroups {
+	groups := &TypeGroups{All: api}
+	for _, info := range api {
+		switch {
+		case info.Kind == "const":
+			groups.Consts = append(groups.Consts, info)
+		case info.Kind == "var":
+			groups.Vars = append(groups.Vars, info)
+		case info.Kind == "method":
+			groups.Methods = append(groups.Methods, info)
+		case info.Kind == "struct":
+			groups.Structs = append(groups.Structs, info)
+		case info.Kind == "interface":
+			groups.Interfaces = append(groups.Interfaces, info)
+		case info.Kind == "constraint":
+			groups.Constraints = append(groups.Constraints, info)
+		case isTypeDecl(info):
+			groups.Types = append(groups.Types, info)
+		case info.Kind == "func":
+			groups.Funcs = append(groups.Funcs, info)
+		}
+	}
+	return groups
+}
+
+// lazyTypes はパッケージの公開APIを初めて必要になったときに解析する関数を返します
+// hideDeprecated が true の場合は非推奨の宣言を除き、useCache が false の場合はキャッシュしたモジュールの zip を使用しません
+func (f *Fetcher) lazyTypes(importPath string, version string, hideDeprecated bool, useCache bool) func() (*TypeGroups, error) {
+	var once sync.Once
+	var groups *TypeGroups
+	var err error
+	return func() (*TypeGroups, error) {
+		once.Do(func() {
+			var api []TypeInfo
+			// テンプレートが .Anchor で宣言にアンカーを付けられるよう、テンプレートに渡す全ての宣言をアンカーの対象にする
+			api, _, _, err = f.packageAPI(importPath, version, useCache, func(api []TypeInfo) []TypeInfo {
+				if hideDeprecated {
+					return FilterDeprecated(api)
+				}
+				return api
+			})
+			if err == nil {
+				if hideDeprecated {
+					api = FilterDeprecated(api)
+				}
+				groups = groupTypes(api)
+			}
+		})
+		return groups, err
+	}
+}
+
+// packageAPI はパッケージのソースコードを取得し、公開APIと go/build で読み込んだパッケージ、ソースコードを返します
+// 公開APIのドキュメントコメントのリンクは、shown が返す宣言へのアンカーとして解決します（shown が nil の場合は全ての宣言）
+// useCache が false の場合はキャッシュしたモジュールの zip を使用せずに取得し直します
+func (f *Fetcher) packageAPI(importPath string, version string, useCache bool, shown func(api []TypeInfo) []TypeInfo) ([]TypeInfo, *build.Package, *ModuleSource, error) {
+	source, dir, err := f.packageSource(importPath, version, useCache)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ctxt := moduleBuildContext(source.FS)
+	bp, err := ctxt.ImportDir("/"+dir, 0)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("パッケージ %s の読み込みに失敗しました: %w", importPath, err)
+	}
+
+	api, imports := parsePackageAPI(source, dir, bp, NewParser(f.debug), f.debug)
+	anchored := api
+	if shown != nil {
+		anchored = shown(api)
+	}
+	NewDocLinker(importPath, api, anchored, imports).Apply(api)
+	return api, bp, source, nil
+}
+
+// codeFence は code をコードブロックで囲みます
+// code にバッククォートの並びが含まれる場合は、それより長いフェンスを使用します
+func codeFence(lang string, code string) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + lang + "\n" + strings.TrimRight(code, "\n") + "\n" + fence
+}
+
+// headingAnchor は GitHub と同じ規則で見出しのアンカーを返します
+// 小文字に変換し、英数字・ハイフン・アンダースコア以外の記号を除き、空白をハイフンに置き換えます
+func headingAnchor(text string) string {
+	var anchor strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case r == ' ':
+			anchor.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r):
+			anchor.WriteRune(r)
+		}
+	}
+	return anchor.String()
+}
+
+// truncateText は text が n 文字を超える場合に切り詰め、末尾に "…" を付けます
+func truncateText(n int, text string) string {
+	if n <= 0 || utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update を指定すると、testdata/summary のゴールデンファイルを現在の出力で更新します
+var update = flag.Bool("update", false, "testdata/summary のゴールデンファイルを更新する")
+
+// assertGolden は content が testdata/summary/name と一致することを確認します
+func assertGolden(t *testing.T, name string, content string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "summary", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), content)
+}
+
+func TestSummaryTemplateGolden(t *testing.T) {
+	f := newReplayFetcher(t, "testify-assert")
+	const importPath = "github.com/stretchr/testify/assert"
+
+	t.Run("既定のテンプレート", func(t *testing.T) {
+		content, err := f.GetPackage(importPath, "latest", GetPackageOptions{})
+		require.NoError(t, err)
+		assertGolden(t, "testify-assert.golden.md", content)
+	})
+
+	t.Run("README のセクションとファイルの絞り込み", func(t *testing.T) {
+		content, err := f.GetPackage(importPath, "latest", GetPackageOptions{
+			ReadmeSections: []string{"Installation"},
+			Exclude:        []string{"*_test.go"},
+		})
+		require.NoError(t, err)
+		assertGolden(t, "testify-assert-sections.golden.md", content)
+	})
+
+	t.Run("カスタムテンプレート", func(t *testing.T) {
+		tmpl, err := LoadSummaryTemplate(filepath.Join("testdata", "summary", "custom.tmpl"))
+		require.NoError(t, err)
+
+		content, err := f.GetPackage(importPath, "latest", GetPackageOptions{UseCache: true, Template: tmpl})
+		require.NoError(t, err)
+		assertGolden(t, "testify-assert-custom.golden.md", content)
+	})
+}
+
+func TestParseSummaryTemplateError(t *testing.T) {
+	_, err := ParseSummaryTemplate("broken", "{{range .Files}")
+	assert.Error(t, err)
+
+	tmpl, err := ParseSummaryTemplate("missing", "{{.Unknown}}")
+	require.NoError(t, err)
+	_, err = RenderSummary(tmpl, &SummaryData{Package: &Package{}})
+	assert.Error(t, err, "存在しないフィールドは適用時のエラーになること")
+}
+
+func TestSummaryFuncs(t *testing.T) {
+	assert.Equal(t, "```go\nfmt.Println()\n```", codeFence("go", "fmt.Println()\n\n"))
+	assert.Equal(t, "````md\n```go\nx\n```\n````", codeFence("md", "```go\nx\n```"), "内容のフェンスより長いフェンスを使用すること")
+
+	assert.Equal(t, "clientdo-の使い方", headingAnchor("Client.Do の使い方"))
+	assert.Equal(t, "new-option_type", headingAnchor(" New Option_Type "))
+
+	assert.Equal(t, "テンプレー…", truncateText(5, "テンプレートの例"))
+	assert.Equal(t, "short", truncateText(10, "short"))
+
+	assert.Equal(t, "  a\n  b", summaryFuncs["indent"].(func(int, string) string)(2, "a\nb"))
+}
+
+func TestGroupTypes(t *testing.T) {
+	groups := groupTypes([]TypeInfo{
+		{Name: "MaxSize", Kind: "const"},
+		{Name: "ErrClosed", Kind: "var"},
+		{Name: "Client", Kind: "struct"},
+		{Name: "Doer", Kind: "interface"},
+		{Name: "Option", Kind: "func", Signature: "type Option func(*Client)"},
+		{Name: "Level", Kind: "type"},
+		{Name: "New", Kind: "func", Signature: "func New() *Client"},
+		{Name: "Do", Kind: "method", Receiver: "Client"},
+	})
+
+	names := func(infos []TypeInfo) []string {
+		var result []string
+		for _, info := range infos {
+			result = append(result, info.Name)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"MaxSize"}, names(groups.Consts))
+	assert.Equal(t, []string{"ErrClosed"}, names(groups.Vars))
+	assert.Equal(t, []string{"Client"}, names(groups.Structs))
+	assert.Equal(t, []string{"Doer"}, names(groups.Interfaces))
+	assert.Equal(t, []string{"Option", "Level"}, names(groups.Types))
+	assert.Equal(t, []string{"New"}, names(groups.Funcs))
+	assert.Equal(t, []string{"Do"}, names(groups.Methods))
+	assert.Len(t, groups.All, 8)
+}
+
+func TestDefaultSummaryTemplateModuleStatus(t *testing.T) {
+	data := &SummaryData{
+		Package: &Package{Name: "old", ImportPath: "example.com/old", Version: "v1.1.0"},
+		Files:   []string{"old.go"},
+		Status: &ModuleStatus{
+			ModulePath:    "example.com/old",
+			Version:       "v1.1.0",
+			LatestVersion: "v1.3.0",
+			Deprecated:    "example.com/new を使用してください。",
+			Retracted:     true,
+			RetractReason: "データが破損する不具合があります",
+		},
+	}
+
+	content, err := RenderSummary(DefaultSummaryTemplate(), data)
+	require.NoError(t, err)
+	assert.Equal(t, `# old
+
+インポートパス: example.com/old
+バージョン: v1.1.0
+
+> **警告**: このモジュールは非推奨です: example.com/new を使用してください。
+
+> **警告**: バージョン v1.1.0 は撤回されています: データが破損する不具合があります（最新バージョン: v1.3.0）
+
+## ファイル一覧
+
+- old.go
+
+## 主要なファイル
+
+`, content)
+
+	data.Status = &ModuleStatus{ModulePath: "example.com/old", Version: "v1.3.0", LatestVersion: "v1.3.0"}
+	content, err = RenderSummary(DefaultSummaryTemplate(), data)
+	require.NoError(t, err)
+	assert.NotContains(t, content, "警告")
+}
@@ -1,20 +1,47 @@
 // Package types は go-pkg-summary で使用する型定義を提供します
 package internal
 
+import (
+	"text/template"
+	"time"
+)
+
 // Package はGoパッケージの情報を表す構造体です
 type Package struct {
 	// パッケージ名
-	Name string
+	Name string `json:"name"`
 	// インポートパス
-	ImportPath string
+	ImportPath string `json:"importPath"`
 	// バージョン
-	Version string
+	Version string `json:"version,omitempty"`
 	// 概要
-	Synopsis string
+	Synopsis string `json:"synopsis,omitempty"`
 	// ドキュメントURL
-	DocURL string
+	DocURL string `json:"docURL,omitempty"`
 	// リポジトリURL
-	RepoURL string
+	RepoURL string `json:"repoURL,omitempty"`
+	// ライセンス（SPDX 識別子、複数ある場合はカンマ区切り）
+	License string `json:"license,omitempty"`
+	// このパッケージをインポートしているパッケージ数
+	ImportedBy int `json:"importedBy"`
+	// 公開日
+	Published time.Time `json:"published,omitzero"`
+}
+
+// Symbol はシンボル検索で見つかったシンボルを表す構造体です
+type Symbol struct {
+	// シンボル名（メソッドの場合は Type.Method）
+	Name string `json:"name"`
+	// シンボルの種類（Function, Type, Method など）
+	Kind string `json:"kind"`
+	// シグネチャ
+	Signature string `json:"signature,omitempty"`
+	// 概要
+	Synopsis string `json:"synopsis,omitempty"`
+	// シンボルを含むパッケージのインポートパス
+	PackagePath string `json:"packagePath"`
+	// ドキュメントURL
+	DocURL string `json:"docURL,omitempty"`
 }
 
 // PackageFile はパッケージ内のファイル情報を表す構造体です
@@ -30,13 +57,55 @@ type PackageFile struct {
 // TypeInfo はGoの型情報を表す構造体です
 type TypeInfo struct {
 	// 型名
-	Name string
-	// 型の種類（struct, interface, func, const, var）
-	Kind string
+	Name string `json:"name"`
+	// 型の種類（struct, interface, constraint, func, method, const, var, type）
+	Kind string `json:"kind"`
 	// 型の定義
-	Definition string
+	Definition string `json:"definition"`
 	// コメント
-	Comment string
+	Comment string `json:"comment,omitempty"`
+	// レシーバーの型名（メソッドの場合）
+	Receiver string `json:"receiver,omitempty"`
+	// 引数と戻り値を含むシグネチャ（関数、メソッド、関数型の場合）
+	Signature string `json:"signature,omitempty"`
+	// 戻り値の型名（関数、メソッドの場合）
+	Results []string `json:"results,omitempty"`
+	// メソッドのシグネチャと埋め込まれた型（インターフェースの場合）
+	Methods []string `json:"methods,omitempty"`
+	// クイックリファレンスでの分類（TypeCategory* のいずれか）
+	Category string `json:"category,omitempty"`
+	// ドキュメントコメントに "Deprecated:" の段落があるかどうか
+	Deprecated bool `json:"deprecated,omitempty"`
+	// "Deprecated:" の段落の本文（代わりに使用する API の案内など。リンクを解決した場合は Markdown）
+	Replacement string `json:"replacement,omitempty"`
+	// ドキュメントコメントを Markdown に変換したもの（[Name] などのリンクを解決します）
+	Doc string `json:"doc,omitempty"`
+	// ドキュメントコメントの最初の行（"Deprecated:" の段落を除く）を Markdown に変換したもの
+	Summary string `json:"summary,omitempty"`
+	// サマリー内で宣言に付けるアンカーの ID（Doc の同じパッケージへのリンク先）
+	Anchor string `json:"anchor,omitempty"`
+	// 型パラメータと制約（ジェネリックな型と関数の場合）
+	TypeParams []TypeParam `json:"typeParams,omitempty"`
+	// 型のインスタンス化を示す Example 関数（ジェネリックな型と関数の場合）
+	Examples []ExampleRef `json:"examples,omitempty"`
+}
+
+// TypeParam は型パラメータとその制約を表す構造体です
+type TypeParam struct {
+	// 型パラメータ名
+	Name string `json:"name"`
+	// 制約（例: any、comparable、~int | ~string）
+	Constraint string `json:"constraint"`
+}
+
+// ExampleRef は宣言の使用例を示す Example 関数への参照を表す構造体です
+type ExampleRef struct {
+	// Example 関数名（例: ExampleMap_ints）
+	Name string `json:"name"`
+	// Example 関数内の明示的なインスタンス化（例: Map[int, string]。型引数を推論する場合は空）
+	Instantiation string `json:"instantiation,omitempty"`
+	// pkg.go.dev の Example への URL
+	URL string `json:"url,omitempty"`
 }
 
 // GetPackageOptions はパッケージ取得オプションを表す構造体です
@@ -45,10 +114,21 @@ type GetPackageOptions struct {
 	UseCache bool
 	// 出力ファイル
 	OutputFile string
-	// 含めるファイルパターン
+	// 含めるファイルパターン（指定した場合、ファイル一覧をいずれかに一致するファイルに絞り込む）
 	Include []string
+	// 除くファイルパターン（ファイル一覧からいずれかに一致するファイルを除く）
+	Exclude []string
 	// ドライラン（実際に取得せずに情報のみ表示）
 	DryRun bool
+	// 指定した場合、既知の脆弱性を照合して Security セクションを追加する
+	VulnDB *VulnDB
+	// 指定した場合、README の見出しがいずれかを含むセクションのみを残す
+	ReadmeSections []string
+	// サマリーのテンプレート（nil の場合は既定のテンプレート）
+	Template *template.Template
+	// テンプレートに渡す公開API（.Types）から非推奨の宣言を除くかどうか
+	// 既定のテンプレートは宣言を出力しないため、Template を指定した場合のみ出力が変わります
+	HideDeprecated bool
 }
 
 // DEFAULT_INCLUDE_PATTERNS はデフォルトで含めるファイルパターンです
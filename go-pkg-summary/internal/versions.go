@@ -0,0 +1,450 @@
+// Package versions はモジュールの公開バージョン一覧の取得機能を提供します
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// DefaultProxyURL はデフォルトのモジュールプロキシのURLです
+	DefaultProxyURL = "https://proxy.golang.org"
+
+	// VersionSourceProxy はモジュールプロキシから取得したことを表します
+	VersionSourceProxy = "proxy"
+	// VersionSourcePkgGoDev は pkg.go.dev のバージョンタブから取得したことを表します
+	VersionSourcePkgGoDev = "pkg.go.dev"
+	// VersionSourceGit は非公開モジュールのリポジトリのタグから取得したことを表します
+	VersionSourceGit = "git"
+
+	// maxMajorVersionProbe はメジャーバージョンの兄弟モジュールを探索する上限です
+	maxMajorVersionProbe = 20
+	// versionInfoConcurrency は .info エンドポイントへの同時リクエスト数です
+	versionInfoConcurrency = 8
+)
+
+// errProxyNotFound はモジュールプロキシに対象が存在しないことを表すエラーです
+var errProxyNotFound = errors.New("モジュールプロキシに見つかりません")
+
+// ModuleVersion はモジュールの公開バージョンを表す構造体です
+type ModuleVersion struct {
+	// バージョン（例: v1.2.3）
+	Version string `json:"version"`
+	// 公開日時（取得できない場合はゼロ値）
+	Time time.Time `json:"time,omitzero"`
+	// プレリリースかどうか
+	PreRelease bool `json:"preRelease,omitempty"`
+	// 疑似バージョン（タグのないコミット）かどうか
+	Pseudo bool `json:"pseudo,omitempty"`
+	// 撤回（retract）されているかどうか
+	Retracted bool `json:"retracted,omitempty"`
+	// 撤回の理由
+	RetractReason string `json:"retractReason,omitempty"`
+}
+
+// ModuleVersions はモジュールのバージョン一覧を表す構造体です
+type ModuleVersions struct {
+	// モジュールパス
+	ModulePath string `json:"modulePath"`
+	// バージョン一覧（新しい順）
+	Versions []ModuleVersion `json:"versions"`
+	// メジャーバージョンの兄弟モジュール（例: example.com/mod/v2）
+	MajorSiblings []string `json:"majorSiblings,omitempty"`
+	// 取得元（proxy または pkg.go.dev）
+	Source string `json:"source"`
+}
+
+// proxyVersionInfo はモジュールプロキシの .info エンドポイントのレスポンスです
+type proxyVersionInfo struct {
+	Version string
+	Time    time.Time
+}
+
+// ListVersions はパッケージを含むモジュールの公開バージョン一覧を取得します
+// モジュールプロキシの @v/list と .info を使用し、失敗した場合は pkg.go.dev のバージョンタブにフォールバックします
+// 非公開モジュールの場合は git のタグから取得します
+func (f *Fetcher) ListVersions(importPath string) (*ModuleVersions, error) {
+	if f.IsPrivateModule(importPath) {
+		return f.listPrivateVersions(importPath)
+	}
+
+	result, err := f.listProxyVersions(importPath)
+	if err == nil {
+		return result, nil
+	}
+
+	if f.debug {
+		fmt.Printf("モジュールプロキシからのバージョン取得に失敗しました: %v\n", err)
+	}
+
+	// pkg.go.dev のバージョンタブにフォールバック
+	modulePath, versions, scrapeErr := f.scraper.GetVersions(importPath)
+	if scrapeErr != nil {
+		return nil, fmt.Errorf("バージョン一覧の取得に失敗しました: %w", errors.Join(err, scrapeErr))
+	}
+
+	return &ModuleVersions{
+		ModulePath:    modulePath,
+		Versions:      versions,
+		MajorSiblings: f.findMajorSiblings(modulePath),
+		Source:        VersionSourcePkgGoDev,
+	}, nil
+}
+
+// ResolveModulePath はインポートパスを含むモジュールのパスをモジュールプロキシで解決します
+// 非公開モジュールの場合はリポジトリのルートをモジュールとし、バージョンは git のタグから求めます
+func (f *Fetcher) ResolveModulePath(importPath string) (string, []string, error) {
+	if f.IsPrivateModule(importPath) {
+		return f.resolvePrivateModulePath(importPath)
+	}
+
+	// 長いパスから順にモジュールとして存在するかを確認する
+	candidate := importPath
+	for {
+		versions, err := f.proxyVersionList(candidate)
+		if err == nil && len(versions) > 0 {
+			return candidate, versions, nil
+		}
+		if err != nil && !errors.Is(err, errProxyNotFound) {
+			return "", nil, err
+		}
+
+		slashIndex := strings.LastIndex(candidate, "/")
+		if slashIndex == -1 {
+			break
+		}
+		candidate = candidate[:slashIndex]
+	}
+
+	return "", nil, fmt.Errorf("%s を含むモジュールが見つかりません", importPath)
+}
+
+// listProxyVersions はモジュールプロキシからバージョン一覧を取得します
+func (f *Fetcher) listProxyVersions(importPath string) (*ModuleVersions, error) {
+	modulePath, rawVersions, err := f.ResolveModulePath(importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := f.fetchVersionInfos(modulePath, rawVersions)
+
+	// 最新バージョンの go.mod から retract ディレクティブを反映
+	if latest := LatestVersion(rawVersions); latest != "" {
+		retracts, err := f.fetchRetractions(modulePath, latest)
+		if err != nil && f.debug {
+			fmt.Printf("retract 情報の取得に失敗しました: %v\n", err)
+		}
+		applyRetractions(versions, retracts)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return semver.Compare(versions[i].Version, versions[j].Version) > 0
+	})
+
+	return &ModuleVersions{
+		ModulePath:    modulePath,
+		Versions:      versions,
+		MajorSiblings: f.findMajorSiblings(modulePath),
+		Source:        VersionSourceProxy,
+	}, nil
+}
+
+// fetchVersionInfos は各バージョンの .info を並行して取得します
+func (f *Fetcher) fetchVersionInfos(modulePath string, rawVersions []string) []ModuleVersion {
+	versions := make([]ModuleVersion, len(rawVersions))
+	sem := make(chan struct{}, versionInfoConcurrency)
+	var wg sync.WaitGroup
+
+	for i, v := range rawVersions {
+		versions[i] = ModuleVersion{
+			Version:    v,
+			Pseudo:     module.IsPseudoVersion(v),
+			PreRelease: semver.Prerelease(v) != "" && !module.IsPseudoVersion(v),
+		}
+
+		wg.Add(1)
+		go func(i int, v string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			info, err := f.proxyVersionInfo(modulePath, v)
+			if err != nil {
+				if f.debug {
+					fmt.Printf("%s@%s の情報取得に失敗しました: %v\n", modulePath, v, err)
+				}
+				return
+			}
+			versions[i].Time = info.Time
+		}(i, v)
+	}
+	wg.Wait()
+
+	return versions
+}
+
+// fetchRetractions は指定バージョンの go.mod から retract ディレクティブを取得します
+func (f *Fetcher) fetchRetractions(modulePath string, version string) ([]*modfile.Retract, error) {
+	data, err := f.proxyGoMod(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	mf, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("go.mod のパースに失敗しました: %w", err)
+	}
+
+	return mf.Retract, nil
+}
+
+// ModuleStatus はモジュールの非推奨と、指定したバージョンの撤回の状態を表す構造体です
+// いずれも最新バージョンの go.mod の module ディレクティブの "// Deprecated:" コメントと retract ディレクティブから求めます
+type ModuleStatus struct {
+	// モジュールパス
+	ModulePath string `json:"modulePath"`
+	// 状態を確認したバージョン
+	Version string `json:"version"`
+	// 最新バージョン
+	LatestVersion string `json:"latestVersion"`
+	// モジュールの非推奨のメッセージ（非推奨でない場合は空）
+	Deprecated string `json:"deprecated,omitempty"`
+	// 撤回（retract）されているかどうか
+	Retracted bool `json:"retracted,omitempty"`
+	// 撤回の理由
+	RetractReason string `json:"retractReason,omitempty"`
+}
+
+// GetModuleStatus はパッケージを含むモジュールが非推奨か、指定したバージョンが撤回されているかを確認します
+// 非公開モジュールの場合は git で取得した最新バージョンの go.mod を使用します
+func (f *Fetcher) GetModuleStatus(importPath string, version string) (*ModuleStatus, error) {
+	modulePath, versions, err := f.ResolveModulePath(importPath)
+	if err != nil {
+		return nil, err
+	}
+	latest := LatestVersion(versions)
+	if latest == "" {
+		return nil, fmt.Errorf("%s の公開バージョンが見つかりません", modulePath)
+	}
+	if version == "" || version == "latest" {
+		version = latest
+	}
+
+	data, err := f.moduleGoMod(modulePath, latest)
+	if err != nil {
+		return nil, err
+	}
+	mf, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("go.mod のパースに失敗しました: %w", err)
+	}
+
+	status := &ModuleStatus{ModulePath: modulePath, Version: version, LatestVersion: latest}
+	if mf.Module != nil {
+		status.Deprecated = mf.Module.Deprecated
+	}
+	checked := []ModuleVersion{{Version: version}}
+	applyRetractions(checked, mf.Retract)
+	status.Retracted = checked[0].Retracted
+	status.RetractReason = checked[0].RetractReason
+	return status, nil
+}
+
+// moduleGoMod は指定バージョンの go.mod を取得します（非公開モジュールの場合は git で取得したソースコードから読み込みます）
+func (f *Fetcher) moduleGoMod(modulePath string, version string) ([]byte, error) {
+	if !f.IsPrivateModule(modulePath) {
+		return f.proxyGoMod(modulePath, version)
+	}
+	source, err := f.DownloadModule(modulePath, version, true)
+	if err != nil {
+		return nil, err
+	}
+	content, err := source.ReadFile("go.mod")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// applyRetractions は retract ディレクティブに該当するバージョンに撤回フラグを設定します
+func applyRetractions(versions []ModuleVersion, retracts []*modfile.Retract) {
+	for i := range versions {
+		for _, r := range retracts {
+			if semver.Compare(versions[i].Version, r.Low) >= 0 && semver.Compare(versions[i].Version, r.High) <= 0 {
+				versions[i].Retracted = true
+				versions[i].RetractReason = r.Rationale
+				break
+			}
+		}
+	}
+}
+
+// findMajorSiblings はメジャーバージョンが異なる兄弟モジュールを探索します
+func (f *Fetcher) findMajorSiblings(modulePath string) []string {
+	// gopkg.in はパス自体にバージョンを含むため対象外
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		return nil
+	}
+
+	prefix, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		return nil
+	}
+
+	var siblings []string
+
+	// 自身が /vN の場合はベースのモジュールも兄弟に含める
+	if pathMajor != "" {
+		if versions, err := f.proxyVersionList(prefix); err == nil && len(versions) > 0 {
+			siblings = append(siblings, prefix)
+		}
+	}
+
+	// /v2 から順に、存在しないメジャーバージョンに当たるまで探索する
+	for n := 2; n <= maxMajorVersionProbe; n++ {
+		candidate := fmt.Sprintf("%s/v%d", prefix, n)
+		if candidate == modulePath {
+			continue
+		}
+		versions, err := f.proxyVersionList(candidate)
+		if err != nil || len(versions) == 0 {
+			break
+		}
+		siblings = append(siblings, candidate)
+	}
+
+	return siblings
+}
+
+// LatestVersion はバージョン一覧から最新のリリースバージョンを返します
+// リリースバージョンが存在しない場合は最新のプレリリースバージョンを返します
+func LatestVersion(versions []string) string {
+	latest := ""
+	latestPre := ""
+	for _, v := range versions {
+		if !semver.IsValid(v) {
+			continue
+		}
+		if semver.Prerelease(v) == "" {
+			if latest == "" || semver.Compare(v, latest) > 0 {
+				latest = v
+			}
+		} else if latestPre == "" || semver.Compare(v, latestPre) > 0 {
+			latestPre = v
+		}
+	}
+	if latest != "" {
+		return latest
+	}
+	return latestPre
+}
+
+// proxyVersionList はモジュールプロキシの @v/list からバージョン一覧を取得します
+func (f *Fetcher) proxyVersionList(modulePath string) ([]string, error) {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errProxyNotFound, err)
+	}
+
+	body, err := f.proxyGet(fmt.Sprintf("%s/%s/@v/list", f.proxyURL, escapedPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, line := range strings.Split(string(body), "\n") {
+		if v := strings.TrimSpace(line); v != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+// proxyVersionInfo はモジュールプロキシの .info エンドポイントからバージョン情報を取得します
+func (f *Fetcher) proxyVersionInfo(modulePath string, version string) (*proxyVersionInfo, error) {
+	escapedPath, escapedVersion, err := escapeModuleVersion(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := f.proxyGet(fmt.Sprintf("%s/%s/@v/%s.info", f.proxyURL, escapedPath, escapedVersion))
+	if err != nil {
+		return nil, err
+	}
+
+	var info proxyVersionInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("JSONのパースに失敗しました: %w", err)
+	}
+	return &info, nil
+}
+
+// proxyGoMod はモジュールプロキシから指定バージョンの go.mod を取得します
+func (f *Fetcher) proxyGoMod(modulePath string, version string) ([]byte, error) {
+	escapedPath, escapedVersion, err := escapeModuleVersion(modulePath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.proxyGet(fmt.Sprintf("%s/%s/@v/%s.mod", f.proxyURL, escapedPath, escapedVersion))
+}
+
+// escapeModuleVersion はモジュールパスとバージョンをプロキシ用にエスケープします
+func escapeModuleVersion(modulePath string, version string) (string, string, error) {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", "", fmt.Errorf("無効なモジュールパスです: %w", err)
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", "", fmt.Errorf("無効なバージョンです: %w", err)
+	}
+	return escapedPath, escapedVersion, nil
+}
+
+// proxyGet はモジュールプロキシにGETリクエストを送信してレスポンスボディを返します
+func (f *Fetcher) proxyGet(proxyURL string) ([]byte, error) {
+	if f.debug {
+		fmt.Printf("プロキシ URL: %s\n", proxyURL)
+	}
+
+	// HTTPリクエストを作成
+	req, err := http.NewRequest("GET", proxyURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+	}
+
+	// リクエストを実行
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("プロキシへのリクエストに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 404/410 は存在しないモジュール、403 は利用できないモジュールとして扱う
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone, http.StatusForbidden:
+		return nil, fmt.Errorf("%w: %s", errProxyNotFound, proxyURL)
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("プロキシへのリクエストに失敗しました: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("レスポンスの読み取りに失敗しました: %w", err)
+	}
+	return body, nil
+}
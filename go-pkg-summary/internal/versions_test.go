@@ -0,0 +1,277 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+)
+
+// newVersionsProxy は @v/list の内容をモジュールパスごとに返すモジュールプロキシを起動します
+// その他のエンドポイントは routes で指定し、どちらにもないパスは 404 を返します
+func newVersionsProxy(t *testing.T, lists map[string]string, routes map[string]string) string {
+	t.Helper()
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path[1:]
+		if body, ok := routes[path]; ok {
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		modulePath, ok := strings.CutSuffix(path, "/@v/list")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		list, ok := lists[modulePath]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(list))
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy.URL
+}
+
+func TestGetModuleStatus(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/old/@v/list":
+			_, _ = w.Write([]byte("v1.0.0\nv1.1.0\nv1.2.0\nv1.3.0\n"))
+		case "/example.com/old/@v/v1.3.0.mod":
+			_, _ = w.Write([]byte(`// Deprecated: example.com/new を使用してください。
+module example.com/old
+
+go 1.22
+
+retract (
+	v1.1.0 // データが破損する不具合があります
+	[v1.2.0, v1.2.9]
+)
+`))
+		case "/example.com/current/@v/list":
+			_, _ = w.Write([]byte("v0.1.0\n"))
+		case "/example.com/current/@v/v0.1.0.mod":
+			_, _ = w.Write([]byte("module example.com/current\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(proxy.Close)
+
+	f, err := NewFetcher(false, WithBaseURLs(BaseURLs{Proxy: proxy.URL}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		importPath string
+		version    string
+		expected   ModuleStatus
+	}{
+		{
+			importPath: "example.com/old/sub",
+			version:    "v1.1.0",
+			expected: ModuleStatus{
+				ModulePath:    "example.com/old",
+				Version:       "v1.1.0",
+				LatestVersion: "v1.3.0",
+				Deprecated:    "example.com/new を使用してください。",
+				Retracted:     true,
+				RetractReason: "データが破損する不具合があります",
+			},
+		},
+		{
+			importPath: "example.com/old",
+			version:    "v1.2.0",
+			expected: ModuleStatus{
+				ModulePath:    "example.com/old",
+				Version:       "v1.2.0",
+				LatestVersion: "v1.3.0",
+				Deprecated:    "example.com/new を使用してください。",
+				Retracted:     true,
+			},
+		},
+		{
+			importPath: "example.com/old",
+			version:    "latest",
+			expected: ModuleStatus{
+				ModulePath:    "example.com/old",
+				Version:       "v1.3.0",
+				LatestVersion: "v1.3.0",
+				Deprecated:    "example.com/new を使用してください。",
+			},
+		},
+		{
+			importPath: "example.com/current",
+			version:    "v0.1.0",
+			expected:   ModuleStatus{ModulePath: "example.com/current", Version: "v0.1.0", LatestVersion: "v0.1.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importPath+"@"+tt.version, func(t *testing.T) {
+			status, err := f.GetModuleStatus(tt.importPath, tt.version)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, *status)
+		})
+	}
+
+	_, err = f.GetModuleStatus("example.com/missing", "latest")
+	assert.Error(t, err)
+}
+
+func TestListVersions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	proxyURL := newVersionsProxy(t, map[string]string{
+		"example.com/lib":    "v1.0.0\nv1.1.0-rc.1\nv1.1.0\nv0.0.0-20240101000000-abcdefabcdef\n",
+		"example.com/lib/v2": "v2.0.0\n",
+	}, map[string]string{
+		"example.com/lib/@v/v1.0.0.info": `{"Version":"v1.0.0","Time":"2024-01-02T03:04:05Z"}`,
+		"example.com/lib/@v/v1.1.0.mod":  "module example.com/lib\n\nretract v1.0.0 // 不具合があります\n",
+	})
+	f, err := NewFetcher(false, WithBaseURLs(BaseURLs{Proxy: proxyURL}))
+	require.NoError(t, err)
+
+	versions, err := f.ListVersions("example.com/lib/sub")
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com/lib", versions.ModulePath, "パッケージを含むモジュールのパスになること")
+	assert.Equal(t, VersionSourceProxy, versions.Source)
+	assert.Equal(t, []string{"example.com/lib/v2"}, versions.MajorSiblings)
+	assert.Equal(t, []ModuleVersion{
+		{Version: "v1.1.0"},
+		{Version: "v1.1.0-rc.1", PreRelease: true},
+		{Version: "v1.0.0", Time: time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC), Retracted: true, RetractReason: "不具合があります"},
+		{Version: "v0.0.0-20240101000000-abcdefabcdef", Pseudo: true},
+	}, versions.Versions, "新しい順に並び、公開日時と撤回が反映されること")
+}
+
+func TestListVersionsPkgGoDevFallback(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// モジュールプロキシが利用できない場合は pkg.go.dev のバージョンタブから取得する
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/stretchr/testify/v2/@v/list":
+			_, _ = w.Write([]byte("v2.0.0\n"))
+		case "/github.com/stretchr/testify/v3/@v/list":
+			http.NotFound(w, r)
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(proxy.Close)
+	s := newFixtureScraper(t, map[string]string{
+		"/github.com/stretchr/testify/assert?tab=versions": "testify-assert-versions.html",
+	})
+	f, err := NewFetcher(false, WithBaseURLs(BaseURLs{Proxy: proxy.URL, PkgGoDev: s.baseURL}))
+	require.NoError(t, err)
+
+	versions, err := f.ListVersions("github.com/stretchr/testify/assert")
+	require.NoError(t, err)
+
+	assert.Equal(t, "github.com/stretchr/testify", versions.ModulePath, "インポートパスではなくモジュールのパスになること")
+	assert.Equal(t, VersionSourcePkgGoDev, versions.Source)
+	assert.Equal(t, []string{"github.com/stretchr/testify/v2"}, versions.MajorSiblings, "フォールバックした場合もメジャーバージョンの兄弟モジュールを探索すること")
+	require.Len(t, versions.Versions, 4)
+	assert.Equal(t, "v1.10.0", versions.Versions[0].Version)
+}
+
+func TestResolveModulePath(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	proxyURL := newVersionsProxy(t, map[string]string{
+		"example.com/lib":         "v1.0.0\n",
+		"example.com/lib/nested":  "v0.1.0\nv0.2.0\n",
+		"example.com/empty/inner": "",
+	}, nil)
+	f, err := NewFetcher(false, WithBaseURLs(BaseURLs{Proxy: proxyURL}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		importPath string
+		modulePath string
+		versions   []string
+	}{
+		{importPath: "example.com/lib", modulePath: "example.com/lib", versions: []string{"v1.0.0"}},
+		{importPath: "example.com/lib/a/b", modulePath: "example.com/lib", versions: []string{"v1.0.0"}},
+		{importPath: "example.com/lib/nested/pkg", modulePath: "example.com/lib/nested", versions: []string{"v0.1.0", "v0.2.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			modulePath, versions, err := f.ResolveModulePath(tt.importPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.modulePath, modulePath, "最も長いパスのモジュールを選ぶこと")
+			assert.Equal(t, tt.versions, versions)
+		})
+	}
+
+	_, _, err = f.ResolveModulePath("example.com/empty/inner/pkg")
+	assert.Error(t, err, "バージョンのないモジュールしかない場合はエラーになること")
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(failing.Close)
+	f, err = NewFetcher(false, WithBaseURLs(BaseURLs{Proxy: failing.URL}))
+	require.NoError(t, err)
+	_, _, err = f.ResolveModulePath("example.com/lib/sub")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, errProxyNotFound, "プロキシの障害は見つからないエラーと区別すること")
+}
+
+func TestApplyRetractions(t *testing.T) {
+	retracts := []*modfile.Retract{
+		{VersionInterval: modfile.VersionInterval{Low: "v1.1.0", High: "v1.1.0"}, Rationale: "データが破損します"},
+		{VersionInterval: modfile.VersionInterval{Low: "v1.2.0", High: "v1.2.9"}},
+	}
+	versions := []ModuleVersion{
+		{Version: "v1.0.0"},
+		{Version: "v1.1.0"},
+		{Version: "v1.2.0"},
+		{Version: "v1.2.5"},
+		{Version: "v1.2.9"},
+		{Version: "v1.3.0"},
+	}
+
+	applyRetractions(versions, retracts)
+
+	assert.Equal(t, []ModuleVersion{
+		{Version: "v1.0.0"},
+		{Version: "v1.1.0", Retracted: true, RetractReason: "データが破損します"},
+		{Version: "v1.2.0", Retracted: true},
+		{Version: "v1.2.5", Retracted: true},
+		{Version: "v1.2.9", Retracted: true},
+		{Version: "v1.3.0"},
+	}, versions, "範囲の両端を含めて撤回されること")
+}
+
+func TestFindMajorSiblings(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	proxyURL := newVersionsProxy(t, map[string]string{
+		"example.com/lib":    "v1.0.0\n",
+		"example.com/lib/v2": "v2.0.0\n",
+		"example.com/lib/v3": "v3.0.0\n",
+		"example.com/lib/v5": "v5.0.0\n",
+		"example.com/single": "v1.0.0\n",
+		"gopkg.in/yaml.v3":   "v3.0.1\n",
+	}, nil)
+	f, err := NewFetcher(false, WithBaseURLs(BaseURLs{Proxy: proxyURL}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"example.com/lib/v2", "example.com/lib/v3"}, f.findMajorSiblings("example.com/lib"),
+		"存在しないメジャーバージョンに当たった時点で探索を終えること")
+	assert.Equal(t, []string{"example.com/lib", "example.com/lib/v3"}, f.findMajorSiblings("example.com/lib/v2"),
+		"/vN のモジュールではベースのモジュールも含め、自身は含めないこと")
+	assert.Empty(t, f.findMajorSiblings("example.com/single"))
+	assert.Empty(t, f.findMajorSiblings("gopkg.in/yaml.v3"), "gopkg.in は対象外であること")
+}
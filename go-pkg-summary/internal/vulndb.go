@@ -0,0 +1,485 @@
+// Package vulndb は vuln.go.dev 形式の脆弱性データベース（OSV）をローカルのスナップショットから照会する機能を提供します
+package internal
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// vulnDBIndexFile はデータベースの更新日時を記録したファイルです
+	vulnDBIndexFile = "index/db.json"
+	// vulnDBModulesFile はモジュールごとの脆弱性IDの一覧を記録したファイルです
+	vulnDBModulesFile = "index/modules.json"
+	// vulnDBEntryDir は OSV エントリを格納したディレクトリです
+	vulnDBEntryDir = "ID"
+	// StdlibVulnModule は OSV で標準ライブラリの脆弱性を登録しているモジュール名です
+	StdlibVulnModule = "stdlib"
+)
+
+// osvEntry は OSV 形式の脆弱性エントリです（go-pkg-summary で使用するフィールドのみ）
+type osvEntry struct {
+	ID               string        `json:"id"`
+	Summary          string        `json:"summary"`
+	Aliases          []string      `json:"aliases"`
+	Withdrawn        *time.Time    `json:"withdrawn,omitempty"`
+	Affected         []osvAffected `json:"affected"`
+	DatabaseSpecific struct {
+		URL string `json:"url"`
+	} `json:"database_specific"`
+}
+
+// osvAffected は脆弱性の影響を受けるモジュールとバージョン範囲です
+type osvAffected struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Ranges            []osvRange `json:"ranges"`
+	EcosystemSpecific struct {
+		Imports []struct {
+			Path    string   `json:"path"`
+			Symbols []string `json:"symbols"`
+		} `json:"imports"`
+	} `json:"ecosystem_specific"`
+}
+
+// osvRange はバージョン範囲です（バージョンは "v" を含まない SemVer）
+type osvRange struct {
+	Type   string `json:"type"`
+	Events []struct {
+		Introduced string `json:"introduced,omitempty"`
+		Fixed      string `json:"fixed,omitempty"`
+	} `json:"events"`
+}
+
+// vulnDBModule は index/modules.json の1つのモジュールです
+type vulnDBModule struct {
+	Path  string `json:"path"`
+	Vulns []struct {
+		ID string `json:"id"`
+	} `json:"vulns"`
+}
+
+// AffectedPackage は脆弱性の影響を受けるパッケージとシンボルを表す構造体です
+type AffectedPackage struct {
+	// インポートパス
+	Path string `json:"path"`
+	// 影響を受けるシンボル（空の場合はパッケージ全体）
+	Symbols []string `json:"symbols,omitempty"`
+}
+
+// Vulnerability はモジュールのバージョンに影響する既知の脆弱性を表す構造体です
+type Vulnerability struct {
+	// 脆弱性ID（例: GO-2022-0001）
+	ID string `json:"id"`
+	// CVE や GHSA などの別名
+	Aliases []string `json:"aliases,omitempty"`
+	// 概要
+	Summary string `json:"summary,omitempty"`
+	// 修正されたバージョン（修正されていない場合は空文字列）
+	Fixed string `json:"fixed,omitempty"`
+	// 詳細のURL
+	URL string `json:"url,omitempty"`
+	// 影響を受けるパッケージ
+	Packages []AffectedPackage `json:"packages,omitempty"`
+}
+
+// VulnDB はローカルの脆弱性データベースを表す構造体です
+type VulnDB struct {
+	// データベースのパス
+	path string
+	// データベースのファイルシステム
+	fsys fs.FS
+	// zip の場合のリーダー
+	closer io.Closer
+
+	// index/modules.json の読み込み結果
+	modulesOnce sync.Once
+	modules     map[string][]string
+	modulesErr  error
+}
+
+// OpenVulnDB は vuln.go.dev 形式の脆弱性データベースをディレクトリ、または zip から開きます
+func OpenVulnDB(dbPath string) (*VulnDB, error) {
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("脆弱性データベースを開けません: %w", err)
+	}
+
+	if info.IsDir() {
+		return &VulnDB{path: dbPath, fsys: os.DirFS(dbPath)}, nil
+	}
+
+	reader, err := zip.OpenReader(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("脆弱性データベースの zip を開けません: %w", err)
+	}
+	db := &VulnDB{path: dbPath, fsys: reader, closer: reader}
+
+	// zip の中に1つのディレクトリがあり、その下にデータベースがある場合はそのディレクトリをルートとする
+	if _, err := fs.Stat(reader, vulnDBEntryDir); err != nil {
+		entries, _ := fs.ReadDir(reader, ".")
+		if len(entries) == 1 && entries[0].IsDir() {
+			sub, err := fs.Sub(reader, entries[0].Name())
+			if err == nil {
+				db.fsys = sub
+			}
+		}
+	}
+	return db, nil
+}
+
+// Close はデータベースを閉じます
+func (db *VulnDB) Close() error {
+	if db.closer != nil {
+		return db.closer.Close()
+	}
+	return nil
+}
+
+// Path はデータベースのパスを返します
+func (db *VulnDB) Path() string {
+	return db.path
+}
+
+// Modified はデータベースの更新日時を返します（記録されていない場合はゼロ値）
+func (db *VulnDB) Modified() time.Time {
+	data, err := fs.ReadFile(db.fsys, vulnDBIndexFile)
+	if err != nil {
+		return time.Time{}
+	}
+	var index struct {
+		Modified time.Time `json:"modified"`
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return time.Time{}
+	}
+	return index.Modified
+}
+
+// ModuleFor はインポートパスを含むモジュールのうち、データベースに登録されているものを返します
+func (db *VulnDB) ModuleFor(importPath string) (string, error) {
+	modules, err := db.loadModules()
+	if err != nil {
+		return "", err
+	}
+
+	best := ""
+	for modulePath := range modules {
+		if (importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")) && len(modulePath) > len(best) {
+			best = modulePath
+		}
+	}
+	return best, nil
+}
+
+// Query はモジュールのバージョンに影響する脆弱性を ID 順で返します
+// 取り下げられたエントリは対象外です
+func (db *VulnDB) Query(modulePath string, version string) ([]Vulnerability, error) {
+	if !semver.IsValid(version) {
+		return nil, fmt.Errorf("脆弱性の照合には有効なバージョンが必要です: %s", version)
+	}
+
+	modules, err := db.loadModules()
+	if err != nil {
+		return nil, err
+	}
+
+	var vulns []Vulnerability
+	for _, id := range modules[modulePath] {
+		entry, err := db.readEntry(id)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Withdrawn != nil {
+			continue
+		}
+
+		for _, affected := range entry.Affected {
+			if affected.Package.Name != modulePath || !affectsVersion(affected.Ranges, version) {
+				continue
+			}
+
+			vuln := Vulnerability{
+				ID:      entry.ID,
+				Aliases: entry.Aliases,
+				Summary: entry.Summary,
+				Fixed:   fixedVersion(affected.Ranges, version),
+				URL:     entry.DatabaseSpecific.URL,
+			}
+			for _, imp := range affected.EcosystemSpecific.Imports {
+				vuln.Packages = append(vuln.Packages, AffectedPackage{Path: imp.Path, Symbols: imp.Symbols})
+			}
+			vulns = append(vulns, vuln)
+			break
+		}
+	}
+
+	sort.Slice(vulns, func(i, j int) bool {
+		return vulns[i].ID < vulns[j].ID
+	})
+	return vulns, nil
+}
+
+// QueryStdlib は標準ライブラリのパッケージに影響する脆弱性を ID 順で返します
+// 標準ライブラリの脆弱性はモジュール stdlib に登録されているため、Go のバージョン（例: go1.22.0）を SemVer に変換して照合し、
+// 影響を受けるパッケージに importPath を含むものに絞り込みます
+func (db *VulnDB) QueryStdlib(importPath string, goVersion string) ([]Vulnerability, error) {
+	version := stdlibSemver(goVersion)
+	if !semver.IsValid(version) {
+		return nil, fmt.Errorf("脆弱性の照合には有効な Go のバージョンが必要です: %s", goVersion)
+	}
+
+	vulns, err := db.Query(StdlibVulnModule, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []Vulnerability
+	for _, vuln := range vulns {
+		for _, pkg := range vuln.Packages {
+			if pkg.Path == importPath {
+				vuln.Packages = []AffectedPackage{pkg}
+				matched = append(matched, vuln)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// stdlibSemver は Go のバージョンを SemVer に変換します
+// "go1.22.0" は "v1.22.0"、"go1.22" は "v1.22.0"、"go1.22rc1" は "v1.22.0-rc.1" になります
+func stdlibSemver(goVersion string) string {
+	version := strings.TrimPrefix(goVersion, "go")
+	prerelease := ""
+	for _, tag := range []string{"rc", "beta"} {
+		if i := strings.Index(version, tag); i >= 0 {
+			prerelease = "-" + tag + "." + version[i+len(tag):]
+			version = version[:i]
+			break
+		}
+	}
+	if strings.Count(version, ".") == 1 {
+		version += ".0"
+	}
+	return "v" + version + prerelease
+}
+
+// loadModules は index/modules.json を読み込み、モジュールパスから脆弱性IDへの対応を作成します
+// インデックスがない場合は ID ディレクトリの全エントリから作成します
+func (db *VulnDB) loadModules() (map[string][]string, error) {
+	db.modulesOnce.Do(func() {
+		data, err := fs.ReadFile(db.fsys, vulnDBModulesFile)
+		if errors.Is(err, fs.ErrNotExist) {
+			db.modules, db.modulesErr = db.scanEntries()
+			return
+		}
+		if err != nil {
+			db.modulesErr = fmt.Errorf("脆弱性データベースのインデックスの読み込みに失敗しました: %w", err)
+			return
+		}
+
+		var modules []vulnDBModule
+		if err := json.Unmarshal(data, &modules); err != nil {
+			db.modulesErr = fmt.Errorf("脆弱性データベースのインデックスのパースに失敗しました: %w", err)
+			return
+		}
+
+		db.modules = make(map[string][]string, len(modules))
+		for _, m := range modules {
+			for _, v := range m.Vulns {
+				db.modules[m.Path] = append(db.modules[m.Path], v.ID)
+			}
+		}
+	})
+	return db.modules, db.modulesErr
+}
+
+// scanEntries は ID ディレクトリの全エントリを読み込み、モジュールパスから脆弱性IDへの対応を作成します
+func (db *VulnDB) scanEntries() (map[string][]string, error) {
+	entries, err := fs.ReadDir(db.fsys, vulnDBEntryDir)
+	if err != nil {
+		return nil, fmt.Errorf("脆弱性データベースのエントリ一覧の取得に失敗しました: %w", err)
+	}
+
+	modules := map[string][]string{}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		entry, err := db.readEntry(id)
+		if err != nil {
+			return nil, err
+		}
+
+		seen := map[string]bool{}
+		for _, affected := range entry.Affected {
+			if name := affected.Package.Name; !seen[name] {
+				seen[name] = true
+				modules[name] = append(modules[name], id)
+			}
+		}
+	}
+	return modules, nil
+}
+
+// readEntry は ID/<id>.json の OSV エントリを読み込みます
+func (db *VulnDB) readEntry(id string) (*osvEntry, error) {
+	data, err := fs.ReadFile(db.fsys, path.Join(vulnDBEntryDir, id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("脆弱性 %s の読み込みに失敗しました: %w", id, err)
+	}
+
+	var entry osvEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("脆弱性 %s のパースに失敗しました: %w", id, err)
+	}
+	return &entry, nil
+}
+
+// affectsVersion はバージョンが SEMVER の範囲のいずれかに含まれるかを判定します
+// 範囲が指定されていない場合は全てのバージョンが影響を受けるとみなします
+func affectsVersion(ranges []osvRange, version string) bool {
+	semverRanges := 0
+	for _, r := range ranges {
+		if r.Type != "SEMVER" {
+			continue
+		}
+		semverRanges++
+		if rangeContains(r, version) {
+			return true
+		}
+	}
+	return semverRanges == 0
+}
+
+// rangeContains はバージョンが範囲に含まれるかを判定します
+// イベントをバージョン順に並べ、バージョン以下で最後のイベントが introduced であれば影響を受けるとみなします
+func rangeContains(r osvRange, version string) bool {
+	type event struct {
+		version    string
+		introduced bool
+	}
+	events := make([]event, 0, len(r.Events))
+	for _, e := range r.Events {
+		if e.Introduced != "" {
+			events = append(events, event{version: osvSemver(e.Introduced), introduced: true})
+		} else if e.Fixed != "" {
+			events = append(events, event{version: osvSemver(e.Fixed)})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return compareOSVSemver(events[i].version, events[j].version) < 0
+	})
+
+	affected := false
+	for _, e := range events {
+		if e.version != "" && semver.Compare(version, e.version) < 0 {
+			break
+		}
+		affected = e.introduced
+	}
+	return affected
+}
+
+// fixedVersion はバージョンより新しい最小の修正バージョンを返します
+func fixedVersion(ranges []osvRange, version string) string {
+	fixed := ""
+	for _, r := range ranges {
+		for _, e := range r.Events {
+			if e.Fixed == "" {
+				continue
+			}
+			v := osvSemver(e.Fixed)
+			if semver.Compare(v, version) > 0 && (fixed == "" || semver.Compare(v, fixed) < 0) {
+				fixed = v
+			}
+		}
+	}
+	return fixed
+}
+
+// osvSemver は OSV のバージョンを "v" 付きの SemVer に変換します（"0" は全てのバージョンを表すため空文字列にします）
+func osvSemver(v string) string {
+	if v == "0" {
+		return ""
+	}
+	return "v" + v
+}
+
+// compareOSVSemver は空文字列を最小として2つのバージョンを比較します
+func compareOSVSemver(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return -1
+	case b == "":
+		return 1
+	}
+	return semver.Compare(a, b)
+}
+
+// FormatSecuritySection は脆弱性の一覧を Markdown の Security セクションに整形します
+func FormatSecuritySection(db *VulnDB, modulePath string, version string, vulns []Vulnerability) string {
+	var output strings.Builder
+
+	output.WriteString("## Security\n\n")
+	source := db.Path()
+	if modified := db.Modified(); !modified.IsZero() {
+		source += fmt.Sprintf("（更新日時: %s）", modified.Format("2006-01-02"))
+	}
+	output.WriteString(fmt.Sprintf("脆弱性データベース: %s\n\n", source))
+
+	if len(vulns) == 0 {
+		output.WriteString(fmt.Sprintf("%s@%s に影響する既知の脆弱性は見つかりませんでした。\n\n", modulePath, version))
+		return output.String()
+	}
+
+	output.WriteString(fmt.Sprintf("%s@%s に影響する既知の脆弱性が %d 件あります。\n\n", modulePath, version, len(vulns)))
+	for _, vuln := range vulns {
+		title := vuln.ID
+		if vuln.Summary != "" {
+			title += ": " + vuln.Summary
+		}
+		output.WriteString(fmt.Sprintf("### %s\n\n", title))
+		if len(vuln.Aliases) > 0 {
+			output.WriteString(fmt.Sprintf("- 別名: %s\n", strings.Join(vuln.Aliases, ", ")))
+		}
+		if vuln.Fixed != "" {
+			output.WriteString(fmt.Sprintf("- 修正バージョン: %s\n", vuln.Fixed))
+		} else {
+			output.WriteString("- 修正バージョン: なし\n")
+		}
+		if vuln.URL != "" {
+			output.WriteString(fmt.Sprintf("- 詳細: %s\n", vuln.URL))
+		}
+		if len(vuln.Packages) > 0 {
+			output.WriteString("- 影響を受けるシンボル:\n")
+			for _, pkg := range vuln.Packages {
+				symbols := "パッケージ全体"
+				if len(pkg.Symbols) > 0 {
+					symbols = strings.Join(pkg.Symbols, ", ")
+				}
+				output.WriteString(fmt.Sprintf("  - %s: %s\n", pkg.Path, symbols))
+			}
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vulnDBFixture はテスト用の脆弱性データベースのディレクトリです
+var vulnDBFixture = filepath.Join("testdata", "vulndb")
+
+// vulnIDs は脆弱性のIDの一覧を返します
+func vulnIDs(vulns []Vulnerability) []string {
+	var ids []string
+	for _, vuln := range vulns {
+		ids = append(ids, vuln.ID)
+	}
+	return ids
+}
+
+func TestVulnDBQuery(t *testing.T) {
+	db, err := OpenVulnDB(vulnDBFixture)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	tests := []struct {
+		name       string
+		modulePath string
+		version    string
+		want       []string
+	}{
+		{name: "最初の範囲に含まれる", modulePath: "example.com/lib", version: "v1.0.0", want: []string{"GO-2024-0001"}},
+		{name: "修正バージョンは含まれない", modulePath: "example.com/lib", version: "v1.1.3", want: []string{"GO-2024-0003"}},
+		{name: "再度導入された範囲に含まれる", modulePath: "example.com/lib", version: "v1.2.0-rc.2", want: []string{"GO-2024-0001", "GO-2024-0003"}},
+		{name: "全て修正済み", modulePath: "example.com/lib", version: "v1.6.0", want: nil},
+		{name: "修正されていない脆弱性", modulePath: "example.com/lib/v2", version: "v2.1.0", want: []string{"GO-2024-0004"}},
+		{name: "登録されていないモジュール", modulePath: "example.com/other", version: "v1.0.0", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vulns, err := db.Query(tt.modulePath, tt.version)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, vulnIDs(vulns))
+		})
+	}
+
+	vulns, err := db.Query("example.com/lib", "v1.2.0-rc.2")
+	require.NoError(t, err)
+	assert.Equal(t, Vulnerability{
+		ID:       "GO-2024-0001",
+		Aliases:  []string{"CVE-2024-0001", "GHSA-xxxx-yyyy-zzzz"},
+		Summary:  "Denial of service in example.com/lib/codec",
+		Fixed:    "v1.2.0",
+		URL:      "https://pkg.go.dev/vuln/GO-2024-0001",
+		Packages: []AffectedPackage{{Path: "example.com/lib/codec", Symbols: []string{"Decode", "Decoder.Read"}}},
+	}, vulns[0], "影響を受けるシンボルと、より新しい最小の修正バージョンが得られること")
+
+	_, err = db.Query("example.com/lib", "latest")
+	assert.Error(t, err, "バージョンが特定されていない場合はエラーになること")
+}
+
+func TestVulnDBModuleFor(t *testing.T) {
+	db, err := OpenVulnDB(vulnDBFixture)
+	require.NoError(t, err)
+
+	for importPath, want := range map[string]string{
+		"example.com/lib":           "example.com/lib",
+		"example.com/lib/codec":     "example.com/lib",
+		"example.com/lib/v2/server": "example.com/lib/v2",
+		"example.com/library":       "",
+	} {
+		modulePath, err := db.ModuleFor(importPath)
+		require.NoError(t, err)
+		assert.Equal(t, want, modulePath, importPath)
+	}
+}
+
+func TestOpenVulnDBZip(t *testing.T) {
+	// vuln.go.dev の vulndb.zip と同様に、ディレクトリの中身を zip にまとめる
+	zipPath := filepath.Join(t.TempDir(), "vulndb.zip")
+	file, err := os.Create(zipPath)
+	require.NoError(t, err)
+	w := zip.NewWriter(file)
+	require.NoError(t, w.AddFS(os.DirFS(vulnDBFixture)))
+	require.NoError(t, w.Close())
+	require.NoError(t, file.Close())
+
+	db, err := OpenVulnDB(zipPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	vulns, err := db.Query("example.com/lib", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GO-2024-0001"}, vulnIDs(vulns))
+	assert.Equal(t, "2024-05-01", db.Modified().Format("2006-01-02"))
+
+	_, err = OpenVulnDB(filepath.Join(t.TempDir(), "missing.zip"))
+	assert.Error(t, err)
+}
+
+func TestVulnDBWithoutIndex(t *testing.T) {
+	// インデックスがない場合は全エントリから照合する
+	dir := t.TempDir()
+	require.NoError(t, os.CopyFS(dir, os.DirFS(vulnDBFixture)))
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "index")))
+
+	db, err := OpenVulnDB(dir)
+	require.NoError(t, err)
+
+	vulns, err := db.Query("example.com/lib/v2", "v2.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GO-2024-0004"}, vulnIDs(vulns))
+	assert.True(t, db.Modified().IsZero())
+
+	_, err = fs.Stat(os.DirFS(dir), vulnDBModulesFile)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestWithSecuritySection(t *testing.T) {
+	db, err := OpenVulnDB(vulnDBFixture)
+	require.NoError(t, err)
+
+	content := "# codec\n\nインポートパス: example.com/lib/codec\n\n## ファイル一覧\n\n- codec.go\n"
+	assert.Equal(t, content, withSecuritySection(content, "example.com/lib/codec", "v1.0.0", false, nil), "データベースがない場合は変更しないこと")
+
+	result := withSecuritySection(content, "example.com/lib/codec", "v1.2.0-rc.2", false, db)
+	assert.Equal(t, `# codec
+
+インポートパス: example.com/lib/codec
+
+## Security
+
+脆弱性データベース: testdata/vulndb（更新日時: 2024-05-01）
+
+example.com/lib@v1.2.0-rc.2 に影響する既知の脆弱性が 2 件あります。
+
+### GO-2024-0001: Denial of service in example.com/lib/codec
+
+- 別名: CVE-2024-0001, GHSA-xxxx-yyyy-zzzz
+- 修正バージョン: v1.2.0
+- 詳細: https://pkg.go.dev/vuln/GO-2024-0001
+- 影響を受けるシンボル:
+  - example.com/lib/codec: Decode, Decoder.Read
+
+### GO-2024-0003: Path traversal in example.com/lib
+
+- 別名: CVE-2024-0003
+- 修正バージョン: v1.5.1
+- 詳細: https://pkg.go.dev/vuln/GO-2024-0003
+- 影響を受けるシンボル:
+  - example.com/lib: パッケージ全体
+
+## ファイル一覧
+
+- codec.go
+`, result, "ヘッダーの直後に Security セクションが挿入されること")
+
+	result = withSecuritySection(content, "example.com/other", "v1.0.0", false, db)
+	assert.Contains(t, result, "example.com/other@v1.0.0 に影響する既知の脆弱性は見つかりませんでした。")
+
+	result = withSecuritySection(content, "example.com/lib", "latest", false, db)
+	assert.Contains(t, result, "脆弱性の照合に失敗しました")
+}
+
+func TestVulnDBQueryStdlib(t *testing.T) {
+	db, err := OpenVulnDB(vulnDBFixture)
+	require.NoError(t, err)
+
+	vulns, err := db.QueryStdlib("container/list", "go1.23.4")
+	require.NoError(t, err)
+	require.Equal(t, []string{"GO-2024-0005"}, vulnIDs(vulns), "影響を受けるパッケージのインポートパスで絞り込むこと")
+	assert.Equal(t, "v1.23.5", vulns[0].Fixed)
+	assert.Equal(t, []AffectedPackage{{Path: "container/list", Symbols: []string{"List.MoveToBack"}}}, vulns[0].Packages)
+
+	vulns, err = db.QueryStdlib("container/list", "go1.23.5")
+	require.NoError(t, err)
+	assert.Empty(t, vulns)
+
+	vulns, err = db.QueryStdlib("net/http", "go1.22")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GO-2024-0006"}, vulnIDs(vulns))
+
+	_, err = db.QueryStdlib("net/http", "latest")
+	assert.Error(t, err)
+}
+
+func TestStdlibSemver(t *testing.T) {
+	for goVersion, want := range map[string]string{
+		"go1.22.0":    "v1.22.0",
+		"go1.22":      "v1.22.0",
+		"go1.23rc1":   "v1.23.0-rc.1",
+		"go1.21beta2": "v1.21.0-beta.2",
+	} {
+		assert.Equal(t, want, stdlibSemver(goVersion), goVersion)
+	}
+}
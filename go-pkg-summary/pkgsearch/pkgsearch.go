@@ -0,0 +1,24 @@
+// Package pkgsearch は pkg.go.dev のパッケージ検索とシンボル検索を、go-pkg-summary の外のコマンドから使用するための API を提供します
+// 検索結果の取得と解析は go-pkg-summary の search コマンドと同じ実装（internal.Scraper）を使用します
+package pkgsearch
+
+import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/internal"
+)
+
+// Package はパッケージ検索で見つかったパッケージを表す構造体です
+type Package = internal.Package
+
+// Symbol はシンボル検索で見つかったシンボルを表す構造体です
+type Symbol = internal.Symbol
+
+// SearchPackages は pkg.go.dev でパッケージを検索します
+// limit が1ページの件数を超える場合は page パラメータでページを送りながら取得します
+func SearchPackages(query string, limit int, debug bool) ([]Package, error) {
+	return internal.NewScraper(debug).SearchPackage(query, limit)
+}
+
+// SearchSymbols は pkg.go.dev のシンボル検索で関数や型などを検索します
+func SearchSymbols(query string, limit int, debug bool) ([]Symbol, error) {
+	return internal.NewScraper(debug).SearchSymbol(query, limit)
+}
@@ -2,132 +2,131 @@
 package main
 
 import (
+	"com.github/kazukimatsumoto/ailab-go/go-pkg-summary/pkgsearch"
+	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
 	"os"
+	"sort"
 	"strings"
-	"time"
-
-	"github.com/PuerkitoBio/goquery"
 )
 
 // SearchResult は検索結果の各アイテムを表す構造体です
 type SearchResult struct {
-	Name        string
-	ImportPath  string
-	Synopsis    string
-	Stars       int
-	Version     string
-	CommitTime  string
-	NumImported int
+	Name        string `json:"name"`
+	ImportPath  string `json:"importPath"`
+	Synopsis    string `json:"synopsis"`
+	Version     string `json:"version,omitempty"`
+	CommitTime  string `json:"commitTime,omitempty"`
+	NumImported int    `json:"numImported"`
+	License     string `json:"license,omitempty"`
 }
 
-// SearchGoPkg は pkg.go.dev を検索します
-func SearchGoPkg(query string, limit int, debug bool) ([]SearchResult, error) {
-	// 検索 URL を構築
-	baseURL := "https://pkg.go.dev/search"
-	params := url.Values{}
-	params.Add("q", query)
+// 並び替えの種類
+const (
+	SortRelevance = "relevance"
+	SortImported  = "imported"
+	SortUpdated   = "updated"
+)
 
-	searchURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
+// 検索モード
+const (
+	ModePackage = "package"
+	ModeSymbol  = "symbol"
+)
 
-	if debug {
-		fmt.Printf("検索 URL: %s\n", searchURL)
-	}
+// SymbolResult はシンボル検索の結果の各アイテムを表す構造体です
+type SymbolResult struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Signature   string `json:"signature,omitempty"`
+	Synopsis    string `json:"synopsis,omitempty"`
+	PackagePath string `json:"packagePath"`
+}
 
-	// HTTP リクエストを作成
-	req, err := http.NewRequest("GET", searchURL, nil)
+// SearchGoPkg は pkg.go.dev を検索します
+// 検索結果の取得と解析は go-pkg-summary と共通の pkgsearch パッケージで行います
+func SearchGoPkg(query string, limit int, debug bool) ([]SearchResult, error) {
+	packages, err := pkgsearch.SearchPackages(query, limit, debug)
 	if err != nil {
-		return nil, fmt.Errorf("リクエストの作成に失敗しました: %w", err)
+		return nil, err
 	}
 
-	// User-Agent ヘッダーを設定
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.114 Safari/537.36")
-
-	if debug {
-		fmt.Println("リクエストヘッダー:")
-		for key, values := range req.Header {
-			fmt.Printf("  %s: %s\n", key, strings.Join(values, ", "))
+	var results []SearchResult
+	for _, pkg := range packages {
+		result := SearchResult{
+			Name:        pkg.Name,
+			ImportPath:  pkg.ImportPath,
+			Synopsis:    pkg.Synopsis,
+			Version:     pkg.Version,
+			NumImported: pkg.ImportedBy,
+			License:     pkg.License,
 		}
+		if !pkg.Published.IsZero() {
+			result.CommitTime = pkg.Published.Format("2006-01-02")
+		}
+		results = append(results, result)
 	}
+	return results, nil
+}
 
-	// HTTP クライアントを作成
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	// リクエストを実行
-	resp, err := client.Do(req)
+// SearchGoSymbol は pkg.go.dev のシンボル検索で関数や型などを検索します
+func SearchGoSymbol(query string, limit int, debug bool) ([]SymbolResult, error) {
+	symbols, err := pkgsearch.SearchSymbols(query, limit, debug)
 	if err != nil {
-		return nil, fmt.Errorf("API リクエストに失敗しました: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if debug {
-		fmt.Println("レスポンスヘッダー:")
-		for key, values := range resp.Header {
-			fmt.Printf("  %s: %s\n", key, strings.Join(values, ", "))
-		}
+	var results []SymbolResult
+	for _, symbol := range symbols {
+		results = append(results, SymbolResult{
+			Name:        symbol.Name,
+			Kind:        symbol.Kind,
+			Signature:   symbol.Signature,
+			Synopsis:    symbol.Synopsis,
+			PackagePath: symbol.PackagePath,
+		})
 	}
+	return results, nil
+}
 
-	// レスポンスをチェック
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API リクエストに失敗しました: %s - %s", resp.Status, string(body))
+// SortResults は検索結果を指定された順序で並び替えます
+// relevance の場合は pkg.go.dev が返した順序のままにします
+func SortResults(results []SearchResult, order string) error {
+	switch order {
+	case SortRelevance, "":
+	case SortImported:
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].NumImported > results[j].NumImported
+		})
+	case SortUpdated:
+		// CommitTime は YYYY-MM-DD 形式なので文字列比較で新しい順に並ぶ
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].CommitTime > results[j].CommitTime
+		})
+	default:
+		return fmt.Errorf("不明な並び順です: %s（imported, updated, relevance のいずれかを指定してください）", order)
 	}
+	return nil
+}
 
-	// HTML をパース
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("HTML のパースに失敗しました: %w", err)
+// シンボルの詳細情報を表示する関数
+func displaySymbolDetails(symbol SymbolResult) {
+	fmt.Printf("🔎 %s (%s)\n", symbol.Name, symbol.Kind)
+	fmt.Printf("   パッケージ: %s\n", symbol.PackagePath)
+	if symbol.Signature != "" {
+		fmt.Printf("   シグネチャ: %s\n", symbol.Signature)
 	}
-
-	// 検索結果を抽出
-	var results []SearchResult
-
-	// 検索結果の各アイテムを処理
-	doc.Find(".SearchSnippet").Each(func(i int, s *goquery.Selection) {
-		// 最大件数に達したら処理を終了
-		if limit > 0 && i >= limit {
-			return
-		}
-
-		// インポートパス（括弧内のテキストを抽出）
-		headerText := s.Find(".SearchSnippet-headerContainer").Text()
-		importPath := ""
-		if start := strings.Index(headerText, "("); start != -1 {
-			if end := strings.Index(headerText[start:], ")"); end != -1 {
-				importPath = strings.TrimSpace(headerText[start+1 : start+end])
-			}
-		}
-
-		// パッケージ名（インポートパスの最後の部分）
-		name := ""
-		if importPath != "" {
-			parts := strings.Split(importPath, "/")
-			name = parts[len(parts)-1]
-		}
-
-		// 概要
-		synopsis := strings.TrimSpace(s.Find(".SearchSnippet-synopsis").Text())
-
-		// 結果に追加
-		result := SearchResult{
-			Name:       name,
-			ImportPath: importPath,
-			Synopsis:   synopsis,
-		}
-
-		results = append(results, result)
-	})
-
-	if debug {
-		fmt.Printf("検索結果: %d 件\n", len(results))
+	if symbol.Synopsis != "" {
+		fmt.Printf("   概要: %s\n", symbol.Synopsis)
 	}
+	fmt.Println()
+}
 
-	return results, nil
+// printJSON は値を整形した JSON として標準出力に書き出します
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
 }
 
 // パッケージの詳細情報を表示する関数
@@ -137,6 +136,17 @@ func displayPackageDetails(pkg SearchResult) {
 	if pkg.Synopsis != "" {
 		fmt.Printf("   概要: %s\n", pkg.Synopsis)
 	}
+	if pkg.Version != "" {
+		fmt.Printf("   バージョン: %s", pkg.Version)
+		if pkg.CommitTime != "" {
+			fmt.Printf(" (%s 公開)", pkg.CommitTime)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("   インポート数: %d\n", pkg.NumImported)
+	if pkg.License != "" {
+		fmt.Printf("   ライセンス: %s\n", pkg.License)
+	}
 	fmt.Println()
 }
 
@@ -144,8 +154,10 @@ func main() {
 	// コマンドライン引数を解析
 	args := os.Args[1:]
 	if len(args) < 1 {
-		fmt.Println("使用法: search-gopkg <検索クエリ> [--limit=N] [--debug]")
-		fmt.Println("例: search-gopkg zap --limit=5")
+		fmt.Println("使用法: search-gopkg <検索クエリ> [--limit=N] [--mode=package|symbol] [--sort=imported|updated|relevance] [--json] [--debug]")
+		fmt.Println("例: search-gopkg zap --limit=5 --sort=imported")
+		fmt.Println("例: search-gopkg NewLogger --mode=symbol")
+		fmt.Println("注意: pkg.go.dev はリポジトリのスター数を公開していないため、結果にスター数は含まれません")
 		os.Exit(1)
 	}
 
@@ -153,16 +165,44 @@ func main() {
 	query := args[0]
 	limit := 10
 	debug := false
+	mode := ModePackage
+	order := SortRelevance
+	jsonOutput := false
 
 	for i := 1; i < len(args); i++ {
 		arg := args[i]
 		if strings.HasPrefix(arg, "--limit=") {
 			fmt.Sscanf(strings.TrimPrefix(arg, "--limit="), "%d", &limit)
+		} else if strings.HasPrefix(arg, "--mode=") {
+			mode = strings.TrimPrefix(arg, "--mode=")
+		} else if strings.HasPrefix(arg, "--sort=") {
+			order = strings.TrimPrefix(arg, "--sort=")
+		} else if arg == "--json" {
+			jsonOutput = true
 		} else if arg == "--debug" {
 			debug = true
 		}
 	}
 
+	switch mode {
+	case ModePackage:
+		runPackageSearch(query, limit, order, jsonOutput, debug)
+	case ModeSymbol:
+		runSymbolSearch(query, limit, jsonOutput, debug)
+	default:
+		fmt.Fprintf(os.Stderr, "エラー: 不明な検索モードです: %s（package または symbol を指定してください）\n", mode)
+		os.Exit(1)
+	}
+}
+
+// runPackageSearch はパッケージ検索を実行して結果を表示します
+func runPackageSearch(query string, limit int, order string, jsonOutput bool, debug bool) {
+	// 検索前に並び順を検証
+	if err := SortResults(nil, order); err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %s\n", err.Error())
+		os.Exit(1)
+	}
+
 	// pkg.go.dev を検索
 	results, err := SearchGoPkg(query, limit, debug)
 	if err != nil {
@@ -170,6 +210,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	// 結果を並び替え
+	_ = SortResults(results, order)
+
+	// JSON 形式で出力
+	if jsonOutput {
+		if results == nil {
+			results = []SearchResult{}
+		}
+		if err := printJSON(results); err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %s\n", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	// 結果を表示
 	if len(results) == 0 {
 		fmt.Printf("クエリ '%s' に一致するパッケージは見つかりませんでした。\n", query)
@@ -181,3 +236,36 @@ func main() {
 		displayPackageDetails(result)
 	}
 }
+
+// runSymbolSearch はシンボル検索を実行して結果を表示します
+func runSymbolSearch(query string, limit int, jsonOutput bool, debug bool) {
+	// pkg.go.dev をシンボル検索
+	results, err := SearchGoSymbol(query, limit, debug)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	// JSON 形式で出力
+	if jsonOutput {
+		if results == nil {
+			results = []SymbolResult{}
+		}
+		if err := printJSON(results); err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %s\n", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 結果を表示
+	if len(results) == 0 {
+		fmt.Printf("クエリ '%s' に一致するシンボルは見つかりませんでした。\n", query)
+		os.Exit(0)
+	}
+
+	fmt.Printf("クエリ '%s' のシンボル検索結果 (%d 件):\n\n", query, len(results))
+	for _, result := range results {
+		displaySymbolDetails(result)
+	}
+}